@@ -1,16 +1,38 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+	// Встроенная база часовых поясов: в минимальных контейнерах её может не быть
+	_ "time/tzdata"
 
 	"goal-helper/internal/bot"
 	"goal-helper/internal/llm"
+	"goal-helper/internal/persona"
 	"goal-helper/internal/repository"
 
 	"github.com/joho/godotenv"
 )
 
+const (
+	// defaultShutdownTimeout время ожидания обработчиков при остановке по умолчанию
+	// (меньше стандартных 30 секунд, которые дает Kubernetes перед SIGKILL)
+	defaultShutdownTimeout = 25 * time.Second
+
+	// Режимы получения апдейтов (переменная BOT_MODE)
+	botModePolling = "polling"
+	botModeWebhook = "webhook"
+
+	defaultWebhookListen = ":8443"
+)
+
 func main() {
 	// Загружаем переменные окружения из .env файла
 	if err := godotenv.Load(); err != nil {
@@ -23,20 +45,182 @@ func main() {
 		log.Fatal("TELEGRAM_BOT_TOKEN environment variable is required")
 	}
 
-	// Инициализируем репозиторий для работы с данными
-	repo, err := repository.NewFileRepository("data")
+	// Директория с данными
+	dataDir := os.Getenv("DATA_DIR")
+	if dataDir == "" {
+		dataDir = "data"
+	}
+
+	// Персоны из папки PERSONAS_DIR (по умолчанию DATA_DIR/personas) дополняют встроенные
+	personasDir := os.Getenv("PERSONAS_DIR")
+	if personasDir == "" {
+		personasDir = filepath.Join(dataDir, "personas")
+	}
+	if err := persona.Load(personasDir); err != nil {
+		log.Printf("Warning: failed to load personas, using built-in personas: %v", err)
+	}
+
+	// Инициализируем репозиторий для работы с данными ("file" или "sqlite")
+	repo, err := repository.New(os.Getenv("STORAGE_BACKEND"), dataDir)
 	if err != nil {
 		log.Fatalf("Failed to initialize repository: %v", err)
 	}
 
-	// Инициализируем LLM клиент (OpenAI)
-	llmClient := llm.NewOpenAIClient(os.Getenv("LLM_API_KEY"))
+	// Инициализируем хранилище состояний диалогов
+	stateStore, err := repository.NewStateStore(repo, dataDir)
+	if err != nil {
+		log.Fatalf("Failed to initialize state store: %v", err)
+	}
+
+	// Инициализируем хранилище запланированных напоминаний
+	reminderStore, err := repository.NewReminderStore(repo, dataDir)
+	if err != nil {
+		log.Fatalf("Failed to initialize reminder store: %v", err)
+	}
+
+	// Инициализируем LLM клиент для выбранного провайдера с цепочкой резервных клиентов
+	llmClient, err := newLLMClient()
+	if err != nil {
+		log.Fatalf("Failed to initialize LLM client: %v", err)
+	}
+
+	// Режим получения апдейтов: long polling (по умолчанию) или webhook
+	webhook, err := webhookConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid webhook configuration: %v", err)
+	}
 
 	// Создаем и запускаем бота
-	botInstance := bot.NewBot(botToken, repo, llmClient)
+	botInstance := bot.NewBot(botToken, webhook, repo, stateStore, reminderStore, llmClient)
+
+	// SIGINT/SIGTERM запускают плавную остановку, повторный сигнал завершает процесс сразу
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	log.Println("Starting Goal Helper bot...")
-	if err := botInstance.Start(); err != nil {
-		log.Fatalf("Failed to start bot: %v", err)
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- botInstance.Start()
+	}()
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		stop()
+		log.Println("Shutdown signal received")
+	case err := <-startErr:
+		if err != nil {
+			log.Printf("Failed to start bot: %v", err)
+			exitCode = 1
+		}
+	}
+
+	// Даже если бот не запустился, останавливаем планировщик и ждем обработчики,
+	// чтобы никто не писал в хранилище после его закрытия
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
+	if err := botInstance.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Bot shutdown error: %v", err)
+		exitCode = 1
+	}
+	cancel()
+
+	// Сбрасываем данные на диск
+	if err := repo.Close(); err != nil {
+		log.Printf("Failed to close repository: %v", err)
+		exitCode = 1
+	}
+
+	log.Println("Goal Helper bot stopped")
+	os.Exit(exitCode)
+}
+
+// shutdownTimeout возвращает время ожидания обработчиков при остановке
+// (переменная SHUTDOWN_TIMEOUT в секундах)
+func shutdownTimeout() time.Duration {
+	if value := os.Getenv("SHUTDOWN_TIMEOUT"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		log.Printf("Warning: invalid SHUTDOWN_TIMEOUT %q, using default", value)
+	}
+	return defaultShutdownTimeout
+}
+
+// webhookConfigFromEnv читает настройки webhook из переменных WEBHOOK_*
+// Возвращает nil, если BOT_MODE не равен "webhook"
+func webhookConfigFromEnv() (*bot.WebhookConfig, error) {
+	switch mode := os.Getenv("BOT_MODE"); mode {
+	case "", botModePolling:
+		return nil, nil
+	case botModeWebhook:
+	default:
+		return nil, fmt.Errorf("unknown BOT_MODE %q (expected %q or %q)", mode, botModePolling, botModeWebhook)
+	}
+
+	config := &bot.WebhookConfig{
+		Listen:      os.Getenv("WEBHOOK_LISTEN"),
+		PublicURL:   os.Getenv("WEBHOOK_URL"),
+		SecretToken: os.Getenv("WEBHOOK_SECRET"),
+		TLSCert:     os.Getenv("WEBHOOK_TLS_CERT"),
+		TLSKey:      os.Getenv("WEBHOOK_TLS_KEY"),
+	}
+	if config.Listen == "" {
+		config.Listen = defaultWebhookListen
+	}
+	if value := os.Getenv("WEBHOOK_SELF_SIGNED"); value != "" {
+		selfSigned, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WEBHOOK_SELF_SIGNED %q: %w", value, err)
+		}
+		config.SelfSigned = selfSigned
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// newLLMClient создает основной LLM клиент (переменные LLM_*) и резервные
+// клиенты из переменных LLM_FALLBACK_1_*, LLM_FALLBACK_2_*, ...
+func newLLMClient() (llm.Client, error) {
+	primary := providerConfigFromEnv("LLM_")
+
+	client, err := llm.NewClient(primary)
+	if err != nil {
+		return nil, err
+	}
+	clients := []llm.Client{client}
+
+	for i := 1; ; i++ {
+		prefix := fmt.Sprintf("LLM_FALLBACK_%d_", i)
+		config := providerConfigFromEnv(prefix)
+		if config.Provider == "" {
+			break
+		}
+
+		// Ключ наследуется от основного клиента, если провайдер тот же
+		if config.APIKey == "" && config.Provider == primary.Provider {
+			config.APIKey = primary.APIKey
+		}
+
+		fallback, err := llm.NewClient(config)
+		if err != nil {
+			return nil, fmt.Errorf("fallback %d: %w", i, err)
+		}
+		clients = append(clients, fallback)
+	}
+
+	return llm.NewFallbackClient(clients...), nil
+}
+
+// providerConfigFromEnv читает конфигурацию LLM провайдера из переменных с префиксом
+func providerConfigFromEnv(prefix string) llm.ProviderConfig {
+	return llm.ProviderConfig{
+		Provider: os.Getenv(prefix + "PROVIDER"),
+		APIKey:   os.Getenv(prefix + "API_KEY"),
+		Model:    os.Getenv(prefix + "MODEL"),
+		BaseURL:  os.Getenv(prefix + "BASE_URL"),
 	}
 }
@@ -5,5 +5,6 @@ go 1.21
 require (
 	github.com/google/uuid v1.4.0
 	github.com/joho/godotenv v1.4.0
+	github.com/mattn/go-sqlite3 v1.14.22
 	gopkg.in/telebot.v3 v3.1.3
 )
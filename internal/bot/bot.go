@@ -1,53 +1,89 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"goal-helper/internal/i18n"
 	"goal-helper/internal/llm"
 	"goal-helper/internal/models"
 	"goal-helper/internal/repository"
+	"goal-helper/internal/scheduler"
 
 	tele "gopkg.in/telebot.v3"
 )
 
 // Bot представляет Telegram-бота
 type Bot struct {
-	bot       *tele.Bot
-	repo      repository.Repository
-	llmClient llm.Client
-	states    map[int64]*UserState // Состояния пользователей
-}
-
-// UserState представляет состояние пользователя в FSM
-type UserState struct {
-	UserID   int64
-	State    string            // "idle", "waiting_goal_description", "rephrasing", "gathering_context"
-	TempData map[string]string // Временные данные для создания цели
+	bot        *tele.Bot
+	repo       repository.Repository
+	stateStore repository.StateStore // Хранилище состояний FSM
+	scheduler  *scheduler.Scheduler  // Напоминания о зависших шагах
+	llmClient  llm.Client
+	webhook    *webhookPoller  // nil в режиме long polling
+	stopOnce   sync.Once       // Поллер останавливается ровно один раз
+	userLocks  *userLocks      // Последовательная обработка апдейтов каждого пользователя
+	requests   *requestTracker // Контексты обрабатываемых апдейтов (для /cancel)
+
+	statesMutex sync.Mutex
+	states      map[int64]*models.UserState // Состояния пользователей
+
+	schedulerCtx  context.Context // Отменяется при остановке бота
+	stopScheduler context.CancelFunc
+	schedulerDone chan struct{} // Закрывается после остановки планировщика
 }
 
 // NewBot создает нового бота
-func NewBot(token string, repo repository.Repository, llmClient llm.Client) *Bot {
+// Если webhook равен nil, апдейты получаются через long polling
+func NewBot(token string, webhook *WebhookConfig, repo repository.Repository, stateStore repository.StateStore, reminderStore repository.ReminderStore, llmClient llm.Client) *Bot {
 	// Настройки бота
 	pref := tele.Settings{
 		Token:  token,
 		Poller: &tele.LongPoller{Timeout: BotPollerTimeout},
 	}
 
+	var webhookPoller *webhookPoller
+	if webhook != nil {
+		if err := webhook.Validate(); err != nil {
+			log.Fatal(err)
+		}
+		webhookPoller = newWebhookPoller(*webhook)
+		pref.Poller = webhookPoller
+	}
+
 	bot, err := tele.NewBot(pref)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	b := &Bot{
-		bot:       bot,
-		repo:      repo,
-		llmClient: llmClient,
-		states:    make(map[int64]*UserState),
+		bot:        bot,
+		repo:       repo,
+		stateStore: stateStore,
+		llmClient:  llmClient,
+		webhook:    webhookPoller,
+		userLocks:  newUserLocks(),
+		requests:   newRequestTracker(),
+		states:     make(map[int64]*models.UserState),
+
+		schedulerDone: make(chan struct{}),
 	}
+	b.schedulerCtx, b.stopScheduler = context.WithCancel(context.Background())
+
+	b.scheduler = scheduler.New(reminderStore, b.lockUserByID, b.reminderRetryTime,
+		ReminderCheckIntervalSeconds*time.Second, ReminderRetryMinutes*time.Minute)
+
+	if webhookPoller != nil {
+		webhookPoller.stop = b.stopPolling
+	}
+
+	// Восстанавливаем незавершенные диалоги после перезапуска
+	b.restoreStates()
 
 	// Регистрируем обработчики команд
 	b.registerHandlers()
@@ -55,15 +91,67 @@ func NewBot(token string, repo repository.Repository, llmClient llm.Client) *Bot
 	return b
 }
 
-// Start запускает бота
+// Start запускает бота и блокируется до вызова Shutdown
 func (b *Bot) Start() error {
-	log.Println("Bot started...")
+	if b.webhook != nil {
+		if err := b.webhook.register(b.bot); err != nil {
+			// Получение апдейтов и планировщик не запускались - Shutdown не должен их ждать
+			b.stopOnce.Do(func() {})
+			close(b.schedulerDone)
+			return err
+		}
+		log.Printf("Bot started in webhook mode (%s)...", b.webhook.config.PublicURL)
+	} else {
+		log.Println("Bot started...")
+	}
+
+	// Планировщик напоминаний работает до Shutdown
+	go func() {
+		defer close(b.schedulerDone)
+		b.scheduler.Run(b.schedulerCtx, b.deliverReminder)
+	}()
+
 	b.bot.Start()
+
+	if b.webhook != nil {
+		return b.webhook.Err()
+	}
+	return nil
+}
+
+// stopPolling останавливает получение апдейтов
+// Повторный вызов tele.Bot.Stop заблокировался бы навсегда
+func (b *Bot) stopPolling() {
+	b.stopOnce.Do(b.bot.Stop)
+}
+
+// Shutdown останавливает получение апдейтов и ждет, пока обработчики
+// (включая запросы к LLM) завершатся. Если ctx истекает раньше,
+// незавершенные запросы отменяются и возвращается ошибка контекста
+func (b *Bot) Shutdown(ctx context.Context) error {
+	log.Println("Stopping bot...")
+	b.stopPolling()
+
+	// Останавливаем планировщик, дожидаясь отправки текущего напоминания
+	b.stopScheduler()
+	select {
+	case <-b.schedulerDone:
+	case <-ctx.Done():
+	}
+
+	if err := b.requests.Close(ctx, BotShutdownGraceSeconds*time.Second); err != nil {
+		return fmt.Errorf("in-flight handlers did not finish: %w", err)
+	}
+
+	log.Println("Bot stopped")
 	return nil
 }
 
 // registerHandlers регистрирует все обработчики команд
 func (b *Bot) registerHandlers() {
+	// Middleware должно быть подключено до регистрации обработчиков
+	b.bot.Use(b.trackRequests, b.serializeUpdates, b.localize)
+
 	// Основные команды
 	b.bot.Handle(CmdStart, b.handleStart)
 	b.bot.Handle(CmdHelp, b.handleHelp)
@@ -72,18 +160,70 @@ func (b *Bot) registerHandlers() {
 	b.bot.Handle(CmdStatus, b.handleStatus)
 	b.bot.Handle(CmdStep, b.handleStep)
 	b.bot.Handle(CmdDone, b.handleDone)
+	b.bot.Handle(CmdSkip, b.handleSkip)
 	b.bot.Handle(CmdNext, b.handleNext)
 	b.bot.Handle(CmdRephrase, b.handleRephrase)
 	b.bot.Handle(CmdSimpler, b.handleSimpler)
 	b.bot.Handle(CmdSwitch, b.handleSwitch)
 	b.bot.Handle(CmdComplete, b.handleComplete)
 	b.bot.Handle(CmdContext, b.handleContext)
+	b.bot.Handle(CmdCancel, b.handleCancel)
+	b.bot.Handle(CmdRemind, b.handleRemind)
+	b.bot.Handle(CmdSettings, b.handleSettings)
+	b.bot.Handle(CmdStats, b.handleStats)
+	b.bot.Handle(CmdPause, b.handlePause)
+	b.bot.Handle(CmdResume, b.handleResume)
+	b.bot.Handle(CmdAbandon, b.handleAbandon)
+	b.bot.Handle(CmdEditGoal, b.handleEditGoal)
+	b.bot.Handle(CmdDeleteGoal, b.handleDeleteGoal)
+	b.bot.Handle(CmdHistory, b.handleHistory)
+	b.bot.Handle(CmdLanguage, b.handleLanguage)
+	b.bot.Handle(CmdPersona, b.handlePersona)
+	b.bot.Handle(CmdMilestones, b.handleMilestones)
+	b.bot.Handle(CmdToday, b.handleToday)
+
+	// Обработчик кнопок на всех языках: у пользователя может остаться клавиатура на прежнем языке
+	for _, language := range i18n.Languages() {
+		loc := i18n.New(language)
+		b.bot.Handle(&tele.Btn{Text: loc.T(BtnTextDone)}, b.handleDone)
+		b.bot.Handle(&tele.Btn{Text: loc.T(BtnTextSkip)}, b.handleSkip)
+		b.bot.Handle(&tele.Btn{Text: loc.T(BtnTextRephrase)}, b.handleRephrase)
+		b.bot.Handle(&tele.Btn{Text: loc.T(BtnTextSimpler)}, b.handleSimpler)
+		b.bot.Handle(&tele.Btn{Text: loc.T(BtnTextComplete)}, b.handleComplete)
+	}
 
-	// Обработчик кнопок
-	b.bot.Handle(&tele.Btn{Text: BtnTextDone}, b.handleDone)
-	b.bot.Handle(&tele.Btn{Text: BtnTextRephrase}, b.handleRephrase)
-	b.bot.Handle(&tele.Btn{Text: BtnTextSimpler}, b.handleSimpler)
-	b.bot.Handle(&tele.Btn{Text: BtnTextComplete}, b.handleComplete)
+	// Обработчики inline кнопок
+	b.bot.Handle(&tele.Btn{Unique: CallbackSwitchGoal}, b.handleSwitchGoal)
+	b.bot.Handle(&tele.Btn{Unique: CallbackResumeGoal}, b.handleResumeGoal)
+	b.bot.Handle(&tele.Btn{Unique: CallbackEditGoal}, b.handleEditGoalField)
+	b.bot.Handle(&tele.Btn{Unique: CallbackLanguage}, b.handleLanguageSelect)
+	b.bot.Handle(&tele.Btn{Unique: CallbackPersona}, b.handlePersonaSelect)
+	b.bot.Handle(&tele.Btn{Unique: CallbackGoalPersona}, b.handleGoalPersonaSelect)
+	b.bot.Handle(&tele.Btn{Unique: CallbackPersonaScope}, b.handlePersonaScope)
+	b.bot.Handle(&tele.Btn{Unique: CallbackContextEdit}, b.handleContextEdit)
+	b.bot.Handle(&tele.Btn{Unique: CallbackContextDelete}, b.handleContextDelete)
+	b.bot.Handle(&tele.Btn{Unique: CallbackContextGather}, b.handleContextGather)
+	b.bot.Handle(&tele.Btn{Unique: CallbackMilestoneEdit}, b.handleMilestoneEdit)
+	b.bot.Handle(&tele.Btn{Unique: CallbackMilestoneDelete}, b.handleMilestoneDelete)
+	b.bot.Handle(&tele.Btn{Unique: CallbackMilestoneAdd}, b.handleMilestoneAdd)
+	b.bot.Handle(&tele.Btn{Unique: CallbackMilestoneDone}, b.handleMilestoneDone)
+	b.bot.Handle(&tele.Btn{Unique: CallbackMilestonesPropose}, b.handleMilestonesPropose)
+	b.bot.Handle(&tele.Btn{Unique: CallbackMilestonesClear}, b.handleMilestonesClear)
+	b.bot.Handle(&tele.Btn{Unique: CallbackStepRating}, b.handleStepRating)
+	b.bot.Handle(&tele.Btn{Unique: CallbackStepNoteSkip}, b.handleStepNoteSkip)
+	b.bot.Handle(&tele.Btn{Unique: CallbackDeleteGoal}, b.handleDeleteGoalSelect)
+	b.bot.Handle(&tele.Btn{Unique: CallbackDeleteGoalConfirm}, b.handleDeleteGoalConfirm)
+	b.bot.Handle(&tele.Btn{Unique: CallbackDeleteGoalCancel}, b.handleDeleteGoalCancel)
+	b.bot.Handle(&tele.Btn{Unique: CallbackReminderDone}, b.handleReminderDone)
+	b.bot.Handle(&tele.Btn{Unique: CallbackReminderSimpler}, b.handleReminderSimpler)
+	b.bot.Handle(&tele.Btn{Unique: CallbackTodayDone}, b.handleTodayDone)
+	b.bot.Handle(&tele.Btn{Unique: CallbackTodaySimpler}, b.handleTodaySimpler)
+	b.bot.Handle(&tele.Btn{Unique: CallbackSettingsMenu}, b.handleSettingsMenu)
+	b.bot.Handle(&tele.Btn{Unique: CallbackSettingsTimezone}, b.handleSettingsTimezone)
+	b.bot.Handle(&tele.Btn{Unique: CallbackSettingsQuietHours}, b.handleSettingsQuietHours)
+	b.bot.Handle(&tele.Btn{Unique: CallbackSettingsReminderTime}, b.handleSettingsReminderTime)
+	b.bot.Handle(&tele.Btn{Unique: CallbackSettingsDay}, b.handleSettingsDay)
+	b.bot.Handle(&tele.Btn{Unique: CallbackSettingsReminders}, b.handleSettingsReminders)
 
 	// Обработка текстовых сообщений
 	b.bot.Handle(tele.OnText, b.handleText)
@@ -91,33 +231,41 @@ func (b *Bot) registerHandlers() {
 
 // handleStart обрабатывает команду /start
 func (b *Bot) handleStart(c tele.Context) error {
+	ctx := requestContext(c)
 	userID := strconv.FormatInt(c.Sender().ID, 10)
 	username := c.Sender().Username
 	firstName := c.Sender().FirstName
 
 	// Проверяем, существует ли пользователь
-	_, err := b.repo.GetUser(userID)
+	user, err := b.repo.GetUser(ctx, userID)
 	if err != nil {
 		// Создаем нового пользователя
 		user := models.NewUser(userID, username, firstName)
-		if err := b.repo.CreateUser(user); err != nil {
-			return c.Send(MsgErrorCreateUser)
+		user.LanguageCode = c.Sender().LanguageCode
+		if err := b.repo.CreateUser(ctx, user); err != nil {
+			return c.Send(tr(c, MsgErrorCreateUser))
+		}
+	} else if rememberClientLanguage(user, c.Sender().LanguageCode) {
+		// Язык клиента мог смениться с прошлого /start
+		if err := b.repo.UpdateUser(ctx, user); err != nil {
+			log.Printf("❌ Ошибка при сохранении языка пользователя %s: %v", userID, err)
 		}
+		c.Set(localizerKey, userLocalizer(user))
 	}
 
 	// Создаем или обновляем состояние пользователя
-	b.states[c.Sender().ID] = &UserState{
-		UserID:   c.Sender().ID,
-		State:    StateIdle,
-		TempData: make(map[string]string),
-	}
+	state := models.NewUserState(c.Sender().ID, StateIdle)
+	b.statesMutex.Lock()
+	b.states[c.Sender().ID] = state
+	b.statesMutex.Unlock()
+	b.saveState(state)
 
 	// Приветственное сообщение
-	message := fmt.Sprintf(MsgWelcomeTemplate, firstName)
+	message := tr(c, MsgWelcomeTemplate, firstName)
 
 	menu := &tele.ReplyMarkup{ResizeKeyboard: true}
-	btnGoals := menu.Text(BtnTextGoals)
-	btnNewGoal := menu.Text(BtnTextNewGoal)
+	btnGoals := menu.Text(tr(c, BtnTextGoals))
+	btnNewGoal := menu.Text(tr(c, BtnTextNewGoal))
 
 	menu.Reply(
 		menu.Row(btnGoals),
@@ -129,79 +277,70 @@ func (b *Bot) handleStart(c tele.Context) error {
 
 // handleHelp обрабатывает команду /help
 func (b *Bot) handleHelp(c tele.Context) error {
-	message := `🤖 **Помощник в достижении целей**
-
-**Основные команды:**
-/start - Начать работу с ботом
-/help - Показать эту справку
-/goals - Показать список твоих целей
-/newgoal - Создать новую цель
-/status - Показать прогресс по активной цели
-/step - Показать текущий шаг
-/done - Отметить шаг как выполненный
-/next - Получить следующий шаг
-/rephrase - Переформулировать текущий шаг
-/simpler - Сделать текущий шаг проще (если он слишком сложный)
-/complete - Завершить цель (если считаешь, что она достигнута)
-/switch - Переключиться на другую цель
-/context - Показать собранный контекст о тебе
-
-**Как это работает:**
-1. Создай цель командой /newgoal
-2. Бот задаст несколько вопросов о твоем опыте и навыках
-3. Получи первый шаг командой /next
-4. Выполни шаг и отметь его командой /done
-5. Получи следующий шаг командой /next
-6. Повторяй, пока цель не будет достигнута
-
-**Важно:** Каждый шаг должен быть максимально простым - от 5 минут до максимум 1 дня. Если шаг кажется слишком сложным, используй /simpler или /rephrase.
-
-**Статусы целей:**
-🎯 - Активная цель
-✅ - Завершенная цель
-⏳ - Неактивная цель
-
-Бот сам определит, когда цель достигнута, но ты можешь завершить её вручную командой /complete.`
+	message := tr(c, MsgHelp)
 
 	return c.Send(message, &tele.SendOptions{ParseMode: tele.ModeMarkdown})
 }
 
 // handleGoals обрабатывает команду /goals
 func (b *Bot) handleGoals(c tele.Context) error {
+	ctx := requestContext(c)
 	userID := strconv.FormatInt(c.Sender().ID, 10)
 
-	goals, err := b.repo.GetUserGoals(userID)
+	goals, err := b.repo.GetUserGoals(ctx, userID)
 	if err != nil {
-		return c.Send(MsgErrorSteps)
+		return c.Send(tr(c, MsgErrorSteps))
 	}
 
 	if len(goals) == 0 {
-		return c.Send(MsgNoGoals)
+		return c.Send(tr(c, MsgNoGoals))
 	}
 
 	var message strings.Builder
-	message.WriteString("📋 **Твои цели:**\n\n")
+	message.WriteString(tr(c, MsgGoalsTitle))
 
-	user, err := b.repo.GetUser(userID)
+	user, err := b.repo.GetUser(ctx, userID)
 	if err != nil {
-		return c.Send(MsgErrorUserData)
+		return c.Send(tr(c, MsgErrorUserData))
+	}
+
+	// Группируем цели по статусу: сначала цели в работе, в конце достигнутые
+	byStatus := make(map[string][]*models.Goal)
+	for _, goal := range goals {
+		byStatus[goal.Status] = append(byStatus[goal.Status], goal)
 	}
 
-	for i, goal := range goals {
-		status := StatusIconInactive
-		if goal.Status == GoalStatusCompleted {
-			status = StatusIconCompleted
-		} else if goal.ID == user.ActiveGoalID {
-			status = StatusIconActive
+	for _, status := range models.GoalStatuses {
+		group := byStatus[status]
+		if len(group) == 0 {
+			continue
 		}
-		message.WriteString(fmt.Sprintf("%s **%d. %s**\n", status, i+1, goal.Title))
-		if goal.Description != "" {
-			message.WriteString(fmt.Sprintf("   %s\n", goal.Description))
+
+		message.WriteString(fmt.Sprintf("**%s**\n\n", tr(c, goalStatusTitles[status])))
+		for i, goal := range group {
+			icon := goalStatusIcon(goal, user.ActiveGoalID)
+			if categoryIcon := goalCategoryIcon(goal.Category); categoryIcon != "" {
+				icon += " " + categoryIcon
+			}
+			message.WriteString(fmt.Sprintf("%s **%d. %s**\n", icon, i+1, goal.Title))
+			if goal.Description != "" {
+				message.WriteString(fmt.Sprintf("   %s\n", goal.Description))
+			}
+			if goal.StatusReason != "" && status != models.GoalStatusCompleted {
+				message.WriteString(tr(c, MsgGoalStatusReasonTemplate, goal.StatusReason))
+			}
+			message.WriteString("\n")
 		}
-		message.WriteString("\n")
 	}
 
-	return c.Send(message.String(), &tele.SendOptions{ParseMode: tele.ModeMarkdown})
+	// Кнопки для переключения активной цели
+	options := &tele.SendOptions{ParseMode: tele.ModeMarkdown}
+	if markup := b.switchGoalMarkup(goals, user.ActiveGoalID); markup != nil {
+		message.WriteString(tr(c, MsgSwitchGoalsHint))
+		options.ReplyMarkup = markup
+	}
+
+	return c.Send(message.String(), options)
 }
 
 // handleNewGoal обрабатывает команду /newgoal
@@ -210,92 +349,103 @@ func (b *Bot) handleNewGoal(c tele.Context) error {
 	state := b.getOrCreateState(c.Sender().ID)
 	state.State = StateWaitingGoalDescription
 	state.TempData = make(map[string]string)
+	b.saveState(state)
 
-	return c.Send(MsgNewGoalPrompt)
+	return c.Send(tr(c, MsgNewGoalPrompt))
 }
 
 // handleStatus обрабатывает команду /status
 func (b *Bot) handleStatus(c tele.Context) error {
+	ctx := requestContext(c)
 	userID := strconv.FormatInt(c.Sender().ID, 10)
 
-	user, err := b.repo.GetUser(userID)
+	user, err := b.repo.GetUser(ctx, userID)
 	if err != nil {
-		return c.Send(MsgErrorUserData)
+		return c.Send(tr(c, MsgErrorUserData))
 	}
 
 	if user.ActiveGoalID == "" {
-		return c.Send(MsgNoActiveGoal)
+		return c.Send(tr(c, MsgNoActiveGoal))
 	}
 
-	goal, err := b.repo.GetGoal(user.ActiveGoalID)
+	goal, err := b.repo.GetGoal(ctx, user.ActiveGoalID)
 	if err != nil {
-		return c.Send(MsgErrorActiveGoal)
+		return c.Send(tr(c, MsgErrorActiveGoal))
 	}
 
 	// Проверяем статус цели
-	if goal.Status == GoalStatusCompleted {
-		return c.Send(MsgGoalAlreadyCompleted)
+	if goal.Status == models.GoalStatusCompleted {
+		return c.Send(tr(c, MsgGoalAlreadyCompleted))
 	}
 
-	steps, err := b.repo.GetGoalSteps(goal.ID)
+	steps, err := b.repo.GetGoalSteps(ctx, goal.ID)
 	if err != nil {
-		return c.Send(MsgErrorSteps)
+		return c.Send(tr(c, MsgErrorSteps))
 	}
 
-	completedCount := 0
+	completedCount, skippedCount := 0, 0
 	for _, step := range steps {
 		if step.IsCompleted() {
 			completedCount++
+		} else if step.IsSkipped() {
+			skippedCount++
 		}
 	}
 
-	message := fmt.Sprintf(MsgActiveGoalTemplate, goal.Title)
+	message := tr(c, MsgActiveGoalTemplate, goal.Title)
 	if goal.Description != "" {
-		message += fmt.Sprintf(MsgGoalDescriptionTemplate, goal.Description)
+		message += tr(c, MsgGoalDescriptionTemplate, goal.Description)
+	}
+	// Пропущенные шаги не входят в прогресс
+	message += tr(c, MsgProgressTemplate, completedCount, len(steps)-skippedCount)
+	if skippedCount > 0 {
+		message += tr(c, MsgSkippedStepsTemplate, skippedCount)
 	}
-	message += fmt.Sprintf(MsgProgressTemplate, completedCount, len(steps))
-	message += MsgUseStepCommand
+	message += milestoneStatus(localizer(c), goal, steps)
+	message += tr(c, MsgUseStepCommand)
 
 	return c.Send(message, &tele.SendOptions{ParseMode: tele.ModeMarkdown})
 }
 
 // handleStep обрабатывает команду /step
 func (b *Bot) handleStep(c tele.Context) error {
+	ctx := requestContext(c)
 	userID := strconv.FormatInt(c.Sender().ID, 10)
 
-	user, err := b.repo.GetUser(userID)
+	user, err := b.repo.GetUser(ctx, userID)
 	if err != nil {
-		return c.Send(MsgErrorUserData)
+		return c.Send(tr(c, MsgErrorUserData))
 	}
 
 	if user.ActiveGoalID == "" {
-		return c.Send(MsgNoActiveGoal)
+		return c.Send(tr(c, MsgNoActiveGoal))
 	}
 
-	goal, err := b.repo.GetGoal(user.ActiveGoalID)
+	goal, err := b.repo.GetGoal(ctx, user.ActiveGoalID)
 	if err != nil {
-		return c.Send(MsgErrorActiveGoal)
+		return c.Send(tr(c, MsgErrorActiveGoal))
 	}
 
 	// Проверяем статус цели
-	if goal.Status == GoalStatusCompleted {
-		return c.Send(MsgGoalAlreadyCompleted)
+	if goal.Status == models.GoalStatusCompleted {
+		return c.Send(tr(c, MsgGoalAlreadyCompleted))
 	}
 
-	currentStep, err := b.repo.GetCurrentStep(user.ActiveGoalID)
+	currentStep, err := b.repo.GetCurrentStep(ctx, user.ActiveGoalID)
 	if err != nil {
-		return c.Send(MsgAllStepsCompleted)
+		return c.Send(tr(c, MsgAllStepsCompleted))
 	}
 
-	message := fmt.Sprintf(MsgCurrentStepTemplate, currentStep.Text)
+	message := tr(c, MsgCurrentStepTemplate, currentStep.Text)
 
 	menu := &tele.ReplyMarkup{ResizeKeyboard: true}
-	btnDone := menu.Text(BtnTextDone)
-	btnRephrase := menu.Text(BtnTextRephrase)
-	btnSimpler := menu.Text(BtnTextSimpler)
+	btnDone := menu.Text(tr(c, BtnTextDone))
+	btnSkip := menu.Text(tr(c, BtnTextSkip))
+	btnRephrase := menu.Text(tr(c, BtnTextRephrase))
+	btnSimpler := menu.Text(tr(c, BtnTextSimpler))
 
 	menu.Reply(
-		menu.Row(btnDone),
+		menu.Row(btnDone, btnSkip),
 		menu.Row(btnRephrase, btnSimpler),
 	)
 
@@ -304,77 +454,135 @@ func (b *Bot) handleStep(c tele.Context) error {
 
 // handleDone обрабатывает команду /done
 func (b *Bot) handleDone(c tele.Context) error {
+	ctx := requestContext(c)
 	userID := strconv.FormatInt(c.Sender().ID, 10)
 
-	user, err := b.repo.GetUser(userID)
+	user, err := b.repo.GetUser(ctx, userID)
 	if err != nil {
-		return c.Send(MsgErrorUserData)
+		return c.Send(tr(c, MsgErrorUserData))
 	}
 
 	if user.ActiveGoalID == "" {
-		return c.Send(MsgNoActiveGoal)
+		return c.Send(tr(c, MsgNoActiveGoal))
 	}
 
-	goal, err := b.repo.GetGoal(user.ActiveGoalID)
+	goal, err := b.repo.GetGoal(ctx, user.ActiveGoalID)
 	if err != nil {
-		return c.Send(MsgErrorGoal)
+		return c.Send(tr(c, MsgErrorGoal))
 	}
 
 	// Проверяем статус цели
-	if goal.Status == GoalStatusCompleted {
-		return c.Send(MsgGoalAlreadyCompleted)
+	if goal.Status == models.GoalStatusCompleted {
+		return c.Send(tr(c, MsgGoalAlreadyCompleted))
 	}
 
-	currentStep, err := b.repo.GetCurrentStep(user.ActiveGoalID)
+	currentStep, err := b.repo.GetCurrentStep(ctx, user.ActiveGoalID)
 	if err != nil {
-		return c.Send(MsgAllStepsCompleted)
+		return c.Send(tr(c, MsgAllStepsCompleted))
 	}
 
-	// Отмечаем шаг как выполненный
-	currentStep.Complete()
-	if err := b.repo.UpdateStep(currentStep); err != nil {
-		return c.Send(MsgErrorUpdateStep)
+	return b.completeStep(c, goal, currentStep)
+}
+
+// completeStep отмечает шаг цели выполненным
+// Цель не обязательно в фокусе: шаг можно выполнить и из /today
+func (b *Bot) completeStep(c tele.Context, goal *models.Goal, step *models.Step) error {
+	ctx := requestContext(c)
+
+	step.Complete()
+	if err := b.repo.UpdateStep(ctx, step); err != nil {
+		return c.Send(tr(c, MsgErrorUpdateStep))
 	}
+	rescheduleReminder(c)
 
-	return c.Send(MsgStepCompleted)
+	// Предлагаем оценить сложность шага - это учтется при генерации следующих
+	return c.Send(trGoal(c, goal, MsgStepCompleted)+"\n\n"+tr(c, MsgStepRatingPrompt), stepRatingMarkup(localizer(c), step.ID))
+}
+
+// handleSkip обрабатывает команду /skip [причина]
+// Пропущенный шаг не считается выполненным и передается LLM отдельно
+func (b *Bot) handleSkip(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorUserData))
+	}
+
+	if user.ActiveGoalID == "" {
+		return c.Send(tr(c, MsgNoActiveGoal))
+	}
+
+	goal, err := b.repo.GetGoal(ctx, user.ActiveGoalID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorGoal))
+	}
+
+	// Проверяем статус цели
+	if goal.Status == models.GoalStatusCompleted {
+		return c.Send(tr(c, MsgGoalAlreadyCompleted))
+	}
+
+	currentStep, err := b.repo.GetCurrentStep(ctx, user.ActiveGoalID)
+	if err != nil {
+		return c.Send(tr(c, MsgAllStepsCompleted))
+	}
+
+	// Причину можно указать только в команде, у кнопки её нет
+	reason := ""
+	if c.Message() != nil {
+		reason = strings.TrimSpace(c.Message().Payload)
+	}
+
+	currentStep.Skip(reason)
+	if err := b.repo.UpdateStep(ctx, currentStep); err != nil {
+		return c.Send(tr(c, MsgErrorUpdateStep))
+	}
+	rescheduleReminder(c)
+
+	return c.Send(tr(c, MsgStepSkipped))
 }
 
 // handleNext обрабатывает команду /next
 func (b *Bot) handleNext(c tele.Context) error {
+	ctx := requestContext(c)
 	userID := strconv.FormatInt(c.Sender().ID, 10)
 
-	user, err := b.repo.GetUser(userID)
+	user, err := b.repo.GetUser(ctx, userID)
 	if err != nil {
-		return c.Send(MsgErrorUserData)
+		return c.Send(tr(c, MsgErrorUserData))
 	}
 
 	if user.ActiveGoalID == "" {
-		return c.Send(MsgNoActiveGoal)
+		return c.Send(tr(c, MsgNoActiveGoal))
 	}
 
-	goal, err := b.repo.GetGoal(user.ActiveGoalID)
+	goal, err := b.repo.GetGoal(ctx, user.ActiveGoalID)
 	if err != nil {
-		return c.Send(MsgErrorGoal)
+		return c.Send(tr(c, MsgErrorGoal))
 	}
 
 	// Проверяем статус цели
-	if goal.Status == GoalStatusCompleted {
-		return c.Send(MsgGoalAlreadyCompleted)
+	if goal.Status == models.GoalStatusCompleted {
+		return c.Send(tr(c, MsgGoalAlreadyCompleted))
 	}
 
 	// Получаем все шаги для цели
-	allSteps, err := b.repo.GetGoalSteps(goal.ID)
+	allSteps, err := b.repo.GetGoalSteps(ctx, goal.ID)
 	if err != nil {
-		return c.Send(MsgErrorSteps)
+		return c.Send(tr(c, MsgErrorSteps))
 	}
 
 	// Проверяем, есть ли невыполненные шаги
 	var currentStep *models.Step
-	var completedSteps []*models.Step
+	var completedSteps, skippedSteps []*models.Step
 
 	for _, step := range allSteps {
 		if step.IsCompleted() {
 			completedSteps = append(completedSteps, step)
+		} else if step.IsSkipped() {
+			skippedSteps = append(skippedSteps, step)
 		} else {
 			// Нашли невыполненный шаг
 			if currentStep == nil || step.CreatedAt.Before(currentStep.CreatedAt) {
@@ -385,7 +593,7 @@ func (b *Bot) handleNext(c tele.Context) error {
 
 	// Если есть невыполненный шаг, предлагаем его выполнить
 	if currentStep != nil {
-		message := fmt.Sprintf(MsgUnfinishedStepTemplate, currentStep.Text)
+		message := tr(c, MsgUnfinishedStepTemplate, currentStep.Text)
 		return c.Send(message, &tele.SendOptions{ParseMode: tele.ModeMarkdown})
 	}
 
@@ -397,10 +605,10 @@ func (b *Bot) handleNext(c tele.Context) error {
 	if len(completedSteps) == 0 && len(goal.Context.Clarifications) == 0 {
 		// Это первый шаг и контекст не собран - собираем контекст
 		log.Printf("🔍 Собираем контекст для новой цели")
-		contextResponse, err := b.llmClient.GatherContext(goal)
+		contextResponse, err := b.llmClient.GatherContext(ctx, goal)
 		if err != nil {
 			log.Printf("❌ Ошибка при сборе контекста: %v", err)
-			return c.Send(MsgErrorGatherContext)
+			return c.Send(tr(c, MsgErrorGatherContext))
 		}
 
 		if contextResponse.Status == LLMStatusNeedContext {
@@ -409,58 +617,67 @@ func (b *Bot) handleNext(c tele.Context) error {
 			state.State = StateGatheringContext
 			state.TempData["goal_id"] = goal.ID
 			state.TempData["context_question"] = contextResponse.Question
+			b.saveState(state)
 
-			message := fmt.Sprintf(MsgContextQuestionTemplate, contextResponse.Question)
+			message := tr(c, MsgContextQuestionTemplate, contextResponse.Question)
 			return c.Send(message, &tele.SendOptions{ParseMode: tele.ModeMarkdown})
 		}
 	}
 
-	response, err := b.llmClient.GenerateStep(goal, completedSteps)
+	level := b.stepLevel(ctx, userID, allSteps)
+	response, err := b.llmClient.GenerateStep(ctx, goal, completedSteps, skippedSteps, level)
 	if err != nil {
 		log.Printf("❌ Ошибка при генерации шага: %v", err)
-		return c.Send(fmt.Sprintf("❌ Ошибка при генерации шага: %v", err))
+		return c.Send(tr(c, MsgErrorGenerateStepDetailsTemplate, err))
 	}
 
 	log.Printf("🔍 Получен ответ от LLM: статус=%s, шаг=%s", response.Status, response.Step)
 
 	if response.Status == LLMStatusNeedClarification {
-		return c.Send(fmt.Sprintf(MsgClarificationTemplate, response.Question))
+		return c.Send(tr(c, MsgClarificationTemplate, response.Question))
 	}
 
 	// Обрабатываем завершение цели
 	if response.Status == LLMStatusGoalCompleted {
 		// Получаем пользователя для завершения цели
 		userID := strconv.FormatInt(c.Sender().ID, 10)
-		user, err := b.repo.GetUser(userID)
+		user, err := b.repo.GetUser(ctx, userID)
 		if err != nil {
-			return c.Send(MsgErrorUserData)
+			return c.Send(tr(c, MsgErrorUserData))
 		}
 
-		if err := b.completeGoal(goal, user, response.CompletionReason); err != nil {
-			return c.Send(MsgErrorUpdateGoal)
+		next, err := b.completeGoal(ctx, goal, user, response.CompletionReason)
+		if err != nil {
+			return c.Send(tr(c, MsgErrorUpdateGoal))
 		}
-		message := fmt.Sprintf(MsgGoalCompletedTemplate, goal.Title, response.CompletionReason)
+		rescheduleReminder(c)
+		message := trGoal(c, goal, MsgGoalCompletedTemplate, goal.Title, response.CompletionReason) + focusMovedNote(c, next)
 		return c.Send(message, &tele.SendOptions{ParseMode: tele.ModeMarkdown})
 	}
 
+	// LLM могла решить, что текущий этап пройден и новый шаг уже из следующего
+	milestoneNote := b.advanceMilestone(c, goal, response)
+
 	// Обрабатываем близость к завершению
 	if response.Status == LLMStatusNearCompletion {
 		// Создаем новый шаг
-		newStep := models.NewStep(goal.ID, response.Step)
-		if err := b.repo.CreateStep(newStep); err != nil {
-			return c.Send(MsgErrorCreateStep)
+		newStep := goal.NewStep(response.Step)
+		if err := b.repo.CreateStep(ctx, newStep); err != nil {
+			return c.Send(tr(c, MsgErrorCreateStep))
 		}
+		rescheduleReminder(c)
 
-		message := fmt.Sprintf(MsgNearCompletionTemplate, newStep.Text)
+		message := milestoneNote + trGoal(c, goal, MsgNearCompletionTemplate, newStep.Text)
 
 		menu := &tele.ReplyMarkup{ResizeKeyboard: true}
-		btnDone := menu.Text(BtnTextDone)
-		btnRephrase := menu.Text(BtnTextRephrase)
-		btnSimpler := menu.Text(BtnTextSimpler)
-		btnComplete := menu.Text(BtnTextComplete)
+		btnDone := menu.Text(tr(c, BtnTextDone))
+		btnSkip := menu.Text(tr(c, BtnTextSkip))
+		btnRephrase := menu.Text(tr(c, BtnTextRephrase))
+		btnSimpler := menu.Text(tr(c, BtnTextSimpler))
+		btnComplete := menu.Text(tr(c, BtnTextComplete))
 
 		menu.Reply(
-			menu.Row(btnDone),
+			menu.Row(btnDone, btnSkip),
 			menu.Row(btnRephrase, btnSimpler),
 			menu.Row(btnComplete),
 		)
@@ -471,20 +688,22 @@ func (b *Bot) handleNext(c tele.Context) error {
 	// Обычный шаг
 	if response.Status == LLMStatusOK {
 		// Создаем новый шаг
-		newStep := models.NewStep(goal.ID, response.Step)
-		if err := b.repo.CreateStep(newStep); err != nil {
-			return c.Send(MsgErrorCreateStep)
+		newStep := goal.NewStep(response.Step)
+		if err := b.repo.CreateStep(ctx, newStep); err != nil {
+			return c.Send(tr(c, MsgErrorCreateStep))
 		}
+		rescheduleReminder(c)
 
-		message := fmt.Sprintf(MsgNewStepTemplate, newStep.Text)
+		message := milestoneNote + tr(c, MsgNewStepTemplate, newStep.Text)
 
 		menu := &tele.ReplyMarkup{ResizeKeyboard: true}
-		btnDone := menu.Text(BtnTextDone)
-		btnRephrase := menu.Text(BtnTextRephrase)
-		btnSimpler := menu.Text(BtnTextSimpler)
+		btnDone := menu.Text(tr(c, BtnTextDone))
+		btnSkip := menu.Text(tr(c, BtnTextSkip))
+		btnRephrase := menu.Text(tr(c, BtnTextRephrase))
+		btnSimpler := menu.Text(tr(c, BtnTextSimpler))
 
 		menu.Reply(
-			menu.Row(btnDone),
+			menu.Row(btnDone, btnSkip),
 			menu.Row(btnRephrase, btnSimpler),
 		)
 
@@ -492,7 +711,7 @@ func (b *Bot) handleNext(c tele.Context) error {
 	}
 
 	// Неизвестный статус
-	return c.Send(MsgErrorUnexpectedResponse)
+	return c.Send(tr(c, MsgErrorUnexpectedResponse))
 }
 
 // handleRephrase обрабатывает команду /rephrase
@@ -501,84 +720,161 @@ func (b *Bot) handleRephrase(c tele.Context) error {
 	state := b.getOrCreateState(c.Sender().ID)
 	state.State = StateRephrasing
 	state.TempData = make(map[string]string)
+	b.saveState(state)
 
-	return c.Send(MsgRephrasePrompt)
+	return c.Send(tr(c, MsgRephrasePrompt))
 }
 
 // handleSimpler обрабатывает команду /simpler
 func (b *Bot) handleSimpler(c tele.Context) error {
+	ctx := requestContext(c)
 	userID := strconv.FormatInt(c.Sender().ID, 10)
 
-	user, err := b.repo.GetUser(userID)
+	user, err := b.repo.GetUser(ctx, userID)
 	if err != nil {
-		return c.Send(MsgErrorUserData)
+		return c.Send(tr(c, MsgErrorUserData))
 	}
 
 	if user.ActiveGoalID == "" {
-		return c.Send(MsgNoActiveGoal)
+		return c.Send(tr(c, MsgNoActiveGoal))
 	}
 
-	goal, err := b.repo.GetGoal(user.ActiveGoalID)
+	goal, err := b.repo.GetGoal(ctx, user.ActiveGoalID)
 	if err != nil {
-		return c.Send(MsgErrorGoal)
+		return c.Send(tr(c, MsgErrorGoal))
 	}
 
 	// Проверяем статус цели
-	if goal.Status == GoalStatusCompleted {
-		return c.Send(MsgGoalAlreadyCompleted)
+	if goal.Status == models.GoalStatusCompleted {
+		return c.Send(tr(c, MsgGoalAlreadyCompleted))
 	}
 
-	currentStep, err := b.repo.GetCurrentStep(user.ActiveGoalID)
+	currentStep, err := b.repo.GetCurrentStep(ctx, user.ActiveGoalID)
 	if err != nil {
-		return c.Send(MsgAllStepsCompleted)
+		return c.Send(tr(c, MsgAllStepsCompleted))
 	}
 
-	// Переформулируем шаг с просьбой сделать его проще
-	response, err := b.llmClient.RephraseStep(goal, currentStep, MsgSimplifyPrompt)
-	if err != nil {
-		return c.Send(MsgErrorSimplifyStep)
-	}
-
-	// Обновляем шаг
-	currentStep.Text = response.Step
-	currentStep.Rephrase(MsgUserRequestedSimplification)
-	if err := b.repo.UpdateStep(currentStep); err != nil {
-		return c.Send(MsgErrorUpdateStep)
-	}
-
-	message := fmt.Sprintf(MsgStepSimplifiedTemplate, currentStep.Text)
-
 	menu := &tele.ReplyMarkup{ResizeKeyboard: true}
-	btnDone := menu.Text(BtnTextDone)
-	btnRephrase := menu.Text(BtnTextRephrase)
+	btnDone := menu.Text(tr(c, BtnTextDone))
+	btnSkip := menu.Text(tr(c, BtnTextSkip))
+	btnRephrase := menu.Text(tr(c, BtnTextRephrase))
 
 	menu.Reply(
-		menu.Row(btnDone),
+		menu.Row(btnDone, btnSkip),
 		menu.Row(btnRephrase),
 	)
 
-	return c.Send(message, menu, &tele.SendOptions{ParseMode: tele.ModeMarkdown})
+	return b.simplifyStep(c, goal, currentStep, menu)
+}
+
+// simplifyStep упрощает шаг цели через LLM и отправляет новую формулировку с клавиатурой menu (может быть nil)
+// Цель не обязательно в фокусе: шаг можно упростить и из /today
+func (b *Bot) simplifyStep(c tele.Context, goal *models.Goal, step *models.Step, menu *tele.ReplyMarkup) error {
+	ctx := requestContext(c)
+
+	// Переформулируем шаг с просьбой сделать его проще
+	response, err := b.llmClient.RephraseStep(ctx, goal, step, tr(c, MsgSimplifyPrompt))
+	if err != nil {
+		return c.Send(tr(c, MsgErrorSimplifyStep))
+	}
+
+	// Обновляем шаг, прежняя формулировка остается в истории
+	step.Simplify(response.Step, tr(c, MsgUserRequestedSimplification))
+	if err := b.repo.UpdateStep(ctx, step); err != nil {
+		return c.Send(tr(c, MsgErrorUpdateStep))
+	}
+	rescheduleReminder(c)
+
+	message := tr(c, MsgStepSimplifiedTemplate, step.Text)
+	return c.Send(message, &tele.SendOptions{ParseMode: tele.ModeMarkdown, ReplyMarkup: menu})
 }
 
 // handleSwitch обрабатывает команду /switch
 func (b *Bot) handleSwitch(c tele.Context) error {
+	ctx := requestContext(c)
 	userID := strconv.FormatInt(c.Sender().ID, 10)
 
-	goals, err := b.repo.GetUserGoals(userID)
+	goals, err := b.repo.GetUserGoals(ctx, userID)
 	if err != nil {
-		return c.Send(MsgErrorSteps)
+		return c.Send(tr(c, MsgErrorSteps))
 	}
 
 	if len(goals) == 0 {
-		return c.Send(MsgNoGoalsForSwitch)
+		return c.Send(tr(c, MsgNoGoalsForSwitch))
+	}
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorUserData))
+	}
+
+	markup := b.switchGoalMarkup(goals, user.ActiveGoalID)
+	if markup == nil {
+		return c.Send(tr(c, MsgNoGoalsForSwitch))
+	}
+
+	return c.Send(tr(c, MsgSwitchGoalsPrompt), markup)
+}
+
+// handleSwitchGoal обрабатывает нажатие inline кнопки выбора цели
+func (b *Bot) handleSwitchGoal(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+	goalID := c.Callback().Data
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUserData)})
+	}
+
+	goal, err := b.repo.GetGoal(ctx, goalID)
+	if err != nil || goal.UserID != userID {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorGoal)})
+	}
+
+	if goal.Status == models.GoalStatusCompleted {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgGoalAlreadyCompleted), ShowAlert: true})
+	}
+	if !goal.IsActive() {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgGoalNotActive), ShowAlert: true})
 	}
 
-	// TODO: Реализовать inline кнопки для выбора цели
-	return c.Send(MsgSwitchGoalsPrompt + b.formatGoalsList(goals))
+	if user.ActiveGoalID != goal.ID {
+		user.ActiveGoalID = goal.ID
+		if err := b.repo.UpdateUser(ctx, user); err != nil {
+			return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUpdateUser)})
+		}
+		rescheduleReminder(c)
+	}
+
+	if err := c.Respond(&tele.CallbackResponse{Text: tr(c, MsgGoalSwitchedTemplate, goal.Title)}); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+
+	// Убираем кнопки из исходного сообщения, чтобы не переключаться по устаревшему списку
+	if err := c.Edit(tr(c, MsgGoalSwitchedTemplate, goal.Title)); err != nil {
+		log.Printf("❌ Ошибка при редактировании сообщения: %v", err)
+	}
+
+	// Показываем текущий шаг выбранной цели
+	return b.handleStep(c)
+}
+
+// handleCancel обрабатывает команду /cancel
+// Запросы пользователя к этому моменту уже отменены в trackRequests,
+// здесь остается только сбросить незавершенный диалог
+func (b *Bot) handleCancel(c tele.Context) error {
+	state := b.getOrCreateState(c.Sender().ID)
+	state.State = StateIdle
+	state.TempData = make(map[string]string)
+	b.saveState(state)
+
+	return c.Send(tr(c, MsgCancelled))
 }
 
 // handleText обрабатывает текстовые сообщения
 func (b *Bot) handleText(c tele.Context) error {
+	ctx := requestContext(c)
 	state := b.getOrCreateState(c.Sender().ID)
 	text := c.Text()
 
@@ -588,34 +884,37 @@ func (b *Bot) handleText(c tele.Context) error {
 	switch state.State {
 	case StateWaitingGoalDescription:
 		// Генерируем название цели через LLM
-		title, err := b.llmClient.GenerateGoalTitle(text)
+		title, err := b.llmClient.GenerateGoalTitle(ctx, text)
 		if err != nil {
-			return c.Send(MsgErrorGenerateStep)
+			return c.Send(tr(c, MsgErrorGenerateStep))
 		}
 
 		// Создаем цель
 		userID := strconv.FormatInt(c.Sender().ID, 10)
 		goal := models.NewGoal(userID, title, text)
+		b.classifyGoal(ctx, goal)
 
-		if err := b.repo.CreateGoal(goal); err != nil {
-			return c.Send(MsgErrorCreateGoal)
+		if err := b.repo.CreateGoal(ctx, goal); err != nil {
+			return c.Send(tr(c, MsgErrorCreateGoal))
 		}
 
 		// Устанавливаем как активную
-		user, err := b.repo.GetUser(userID)
+		user, err := b.repo.GetUser(ctx, userID)
 		if err != nil {
-			return c.Send(MsgErrorUserData)
+			return c.Send(tr(c, MsgErrorUserData))
 		}
 		user.ActiveGoalID = goal.ID
-		if err := b.repo.UpdateUser(user); err != nil {
-			return c.Send(MsgErrorUpdateUser)
+		if err := b.repo.UpdateUser(ctx, user); err != nil {
+			return c.Send(tr(c, MsgErrorUpdateUser))
 		}
+		rescheduleReminder(c)
 		log.Printf("🔍 Пользователь: %+v", user)
 		// Сбрасываем состояние
 		state.State = StateIdle
 		state.TempData = make(map[string]string)
+		b.saveState(state)
 
-		message := fmt.Sprintf(MsgGoalCreatedTemplate, goal.Title, goal.Description)
+		message := tr(c, MsgGoalCreatedTemplate, goal.Title, goal.Description)
 		return c.Send(message, &tele.SendOptions{ParseMode: tele.ModeMarkdown})
 
 	case StateGatheringContext:
@@ -624,82 +923,99 @@ func (b *Bot) handleText(c tele.Context) error {
 		question := state.TempData["context_question"]
 
 		if goalID == "" {
-			return c.Send(MsgGoalNotFoundError)
+			return c.Send(tr(c, MsgGoalNotFoundError))
 		}
 
 		// Получаем цель
-		goal, err := b.repo.GetGoal(goalID)
+		goal, err := b.repo.GetGoal(ctx, goalID)
 		if err != nil {
-			return c.Send(MsgErrorGoal)
+			return c.Send(tr(c, MsgErrorGoal))
 		}
 
 		// Добавляем уточнение в контекст
-		goal.AddClarification(question, text)
-		if err := b.repo.UpdateGoal(goal); err != nil {
-			return c.Send(MsgErrorUpdateGoal)
+		goal.AddClarification(question, text, models.ClarificationSourceContext)
+		if err := b.repo.UpdateGoal(ctx, goal); err != nil {
+			return c.Send(tr(c, MsgErrorUpdateGoal))
 		}
 
 		// Проверяем, нужен ли еще контекст
-		contextResponse, err := b.llmClient.GatherContext(goal)
+		contextResponse, err := b.llmClient.GatherContext(ctx, goal)
 		if err != nil {
-			return c.Send(MsgErrorGatherContext)
+			return c.Send(tr(c, MsgErrorGatherContext))
 		}
 
 		if contextResponse.Status == LLMStatusNeedContext {
 			// Нужен еще контекст
 			state.TempData["context_question"] = contextResponse.Question
-			message := fmt.Sprintf(MsgContextThanksTemplate, contextResponse.Question)
+			b.saveState(state)
+			message := tr(c, MsgContextThanksTemplate, contextResponse.Question)
 			return c.Send(message, &tele.SendOptions{ParseMode: tele.ModeMarkdown})
 		}
 
+		// Контекст дополняли через /context - шаги уже есть, новый не нужен
+		if state.TempData["context_refresh"] != "" {
+			state.State = StateIdle
+			state.TempData = make(map[string]string)
+			b.saveState(state)
+			return c.Send(tr(c, MsgContextUpdated))
+		}
+
 		// Контекст собран, генерируем первый шаг
 		completedSteps := []*models.Step{} // Пустой массив для первого шага
-		response, err := b.llmClient.GenerateStep(goal, completedSteps)
+		level := b.stepLevel(ctx, goal.UserID, nil)
+		response, err := b.llmClient.GenerateStep(ctx, goal, completedSteps, nil, level)
 		if err != nil {
-			return c.Send(MsgErrorGenerateStep)
+			return c.Send(tr(c, MsgErrorGenerateStep))
 		}
 
 		// Сбрасываем состояние
 		state.State = StateIdle
 		state.TempData = make(map[string]string)
+		b.saveState(state)
 
 		// Обрабатываем ответ LLM
 		if response.Status == LLMStatusNeedClarification {
-			return c.Send(fmt.Sprintf(MsgClarificationTemplate, response.Question))
+			return c.Send(tr(c, MsgClarificationTemplate, response.Question))
 		}
 
 		if response.Status == LLMStatusGoalCompleted {
 			// Получаем пользователя для завершения цели
 			userID := strconv.FormatInt(c.Sender().ID, 10)
-			user, err := b.repo.GetUser(userID)
+			user, err := b.repo.GetUser(ctx, userID)
 			if err != nil {
-				return c.Send(MsgErrorUserData)
+				return c.Send(tr(c, MsgErrorUserData))
 			}
 
-			if err := b.completeGoal(goal, user, response.CompletionReason); err != nil {
-				return c.Send(MsgErrorUpdateGoal)
+			next, err := b.completeGoal(ctx, goal, user, response.CompletionReason)
+			if err != nil {
+				return c.Send(tr(c, MsgErrorUpdateGoal))
 			}
-			message := fmt.Sprintf(MsgGoalCompletedTemplate, goal.Title, response.CompletionReason)
+			rescheduleReminder(c)
+			message := trGoal(c, goal, MsgGoalCompletedTemplate, goal.Title, response.CompletionReason) + focusMovedNote(c, next)
 			return c.Send(message, &tele.SendOptions{ParseMode: tele.ModeMarkdown})
 		}
 
+		milestoneNote := b.advanceMilestone(c, goal, response)
+
 		if response.Status == LLMStatusNearCompletion {
 			// Создаем новый шаг
-			newStep := models.NewStep(goal.ID, response.Step)
-			if err := b.repo.CreateStep(newStep); err != nil {
-				return c.Send(MsgErrorCreateStep)
+			newStep := goal.NewStep(response.Step)
+			if err := b.repo.CreateStep(ctx, newStep); err != nil {
+				return c.Send(tr(c, MsgErrorCreateStep))
 			}
+			rescheduleReminder(c)
 
-			message := fmt.Sprintf(MsgNearCompletionTemplate, newStep.Text)
+			message := milestoneNote + trGoal(c, goal, MsgNearCompletionTemplate, newStep.Text)
 
 			menu := &tele.ReplyMarkup{ResizeKeyboard: true}
-			btnDone := menu.Text(BtnTextDone)
-			btnRephrase := menu.Text(BtnTextRephrase)
-			btnSimpler := menu.Text(BtnTextSimpler)
-			btnComplete := menu.Text(BtnTextComplete)
+			btnDone := menu.Text(tr(c, BtnTextDone))
+			btnSkip := menu.Text(tr(c, BtnTextSkip))
+			btnRephrase := menu.Text(tr(c, BtnTextRephrase))
+			btnSimpler := menu.Text(tr(c, BtnTextSimpler))
+			btnComplete := menu.Text(tr(c, BtnTextComplete))
 
 			menu.Reply(
-				menu.Row(btnDone),
+				menu.Row(btnDone, btnSkip),
 				menu.Row(btnRephrase, btnSimpler),
 				menu.Row(btnComplete),
 			)
@@ -709,173 +1025,244 @@ func (b *Bot) handleText(c tele.Context) error {
 
 		if response.Status == LLMStatusOK {
 			// Создаем новый шаг
-			newStep := models.NewStep(goal.ID, response.Step)
-			if err := b.repo.CreateStep(newStep); err != nil {
-				return c.Send(MsgErrorCreateStep)
+			newStep := goal.NewStep(response.Step)
+			if err := b.repo.CreateStep(ctx, newStep); err != nil {
+				return c.Send(tr(c, MsgErrorCreateStep))
 			}
+			rescheduleReminder(c)
 
-			message := fmt.Sprintf(MsgFirstStepTemplate, newStep.Text)
+			message := milestoneNote + tr(c, MsgFirstStepTemplate, newStep.Text)
 
 			menu := &tele.ReplyMarkup{ResizeKeyboard: true}
-			btnDone := menu.Text(BtnTextDone)
-			btnRephrase := menu.Text(BtnTextRephrase)
-			btnSimpler := menu.Text(BtnTextSimpler)
+			btnDone := menu.Text(tr(c, BtnTextDone))
+			btnSkip := menu.Text(tr(c, BtnTextSkip))
+			btnRephrase := menu.Text(tr(c, BtnTextRephrase))
+			btnSimpler := menu.Text(tr(c, BtnTextSimpler))
 
 			menu.Reply(
-				menu.Row(btnDone),
+				menu.Row(btnDone, btnSkip),
 				menu.Row(btnRephrase, btnSimpler),
 			)
 
 			return c.Send(message, menu, &tele.SendOptions{ParseMode: tele.ModeMarkdown})
 		}
 
-		return c.Send(MsgErrorUnexpectedResponse)
+		return c.Send(tr(c, MsgErrorUnexpectedResponse))
+
+	case StateWaitingTimezone, StateWaitingQuietHours:
+		return b.handleSettingsInput(c, state)
+
+	case StateEditingClarification:
+		return b.handleClarificationInput(c, state)
+
+	case StateEditingMilestone:
+		return b.handleMilestoneInput(c, state)
+
+	case StateEditingGoalTitle, StateEditingGoalDescription:
+		return b.handleGoalEditInput(c, state)
+
+	case StateWaitingStepNote:
+		return b.handleStepNoteInput(c, state)
 
 	case StateRephrasing:
 		userID := strconv.FormatInt(c.Sender().ID, 10)
-		user, err := b.repo.GetUser(userID)
+		user, err := b.repo.GetUser(ctx, userID)
 		if err != nil {
-			return c.Send(MsgErrorUserData)
+			return c.Send(tr(c, MsgErrorUserData))
 		}
 
-		currentStep, err := b.repo.GetCurrentStep(user.ActiveGoalID)
+		currentStep, err := b.repo.GetCurrentStep(ctx, user.ActiveGoalID)
 		if err != nil {
-			return c.Send(MsgCurrentStepError)
+			return c.Send(tr(c, MsgCurrentStepError))
 		}
 
-		goal, err := b.repo.GetGoal(user.ActiveGoalID)
+		goal, err := b.repo.GetGoal(ctx, user.ActiveGoalID)
 		if err != nil {
-			return c.Send(MsgErrorGoal)
+			return c.Send(tr(c, MsgErrorGoal))
 		}
 
 		// Переформулируем шаг через LLM
-		response, err := b.llmClient.RephraseStep(goal, currentStep, text)
+		response, err := b.llmClient.RephraseStep(ctx, goal, currentStep, text)
 		if err != nil {
-			return c.Send(MsgErrorRephraseStep)
+			return c.Send(tr(c, MsgErrorRephraseStep))
 		}
 
-		// Обновляем шаг
-		currentStep.Text = response.Step
-		currentStep.Rephrase(text)
-		if err := b.repo.UpdateStep(currentStep); err != nil {
-			return c.Send(MsgErrorUpdateStep)
+		// Обновляем шаг, прежняя формулировка остается в истории
+		currentStep.Rephrase(response.Step, text)
+		if err := b.repo.UpdateStep(ctx, currentStep); err != nil {
+			return c.Send(tr(c, MsgErrorUpdateStep))
 		}
+		rescheduleReminder(c)
 
 		// Сбрасываем состояние
 		state.State = StateIdle
 		state.TempData = make(map[string]string)
+		b.saveState(state)
 
-		message := fmt.Sprintf(MsgStepRephrasedTemplate, currentStep.Text)
+		message := tr(c, MsgStepRephrasedTemplate, currentStep.Text)
 		return c.Send(message)
 
 	default:
-		return c.Send(MsgHelpDefault)
+		return c.Send(tr(c, MsgHelpDefault))
 	}
 }
 
 // getOrCreateState получает или создает состояние пользователя
-func (b *Bot) getOrCreateState(userID int64) *UserState {
-	if state, exists := b.states[userID]; exists {
-		return state
+// Само состояние меняется только под мьютексом пользователя (см. serializeUpdates)
+func (b *Bot) getOrCreateState(userID int64) *models.UserState {
+	b.statesMutex.Lock()
+	state, exists := b.states[userID]
+	if !exists {
+		state = models.NewUserState(userID, StateIdle)
+		b.states[userID] = state
 	}
+	b.statesMutex.Unlock()
 
-	state := &UserState{
-		UserID:   userID,
-		State:    StateIdle,
-		TempData: make(map[string]string),
+	// Брошенный диалог сбрасываем в исходное состояние
+	if exists && state.State != StateIdle && state.IsExpired(BotStateTTLHours*time.Hour) {
+		log.Printf("🔍 Состояние пользователя %d устарело (%s), сбрасываем", userID, state.State)
+		state.State = StateIdle
+		state.TempData = make(map[string]string)
+		b.saveState(state)
 	}
-	b.states[userID] = state
+
 	return state
 }
 
-// formatGoalsList форматирует список целей для отображения
-func (b *Bot) formatGoalsList(goals []*models.Goal) string {
-	var result strings.Builder
-	for i, goal := range goals {
-		result.WriteString(fmt.Sprintf("%d. %s\n", i+1, goal.Title))
+// saveState сохраняет состояние пользователя в хранилище
+// Состояние покоя без временных данных не хранится
+func (b *Bot) saveState(state *models.UserState) {
+	state.UpdatedAt = time.Now()
+
+	var err error
+	if state.State == StateIdle && len(state.TempData) == 0 {
+		err = b.stateStore.DeleteState(state.UserID)
+	} else {
+		err = b.stateStore.SaveState(state)
+	}
+	if err != nil {
+		log.Printf("❌ Ошибка при сохранении состояния пользователя %d: %v", state.UserID, err)
 	}
-	return result.String()
 }
 
-// completeGoal завершает цель и сбрасывает активную цель пользователя
-func (b *Bot) completeGoal(goal *models.Goal, user *models.User, completionReason string) error {
-	// Отмечаем цель как завершенную
-	goal.Status = GoalStatusCompleted
-	now := time.Now()
-	goal.CompletedAt = &now
-	goal.UpdatedAt = now
-
-	if err := b.repo.UpdateGoal(goal); err != nil {
-		return fmt.Errorf("failed to update goal: %w", err)
+// restoreStates загружает сохраненные состояния, отбрасывая устаревшие
+func (b *Bot) restoreStates() {
+	states, err := b.stateStore.ListStates()
+	if err != nil {
+		log.Printf("❌ Ошибка при загрузке состояний пользователей: %v", err)
+		return
 	}
 
-	// Сбрасываем активную цель пользователя
-	user.ActiveGoalID = ""
-	if err := b.repo.UpdateUser(user); err != nil {
-		return fmt.Errorf("failed to update user: %w", err)
+	restored := 0
+	for _, state := range states {
+		if state.IsExpired(BotStateTTLHours * time.Hour) {
+			if err := b.stateStore.DeleteState(state.UserID); err != nil {
+				log.Printf("❌ Ошибка при удалении состояния пользователя %d: %v", state.UserID, err)
+			}
+			continue
+		}
+		if state.TempData == nil {
+			state.TempData = make(map[string]string)
+		}
+		b.statesMutex.Lock()
+		b.states[state.UserID] = state
+		b.statesMutex.Unlock()
+		restored++
 	}
 
-	return nil
+	log.Printf("🔍 Восстановлено состояний пользователей: %d", restored)
 }
 
-// handleComplete обрабатывает команду /complete
-func (b *Bot) handleComplete(c tele.Context) error {
-	userID := strconv.FormatInt(c.Sender().ID, 10)
-
-	user, err := b.repo.GetUser(userID)
-	if err != nil {
-		return c.Send(MsgErrorUserData)
+// goalStatusIcon возвращает иконку статуса цели для отображения в списках
+func goalStatusIcon(goal *models.Goal, activeGoalID string) string {
+	switch goal.Status {
+	case models.GoalStatusCompleted:
+		return StatusIconCompleted
+	case models.GoalStatusPaused:
+		return StatusIconPaused
+	case models.GoalStatusAbandoned:
+		return StatusIconAbandoned
 	}
+	if goal.ID == activeGoalID {
+		return StatusIconActive
+	}
+	return StatusIconInactive
+}
 
-	if user.ActiveGoalID == "" {
-		return c.Send(MsgNoActiveGoal)
+// switchGoalMarkup создает inline клавиатуру для выбора цели в фокусе
+// В список попадают только цели в работе (отложенные возвращаются через /resume);
+// если выбирать не из чего, возвращает nil
+func (b *Bot) switchGoalMarkup(goals []*models.Goal, activeGoalID string) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+
+	var rows []tele.Row
+	for _, goal := range goals {
+		if !goal.IsActive() {
+			continue
+		}
+		text := fmt.Sprintf("%s %s", goalStatusIcon(goal, activeGoalID), goal.Title)
+		rows = append(rows, markup.Row(markup.Data(text, CallbackSwitchGoal, goal.ID)))
 	}
 
-	goal, err := b.repo.GetGoal(user.ActiveGoalID)
-	if err != nil {
-		return c.Send(MsgErrorGoal)
+	if len(rows) == 0 {
+		return nil
 	}
 
-	// Отмечаем цель как завершенную
-	goal.Status = GoalStatusCompleted
-	now := time.Now()
-	goal.CompletedAt = &now
-	goal.UpdatedAt = now
+	markup.Inline(rows...)
+	return markup
+}
 
-	if err := b.repo.UpdateGoal(goal); err != nil {
-		return c.Send(MsgErrorUpdateGoal)
+// completeGoal завершает цель и переводит фокус на другую цель в работе
+// Возвращает новую цель в фокусе (nil, если целей в работе не осталось)
+func (b *Bot) completeGoal(ctx context.Context, goal *models.Goal, user *models.User, completionReason string) (*models.Goal, error) {
+	// Отмечаем цель как завершенную
+	if err := goal.SetStatus(models.GoalStatusCompleted, completionReason); err != nil {
+		return nil, err
 	}
 
-	// Сбрасываем активную цель пользователя
-	user.ActiveGoalID = ""
-	if err := b.repo.UpdateUser(user); err != nil {
-		return c.Send(MsgErrorUpdateUser)
+	if err := b.repo.UpdateGoal(ctx, goal); err != nil {
+		return nil, fmt.Errorf("failed to update goal: %w", err)
 	}
 
-	message := fmt.Sprintf(MsgGoalCompletedManualTemplate, goal.Title)
-	return c.Send(message, &tele.SendOptions{ParseMode: tele.ModeMarkdown})
+	return b.refocus(ctx, user, goal.ID)
 }
 
-// handleContext обрабатывает команду /context
-func (b *Bot) handleContext(c tele.Context) error {
+// handleComplete обрабатывает команду /complete
+func (b *Bot) handleComplete(c tele.Context) error {
+	ctx := requestContext(c)
 	userID := strconv.FormatInt(c.Sender().ID, 10)
 
-	user, err := b.repo.GetUser(userID)
+	user, err := b.repo.GetUser(ctx, userID)
 	if err != nil {
-		return c.Send(MsgErrorUserData)
+		return c.Send(tr(c, MsgErrorUserData))
 	}
 
 	if user.ActiveGoalID == "" {
-		return c.Send(MsgNoActiveGoal)
+		return c.Send(tr(c, MsgNoActiveGoal))
 	}
 
-	goal, err := b.repo.GetGoal(user.ActiveGoalID)
+	goal, err := b.repo.GetGoal(ctx, user.ActiveGoalID)
 	if err != nil {
-		return c.Send(MsgErrorGoal)
+		return c.Send(tr(c, MsgErrorGoal))
+	}
+
+	// Отмечаем цель как завершенную
+	if err := goal.SetStatus(models.GoalStatusCompleted, ""); err != nil {
+		return c.Send(tr(c, MsgGoalAlreadyCompleted))
 	}
 
-	message := fmt.Sprintf(MsgContextSummaryTemplate, goal.Title)
-	message += goal.GetContextSummary()
+	if err := b.repo.UpdateGoal(ctx, goal); err != nil {
+		return c.Send(tr(c, MsgErrorUpdateGoal))
+	}
+
+	// Фокус переходит на другую цель в работе
+	next, err := b.refocus(ctx, user, goal.ID)
+	if err != nil {
+		log.Printf("❌ Ошибка при смене цели в фокусе: %v", err)
+		return c.Send(tr(c, MsgErrorUpdateUser))
+	}
+	rescheduleReminder(c)
 
+	message := trGoal(c, goal, MsgGoalCompletedManualTemplate, goal.Title) + focusMovedNote(c, next)
 	return c.Send(message, &tele.SendOptions{ParseMode: tele.ModeMarkdown})
 }
@@ -0,0 +1,22 @@
+package bot
+
+import (
+	"context"
+	"log"
+
+	"goal-helper/internal/calibration"
+	"goal-helper/internal/models"
+)
+
+// stepLevel подбирает размер следующего шага по истории цели,
+// а для новой цели - по последним закрытым шагам всех целей пользователя
+func (b *Bot) stepLevel(ctx context.Context, userID string, goalSteps []*models.Step) calibration.Level {
+	recentSteps, err := b.repo.GetRecentFinishedSteps(ctx, userID, calibration.Window)
+	if err != nil {
+		log.Printf("❌ Ошибка при загрузке шагов для калибровки: %v", err)
+	}
+
+	level := calibration.Calibrate(goalSteps, recentSteps)
+	log.Printf("🔍 Уровень размера шага для пользователя %s: %d", userID, level)
+	return level
+}
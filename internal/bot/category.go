@@ -0,0 +1,35 @@
+package bot
+
+import (
+	"context"
+	"log"
+
+	"goal-helper/internal/models"
+)
+
+// goalCategoryIcons иконки категорий целей для списков
+var goalCategoryIcons = map[string]string{
+	models.GoalCategoryCreative:    CategoryIconCreative,
+	models.GoalCategoryEducational: CategoryIconEducational,
+	models.GoalCategoryHousehold:   CategoryIconHousehold,
+	models.GoalCategoryHealth:      CategoryIconHealth,
+	models.GoalCategoryCareer:      CategoryIconCareer,
+	models.GoalCategoryOther:       CategoryIconOther,
+}
+
+// goalCategoryIcon возвращает иконку категории цели (пусто, если категория не определена)
+func goalCategoryIcon(category string) string {
+	return goalCategoryIcons[category]
+}
+
+// classifyGoal определяет категорию цели через LLM
+// Без категории шаги генерируются по общей стратегии, поэтому ошибка только логируется
+// и цель сохраняет прежнюю категорию
+func (b *Bot) classifyGoal(ctx context.Context, goal *models.Goal) {
+	category, err := b.llmClient.ClassifyGoal(ctx, goal.Title, goal.Description)
+	if err != nil {
+		log.Printf("❌ Ошибка при классификации цели %s: %v", goal.ID, err)
+		return
+	}
+	goal.Category = category
+}
@@ -6,13 +6,13 @@ const (
 	StateWaitingGoalDescription = "waiting_goal_description"
 	StateRephrasing             = "rephrasing"
 	StateGatheringContext       = "gathering_context"
-)
-
-// Константы для статусов целей
-const (
-	GoalStatusActive    = "active"
-	GoalStatusCompleted = "completed"
-	GoalStatusInactive  = "inactive"
+	StateWaitingTimezone        = "waiting_timezone"
+	StateWaitingQuietHours      = "waiting_quiet_hours"
+	StateEditingGoalTitle       = "editing_goal_title"
+	StateEditingGoalDescription = "editing_goal_description"
+	StateWaitingStepNote        = "waiting_step_note"
+	StateEditingClarification   = "editing_clarification"
+	StateEditingMilestone       = "editing_milestone"
 )
 
 // Константы для статусов ответов LLM
@@ -24,40 +24,202 @@ const (
 	LLMStatusNeedContext       = "need_context"
 )
 
-// Константы для сообщений
+// Ключи сообщений об ошибках
+// Тексты сообщений и кнопок лежат в каталогах internal/i18n/locales
 const (
-	MsgErrorUserData           = "❌ Ошибка при получении данных пользователя"
-	MsgErrorGoal               = "❌ Ошибка при получении цели"
-	MsgErrorActiveGoal         = "❌ Ошибка при получении активной цели"
-	MsgErrorSteps              = "❌ Ошибка при получении шагов"
-	MsgErrorCreateGoal         = "❌ Ошибка при создании цели"
-	MsgErrorCreateUser         = "❌ Ошибка при создании пользователя"
-	MsgErrorUpdateGoal         = "❌ Ошибка при обновлении цели"
-	MsgErrorUpdateUser         = "❌ Ошибка при обновлении пользователя"
-	MsgErrorUpdateStep         = "❌ Ошибка при обновлении шага"
-	MsgErrorCreateStep         = "❌ Ошибка при создании шага"
-	MsgErrorGenerateStep       = "❌ Ошибка при генерации шага"
-	MsgErrorGatherContext      = "❌ Ошибка при сборе контекста"
-	MsgErrorRephraseStep       = "❌ Ошибка при переформулировке шага"
-	MsgErrorSimplifyStep       = "❌ Ошибка при упрощении шага"
-	MsgErrorUnexpectedResponse = "❌ Неожиданный ответ от системы"
+	MsgErrorUserData                    = "error_user_data"
+	MsgErrorGoal                        = "error_goal"
+	MsgErrorActiveGoal                  = "error_active_goal"
+	MsgErrorSteps                       = "error_steps"
+	MsgErrorCreateGoal                  = "error_create_goal"
+	MsgErrorCreateUser                  = "error_create_user"
+	MsgErrorUpdateGoal                  = "error_update_goal"
+	MsgErrorUpdateUser                  = "error_update_user"
+	MsgErrorUpdateStep                  = "error_update_step"
+	MsgErrorCreateStep                  = "error_create_step"
+	MsgErrorGenerateStep                = "error_generate_step"
+	MsgErrorGatherContext               = "error_gather_context"
+	MsgErrorRephraseStep                = "error_rephrase_step"
+	MsgErrorSimplifyStep                = "error_simplify_step"
+	MsgErrorUnexpectedResponse          = "error_unexpected_response"
+	MsgErrorStats                       = "error_stats"
+	MsgErrorGenerateStepDetailsTemplate = "error_generate_step_details"
 )
 
 // Константы для статусов целей в UI
 const (
-	StatusIconActive    = "🎯"
+	StatusIconActive    = "🎯" // Цель в фокусе
 	StatusIconCompleted = "✅"
-	StatusIconInactive  = "⏳"
+	StatusIconInactive  = "⏳" // Цель в работе, но не в фокусе
+	StatusIconPaused    = "⏸"
+	StatusIconAbandoned = "🚫"
+	StatusIconSkipped   = "⏭"
+)
+
+// Иконки категорий целей в UI
+const (
+	CategoryIconCreative    = "🎨"
+	CategoryIconEducational = "📚"
+	CategoryIconHousehold   = "🏠"
+	CategoryIconHealth      = "💪"
+	CategoryIconCareer      = "💼"
+	CategoryIconOther       = "📌"
+)
+
+// Ключи текстов кнопок
+const (
+	BtnTextDone     = "btn_done"
+	BtnTextSkip     = "btn_skip"
+	BtnTextRephrase = "btn_rephrase"
+	BtnTextSimpler  = "btn_simpler"
+	BtnTextComplete = "btn_complete"
+	BtnTextGoals    = "btn_goals"
+	BtnTextNewGoal  = "btn_new_goal"
+)
+
+// Ключи текстов кнопок редактирования и удаления цели
+const (
+	BtnTextEditTitle       = "btn_edit_title"
+	BtnTextEditDescription = "btn_edit_description"
+	BtnTextRetitle         = "btn_retitle"
+	BtnTextDeleteConfirm   = "btn_delete_confirm"
+	BtnTextDeleteCancel    = "btn_delete_cancel"
+)
+
+// Ключи текстов кнопок редактирования контекста цели
+const (
+	BtnTextContextEditTemplate   = "btn_context_edit"
+	BtnTextContextDeleteTemplate = "btn_context_delete"
+	BtnTextContextGather         = "btn_context_gather"
+)
+
+// Ключи текстов кнопок редактирования этапов цели
+const (
+	BtnTextMilestoneEditTemplate   = "btn_milestone_edit"
+	BtnTextMilestoneDeleteTemplate = "btn_milestone_delete"
+	BtnTextMilestoneAdd            = "btn_milestone_add"
+	BtnTextMilestoneDone           = "btn_milestone_done"
+	BtnTextMilestonesPropose       = "btn_milestones_propose"
+	BtnTextMilestonesRepropose     = "btn_milestones_repropose"
+	BtnTextMilestonesClear         = "btn_milestones_clear"
+)
+
+// Ключи текстов кнопок шагов в /today (с номером цели в списке)
+const (
+	BtnTextTodayDoneTemplate    = "btn_today_done"
+	BtnTextTodaySimplerTemplate = "btn_today_simpler"
+)
+
+// Ключи текстов кнопок выбора языка
+const (
+	BtnTextLanguageAuto = "btn_language_auto"
+)
+
+// Ключи текстов кнопок выбора персоны
+const (
+	BtnTextPersonaGoal    = "btn_persona_goal"
+	BtnTextPersonaInherit = "btn_persona_inherit"
+)
+
+// Ключи текстов кнопок отзыва о выполненном шаге
+const (
+	BtnTextTooEasy      = "btn_too_easy"
+	BtnTextRight        = "btn_right"
+	BtnTextTooHard      = "btn_too_hard"
+	BtnTextStepNoteSkip = "btn_step_note_skip"
+)
+
+// Ключи текстов кнопок настроек
+const (
+	BtnTextSettingsTimezone     = "btn_settings_timezone"
+	BtnTextSettingsQuietHours   = "btn_settings_quiet_hours"
+	BtnTextSettingsReminderTime = "btn_settings_reminder_time"
+	BtnTextSettingsDays         = "btn_settings_days"
+	BtnTextRemindersOff         = "btn_reminders_off"
+	BtnTextRemindersOn          = "btn_reminders_on"
+	BtnTextSettingsManual       = "btn_settings_manual"
+	BtnTextSettingsDisable      = "btn_settings_disable"
+	BtnTextSettingsAnyTime      = "btn_settings_any_time"
+	BtnTextSettingsEveryDay     = "btn_settings_every_day"
+	BtnTextSettingsWeekdays     = "btn_settings_weekdays"
+	BtnTextSettingsBack         = "btn_settings_back"
+	BtnTextSettingsDone         = "btn_settings_done"
+)
+
+// Константы для inline кнопок (unique идентификаторы callback)
+const (
+	CallbackSwitchGoal      = "switch_goal"
+	CallbackReminderDone    = "reminder_done"
+	CallbackReminderSimpler = "reminder_simpler"
+	CallbackTodayDone       = "today_done"
+	CallbackTodaySimpler    = "today_simpler"
+	CallbackResumeGoal      = "resume_goal"
+
+	CallbackEditGoal          = "edit_goal"
+	CallbackDeleteGoal        = "delete_goal"
+	CallbackDeleteGoalConfirm = "delete_goal_confirm"
+	CallbackDeleteGoalCancel  = "delete_goal_cancel"
+
+	CallbackContextEdit   = "context_edit"
+	CallbackContextDelete = "context_delete"
+	CallbackContextGather = "context_gather"
+
+	CallbackMilestoneEdit     = "milestone_edit"
+	CallbackMilestoneDelete   = "milestone_delete"
+	CallbackMilestoneAdd      = "milestone_add"
+	CallbackMilestoneDone     = "milestone_done"
+	CallbackMilestonesPropose = "milestones_propose"
+	CallbackMilestonesClear   = "milestones_clear"
+
+	CallbackLanguage = "language"
+
+	CallbackPersona      = "persona"
+	CallbackGoalPersona  = "goal_persona"
+	CallbackPersonaScope = "persona_scope"
+
+	CallbackStepRating   = "step_rating"
+	CallbackStepNoteSkip = "step_note_skip"
+
+	CallbackSettingsMenu         = "settings_menu"
+	CallbackSettingsTimezone     = "settings_tz"
+	CallbackSettingsQuietHours   = "settings_quiet"
+	CallbackSettingsReminderTime = "settings_time"
+	CallbackSettingsDay          = "settings_day"
+	CallbackSettingsReminders    = "settings_reminders"
 )
 
-// Константы для кнопок
+// Константы для данных inline кнопок редактирования цели
+const (
+	EditGoalFieldTitle       = "title"
+	EditGoalFieldDescription = "description"
+	EditGoalFieldRetitle     = "retitle"
+)
+
+// LanguageAuto данные inline кнопки "язык как в Telegram"
+const LanguageAuto = "auto"
+
+// Константы для данных inline кнопок выбора персоны
 const (
-	BtnTextDone     = "✅ Выполнил"
-	BtnTextRephrase = "🔄 Переформулировать"
-	BtnTextSimpler  = "🔽 Упростить"
-	BtnTextComplete = "🎉 Завершить цель"
-	BtnTextGoals    = "📋 Мои цели"
-	BtnTextNewGoal  = "➕ Новая цель"
+	PersonaScopeUser    = "user"    // Экран выбора персоны для всех целей
+	PersonaScopeGoal    = "goal"    // Экран выбора персоны для активной цели
+	PersonaInherit      = "inherit" // Персона цели как в настройках пользователя
+	PersonaSelectedIcon = "✅"
+)
+
+// Константы для данных inline кнопок настроек
+const (
+	SettingsSectionMain         = "main"
+	SettingsSectionTimezone     = "timezone"
+	SettingsSectionQuietHours   = "quiet"
+	SettingsSectionReminderTime = "time"
+	SettingsSectionDays         = "days"
+	SettingsManualInput         = "manual"
+	SettingsAnyTime             = "any"
+	SettingsDisabled            = "off"
+	SettingsDaysAll             = "all"
+	SettingsDaysWeekdays        = "weekdays"
+	SettingsDayOnIcon           = "✅"
+	SettingsDayOffIcon          = "▫️"
 )
 
 // Константы для команд
@@ -69,52 +231,224 @@ const (
 	CmdStatus   = "/status"
 	CmdStep     = "/step"
 	CmdDone     = "/done"
+	CmdSkip     = "/skip"
 	CmdNext     = "/next"
 	CmdRephrase = "/rephrase"
 	CmdSimpler  = "/simpler"
 	CmdSwitch   = "/switch"
 	CmdComplete = "/complete"
 	CmdContext  = "/context"
+	CmdCancel   = "/cancel"
+	CmdRemind   = "/remind"
+	CmdSettings = "/settings"
+	CmdStats    = "/stats"
+	CmdPause    = "/pause"
+	CmdResume   = "/resume"
+	CmdAbandon  = "/abandon"
+
+	CmdEditGoal   = "/editgoal"
+	CmdDeleteGoal = "/deletegoal"
+	CmdHistory    = "/history"
+	CmdLanguage   = "/language"
+	CmdPersona    = "/persona"
+	CmdMilestones = "/milestones"
+	CmdToday      = "/today"
 )
 
-// Константы для сообщений пользователю
-const (
-	MsgWelcomeTemplate             = "🎯 Привет, %s!\n\nЯ помогу тебе достичь целей через простые шаги.\n\nЧто хочешь сделать?"
-	MsgNoGoals                     = "📝 У тебя пока нет целей.\n\nСоздай первую цель командой /newgoal"
-	MsgNoActiveGoal                = "📝 У тебя нет активной цели.\n\nВыбери цель из списка командой /goals или создай новую командой /newgoal"
-	MsgGoalAlreadyCompleted        = "✅ Эта цель уже завершена!\n\nСоздай новую цель командой /newgoal или выбери другую из списка /goals"
-	MsgAllStepsCompleted           = "✅ Поздравляю! Ты выполнил все шаги для этой цели.\n\nИспользуй /next чтобы получить следующий шаг"
-	MsgStepCompleted               = "✅ Отлично! Шаг выполнен.\n\nИспользуй /next чтобы получить следующий шаг"
-	MsgGoalCreatedTemplate         = "🎯 Цель создана!\n\n**Название:** %s\n**Описание:** %s\n\nИспользуй /next чтобы получить первый шаг"
-	MsgGoalCompletedTemplate       = "🎉 **Поздравляю! Цель достигнута!**\n\n**%s**\n\n%s\n\nСоздай новую цель командой /newgoal"
-	MsgNearCompletionTemplate      = "🎯 **Почти готово! Осталось совсем немного:**\n\n%s\n\n💡 После этого шага цель может быть достигнута!"
-	MsgStepSimplifiedTemplate      = "🔄 Шаг упрощен:\n\n**%s**\n\n💡 Теперь этот шаг должен быть намного проще!"
-	MsgStepRephrasedTemplate       = "🔄 Шаг переформулирован:\n\n%s"
-	MsgContextQuestionTemplate     = "🔍 Для более точной помощи мне нужно узнать немного больше о тебе:\n\n**%s**\n\nОтветь на этот вопрос, и я смогу предложить подходящие шаги."
-	MsgContextThanksTemplate       = "🔍 Спасибо! Теперь еще один вопрос:\n\n**%s**"
-	MsgRephrasePrompt              = "🔄 Опиши, что именно не подходит в текущем шаге?\n\nНапример: \"Слишком сложно\", \"Непонятно что делать\", \"Нужно что-то проще\""
-	MsgHelpDefault                 = "💡 Используй команды для работы с ботом. Напиши /help для справки"
-	MsgNoGoalsForSwitch            = "📝 У тебя нет целей для переключения"
-	MsgSwitchGoalsPrompt           = "🔄 Выбери цель для переключения:\n\n"
-	MsgGoalNotFoundError           = "❌ Ошибка: не найден ID цели"
-	MsgCurrentStepError            = "❌ Ошибка при получении текущего шага"
-	MsgGoalCompletedManualTemplate = "🎉 **Поздравляю! Цель достигнута!**\n\n**%s**\n\nСоздай новую цель командой /newgoal"
-	MsgNewGoalPrompt               = "🎯 Отлично! Давай создадим новую цель.\n\nОпиши свою цель подробно - что именно ты хочешь достичь? Я сам придумаю подходящее название."
-	MsgActiveGoalTemplate          = "🎯 **Активная цель:** %s\n\n"
-	MsgGoalDescriptionTemplate     = "📝 %s\n\n"
-	MsgProgressTemplate            = "📊 **Прогресс:** %d/%d шагов выполнено\n\n"
-	MsgUseStepCommand              = "Используй /step чтобы увидеть текущий шаг"
-	MsgCurrentStepTemplate         = "📝 **Текущий шаг:**\n\n%s"
-	MsgUnfinishedStepTemplate      = "⏳ У тебя есть невыполненный шаг:\n\n**%s**\n\nСначала выполни этот шаг командой /done, а потом получи следующий."
-	MsgClarificationTemplate       = "❓ %s"
-	MsgNewStepTemplate             = "📝 **Новый шаг:**\n\n%s"
-	MsgFirstStepTemplate           = "📝 **Первый шаг:**\n\n%s"
-	MsgContextSummaryTemplate      = "📋 **Контекст для цели:** %s\n\n"
-	MsgSimplifyPrompt              = "Сделай этот шаг максимально простым - от 5 минут до максимум 1 дня. Разбей на самую простую возможную задачу."
-	MsgUserRequestedSimplification = "Пользователь запросил упрощение шага"
+// Ключи сообщений пользователю
+const (
+	MsgHelp                         = "help"
+	MsgGoalsTitle                   = "goals_title"
+	MsgWelcomeTemplate              = "welcome"
+	MsgNoGoals                      = "no_goals"
+	MsgNoActiveGoal                 = "no_active_goal"
+	MsgGoalAlreadyCompleted         = "goal_already_completed"
+	MsgAllStepsCompleted            = "all_steps_completed"
+	MsgStepCompleted                = "step_completed"
+	MsgGoalCreatedTemplate          = "goal_created"
+	MsgGoalCompletedTemplate        = "goal_completed"
+	MsgNearCompletionTemplate       = "near_completion"
+	MsgStepSimplifiedTemplate       = "step_simplified"
+	MsgStepRephrasedTemplate        = "step_rephrased"
+	MsgContextQuestionTemplate      = "context_question"
+	MsgContextThanksTemplate        = "context_thanks"
+	MsgRephrasePrompt               = "rephrase_prompt"
+	MsgHelpDefault                  = "help_default"
+	MsgGoalNotActive                = "goal_not_active"
+	MsgGoalsGroupActive             = "goals_group_active"
+	MsgGoalsGroupPaused             = "goals_group_paused"
+	MsgGoalsGroupAbandoned          = "goals_group_abandoned"
+	MsgGoalsGroupCompleted          = "goals_group_completed"
+	MsgGoalStatusReasonTemplate     = "goal_status_reason"
+	MsgGoalPausedTemplate           = "goal_paused"
+	MsgGoalAbandonedTemplate        = "goal_abandoned"
+	MsgGoalResumedTemplate          = "goal_resumed"
+	MsgGoalStatusChangeNotAllowed   = "goal_status_change_not_allowed"
+	MsgNoGoalsToResume              = "no_goals_to_resume"
+	MsgResumeGoalsPrompt            = "resume_goals_prompt"
+	MsgEditGoalTemplate             = "edit_goal"
+	MsgEditGoalTitlePrompt          = "edit_goal_title_prompt"
+	MsgEditGoalDescriptionPrompt    = "edit_goal_description_prompt"
+	MsgEditGoalTitleTooLong         = "edit_goal_title_too_long"
+	MsgEditGoalEmpty                = "edit_goal_empty"
+	MsgGoalUpdated                  = "goal_updated"
+	MsgEditGoalRetitling            = "edit_goal_retitling"
+	MsgDeleteGoalsPrompt            = "delete_goals_prompt"
+	MsgDeleteGoalConfirmTemplate    = "delete_goal_confirm"
+	MsgGoalDeletedTemplate          = "goal_deleted"
+	MsgDeleteGoalCancelled          = "delete_goal_cancelled"
+	MsgErrorDeleteGoal              = "error_delete_goal"
+	MsgHistoryEmpty                 = "history_empty"
+	MsgHistoryTitleTemplate         = "history_title"
+	MsgHistoryStepTemplate          = "history_step"
+	MsgHistoryRevisionTemplate      = "history_revision"
+	MsgHistoryRephrasedTemplate     = "history_rephrased"
+	MsgHistorySimplified            = "history_simplified"
+	MsgStepSkipped                  = "step_skipped"
+	MsgSkippedStepsTemplate         = "skipped_steps"
+	MsgStepRatingPrompt             = "step_rating_prompt"
+	MsgStepRatingSaved              = "step_rating_saved"
+	MsgStepNotePrompt               = "step_note_prompt"
+	MsgStepNoteSaved                = "step_note_saved"
+	MsgUseNextCommand               = "use_next_command"
+	MsgNoGoalsForSwitch             = "no_goals_for_switch"
+	MsgSwitchGoalsPrompt            = "switch_goals_prompt"
+	MsgSwitchGoalsHint              = "switch_goals_hint"
+	MsgGoalSwitchedTemplate         = "goal_switched"
+	MsgGoalNotFoundError            = "goal_not_found_error"
+	MsgCurrentStepError             = "current_step_error"
+	MsgGoalCompletedManualTemplate  = "goal_completed_manual"
+	MsgNewGoalPrompt                = "new_goal_prompt"
+	MsgActiveGoalTemplate           = "active_goal"
+	MsgGoalDescriptionTemplate      = "goal_description"
+	MsgProgressTemplate             = "progress"
+	MsgUseStepCommand               = "use_step_command"
+	MsgCurrentStepTemplate          = "current_step"
+	MsgUnfinishedStepTemplate       = "unfinished_step"
+	MsgClarificationTemplate        = "clarification"
+	MsgNewStepTemplate              = "new_step"
+	MsgFirstStepTemplate            = "first_step"
+	MsgContextSummaryTemplate       = "context_summary"
+	MsgContextNotCollected          = "context_not_collected"
+	MsgContextCollectedTemplate     = "context_collected"
+	MsgContextClarificationTemplate = "context_clarification"
+	MsgContextAnswerTemplate        = "context_answer"
+	MsgContextHint                  = "context_hint"
+	MsgClarificationEditTemplate    = "clarification_edit"
+	MsgClarificationUpdated         = "clarification_updated"
+	MsgClarificationDeleted         = "clarification_deleted"
+	MsgClarificationNotFound        = "clarification_not_found"
+	MsgContextComplete              = "context_complete"
+	MsgContextUpdated               = "context_updated"
+	MsgSimplifyPrompt               = "simplify_prompt"
+	MsgUserRequestedSimplification  = "user_requested_simplification"
+	MsgCancelled                    = "cancelled"
+	MsgReminderTemplate             = "reminder"
+	MsgReminderStale                = "reminder_stale"
+	MsgRemindDisabled               = "remind_disabled"
+	MsgRemindEnabledTemplate        = "remind_enabled"
+	MsgRemindTimeTemplate           = "remind_time"
+	MsgRemindNextTemplate           = "remind_next"
+	MsgRemindSaved                  = "remind_saved"
+	MsgRemindInvalid                = "remind_invalid"
+	MsgSettingsTemplate             = "settings"
+	MsgSettingsServerTimezone       = "settings_server_timezone"
+	MsgSettingsOff                  = "settings_off"
+	MsgSettingsEveryDay             = "settings_every_day"
+	MsgSettingsRemindersTemplate    = "settings_reminders"
+	MsgSettingsSaved                = "settings_saved"
+	MsgSettingsTimezonePrompt       = "settings_timezone_prompt"
+	MsgSettingsQuietHoursPrompt     = "settings_quiet_hours_prompt"
+	MsgSettingsInvalidTimezone      = "settings_invalid_timezone"
+	MsgSettingsInvalidQuietHours    = "settings_invalid_quiet_hours"
+	MsgSettingsNoDays               = "settings_no_days"
+	MsgStatsTitle                   = "stats_title"
+	MsgStatsStreakTemplate          = "stats_streak"
+	MsgStatsStepsTemplate           = "stats_steps"
+	MsgStatsWeeksTemplate           = "stats_weeks"
+	MsgStatsAverageTemplate         = "stats_average"
+	MsgStatsGoalsTemplate           = "stats_goals"
+	MsgStatsByGoal                  = "stats_by_goal"
+	MsgStatsGoalTemplate            = "stats_goal"
+	MsgDurationDaysTemplate         = "duration_days"
+	MsgDurationHoursTemplate        = "duration_hours"
+	MsgDurationMinutesTemplate      = "duration_minutes"
+	MsgWeekdayMonday                = "weekday_monday"
+	MsgWeekdayTuesday               = "weekday_tuesday"
+	MsgWeekdayWednesday             = "weekday_wednesday"
+	MsgWeekdayThursday              = "weekday_thursday"
+	MsgWeekdayFriday                = "weekday_friday"
+	MsgWeekdaySaturday              = "weekday_saturday"
+	MsgWeekdaySunday                = "weekday_sunday"
+	MsgLanguageName                 = "language_name"
+	MsgLanguageTemplate             = "language"
+	MsgLanguageAutoTemplate         = "language_auto"
+	MsgLanguageSaved                = "language_saved"
+	MsgPersonaTemplate              = "persona"
+	MsgPersonaGoalTemplate          = "persona_goal"
+	MsgPersonaInheritedTemplate     = "persona_inherited"
+	MsgPersonaSaved                 = "persona_saved"
+	MsgRemindUsage                  = "remind_usage"
+	MsgMilestonesSummaryTemplate    = "milestones_summary"
+	MsgMilestonesNone               = "milestones_none"
+	MsgMilestoneDoneTemplate        = "milestone_done"
+	MsgMilestoneCurrentTemplate     = "milestone_current"
+	MsgMilestoneUpcomingTemplate    = "milestone_upcoming"
+	MsgMilestonesHint               = "milestones_hint"
+	MsgMilestoneEditTemplate        = "milestone_edit"
+	MsgMilestoneAddPrompt           = "milestone_add"
+	MsgMilestoneAdded               = "milestone_added"
+	MsgMilestoneUpdated             = "milestone_updated"
+	MsgMilestoneDeleted             = "milestone_deleted"
+	MsgMilestoneNotFound            = "milestone_not_found"
+	MsgMilestoneDoneToastTemplate   = "milestone_done_toast"
+	MsgMilestonesCleared            = "milestones_cleared"
+	MsgMilestoneCompletedTemplate   = "milestone_completed"
+	MsgMilestoneNextTemplate        = "milestone_next"
+	MsgStatusMilestoneTemplate      = "status_milestone"
+	MsgStatusMilestonesDoneTemplate = "status_milestones_done"
+	MsgErrorProposeMilestones       = "error_propose_milestones"
+	MsgTodayTitle                   = "today_title"
+	MsgTodayNoGoals                 = "today_no_goals"
+	MsgTodayGoalTemplate            = "today_goal"
+	MsgTodayStepTemplate            = "today_step"
+	MsgTodayNoStep                  = "today_no_step"
+	MsgTodayHint                    = "today_hint"
+	MsgTodayStale                   = "today_stale"
+	MsgFocusMovedTemplate           = "focus_moved"
 )
 
 // Константы для настройки бота
 const (
 	BotPollerTimeout = 10
+	BotStateTTLHours = 24 // Через сколько часов незавершенный диалог сбрасывается
+
+	GoalTitleMaxLength = 100 // Максимальная длина названия цели, заданного вручную
+
+	HistoryMaxSteps   = 10 // Сколько последних переформулированных шагов показывать в /history
+	HistoryDateLayout = "02.01 15:04"
+
+	// Максимальное время обработки одного апдейта (с учетом повторов и резервных LLM)
+	BotRequestTimeoutSeconds = 180
+
+	// Сколько ждать обработчики после отмены их запросов при остановке бота
+	BotShutdownGraceSeconds = 5
+)
+
+// Константы для режима webhook
+const (
+	WebhookHealthPath         = "/healthz" // Проверка живости для балансировщика
+	WebhookReadTimeoutSeconds = 10
+	WebhookShutdownSeconds    = 5
+)
+
+// Константы для напоминаний
+const (
+	ReminderDefaultIdleHours     = 24     // Через сколько часов бездействия напоминать по умолчанию
+	ReminderMaxIdleHours         = 24 * 7 // Максимальная задержка, которую можно задать через /remind
+	ReminderMaxNudges            = 3      // Сколько раз напоминать об одном шаге
+	ReminderCheckIntervalSeconds = 60     // Как часто планировщик проверяет наступившие напоминания
+	ReminderRetryMinutes         = 10     // Через сколько повторить неудачную отправку
+	ReminderMaxReschedules       = 16     // Сколько раз можно перенести напоминание из-за тихих часов и дней недели
+	ReminderDateLayout           = "02.01 15:04"
 )
@@ -0,0 +1,229 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"goal-helper/internal/i18n"
+	"goal-helper/internal/models"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// handleContext обрабатывает команду /context: показывает собранный контекст активной цели
+func (b *Bot) handleContext(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorUserData))
+	}
+
+	if user.ActiveGoalID == "" {
+		return c.Send(tr(c, MsgNoActiveGoal))
+	}
+
+	goal, err := b.repo.GetGoal(ctx, user.ActiveGoalID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorGoal))
+	}
+
+	return c.Send(contextSummary(localizer(c), goal), contextMarkup(localizer(c), goal))
+}
+
+// contextSummary описывает контекст цели (без Markdown - в тексте ответы пользователя)
+func contextSummary(loc *i18n.Localizer, goal *models.Goal) string {
+	var message strings.Builder
+	message.WriteString(loc.T(MsgContextSummaryTemplate, goal.Title))
+
+	if len(goal.Context.Clarifications) == 0 {
+		message.WriteString(loc.T(MsgContextNotCollected))
+		return message.String()
+	}
+
+	message.WriteString(loc.T(MsgContextCollectedTemplate, len(goal.Context.Clarifications)))
+	for i, clarification := range goal.Context.Clarifications {
+		if clarification.Question != "" {
+			message.WriteString(loc.T(MsgContextClarificationTemplate, i+1, clarification.Question, clarification.Answer))
+		} else {
+			message.WriteString(loc.T(MsgContextAnswerTemplate, i+1, clarification.Answer))
+		}
+	}
+	message.WriteString(loc.T(MsgContextHint))
+
+	return message.String()
+}
+
+// contextMarkup возвращает inline кнопки редактирования контекста цели
+func contextMarkup(loc *i18n.Localizer, goal *models.Goal) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0, len(goal.Context.Clarifications)+1)
+	for i := range goal.Context.Clarifications {
+		index := strconv.Itoa(i)
+		rows = append(rows, markup.Row(
+			markup.Data(loc.T(BtnTextContextEditTemplate, i+1), CallbackContextEdit, index, goal.ID),
+			markup.Data(loc.T(BtnTextContextDeleteTemplate, i+1), CallbackContextDelete, index, goal.ID),
+		))
+	}
+	rows = append(rows, markup.Row(markup.Data(loc.T(BtnTextContextGather), CallbackContextGather, goal.ID)))
+	markup.Inline(rows...)
+	return markup
+}
+
+// clarificationFromCallback находит цель и номер уточнения по данным inline кнопки (номер|ID цели)
+func (b *Bot) clarificationFromCallback(c tele.Context) (*models.Goal, int, error) {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	args := c.Args()
+	if len(args) != 2 {
+		return nil, 0, fmt.Errorf("unexpected callback data: %q", c.Callback().Data)
+	}
+
+	index, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid clarification index: %w", err)
+	}
+
+	goal, err := b.repo.GetGoal(ctx, args[1])
+	if err != nil {
+		return nil, 0, err
+	}
+	if goal.UserID != userID {
+		return nil, 0, fmt.Errorf("goal %s belongs to another user", goal.ID)
+	}
+
+	// Список мог измениться с тех пор, как были показаны кнопки
+	if index < 0 || index >= len(goal.Context.Clarifications) {
+		return nil, 0, fmt.Errorf("goal %s has no clarification %d", goal.ID, index)
+	}
+
+	return goal, index, nil
+}
+
+// handleContextEdit просит новый ответ на уточнение
+func (b *Bot) handleContextEdit(c tele.Context) error {
+	goal, index, err := b.clarificationFromCallback(c)
+	if err != nil {
+		log.Printf("❌ Ошибка при выборе уточнения: %v", err)
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgClarificationNotFound), ShowAlert: true})
+	}
+
+	state := b.getOrCreateState(c.Sender().ID)
+	state.State = StateEditingClarification
+	state.TempData = map[string]string{
+		"goal_id":             goal.ID,
+		"clarification_index": strconv.Itoa(index),
+	}
+	b.saveState(state)
+
+	if err := c.Respond(); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+
+	// У перенесенных из старого формата уточнений вопроса может не быть
+	clarification := goal.Context.Clarifications[index]
+	question := clarification.Question
+	if question == "" {
+		question = clarification.Answer
+	}
+	return c.Send(tr(c, MsgClarificationEditTemplate, question))
+}
+
+// handleContextDelete удаляет уточнение и обновляет список
+func (b *Bot) handleContextDelete(c tele.Context) error {
+	ctx := requestContext(c)
+
+	goal, index, err := b.clarificationFromCallback(c)
+	if err != nil {
+		log.Printf("❌ Ошибка при выборе уточнения: %v", err)
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgClarificationNotFound), ShowAlert: true})
+	}
+
+	if err := goal.RemoveClarification(index); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgClarificationNotFound), ShowAlert: true})
+	}
+	if err := b.repo.UpdateGoal(ctx, goal); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUpdateGoal)})
+	}
+
+	if err := c.Respond(&tele.CallbackResponse{Text: tr(c, MsgClarificationDeleted)}); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+	return c.Edit(contextSummary(localizer(c), goal), contextMarkup(localizer(c), goal))
+}
+
+// handleContextGather заново запускает сбор контекста для цели
+// Ответы на новые вопросы обрабатываются в состоянии StateGatheringContext
+func (b *Bot) handleContextGather(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	goal, err := b.repo.GetGoal(ctx, c.Callback().Data)
+	if err != nil || goal.UserID != userID {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorGoal)})
+	}
+
+	if err := c.Respond(); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+
+	contextResponse, err := b.llmClient.GatherContext(ctx, goal)
+	if err != nil {
+		log.Printf("❌ Ошибка при сборе контекста: %v", err)
+		return c.Send(tr(c, MsgErrorGatherContext))
+	}
+
+	if contextResponse.Status != LLMStatusNeedContext {
+		return c.Send(tr(c, MsgContextComplete))
+	}
+
+	state := b.getOrCreateState(c.Sender().ID)
+	state.State = StateGatheringContext
+	state.TempData = map[string]string{
+		"goal_id":          goal.ID,
+		"context_question": contextResponse.Question,
+		"context_refresh":  "1",
+	}
+	b.saveState(state)
+
+	message := tr(c, MsgContextQuestionTemplate, contextResponse.Question)
+	return c.Send(message, &tele.SendOptions{ParseMode: tele.ModeMarkdown})
+}
+
+// handleClarificationInput сохраняет новый ответ на уточнение
+func (b *Bot) handleClarificationInput(c tele.Context, state *models.UserState) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+	text := strings.TrimSpace(c.Text())
+
+	if text == "" {
+		return c.Send(tr(c, MsgEditGoalEmpty))
+	}
+
+	goal, err := b.repo.GetGoal(ctx, state.TempData["goal_id"])
+	if err != nil || goal.UserID != userID {
+		return c.Send(tr(c, MsgGoalNotFoundError))
+	}
+
+	index, err := strconv.Atoi(state.TempData["clarification_index"])
+	if err != nil {
+		return c.Send(tr(c, MsgClarificationNotFound))
+	}
+
+	if err := goal.UpdateClarification(index, text); err != nil {
+		return c.Send(tr(c, MsgClarificationNotFound))
+	}
+	if err := b.repo.UpdateGoal(ctx, goal); err != nil {
+		return c.Send(tr(c, MsgErrorUpdateGoal))
+	}
+
+	state.State = StateIdle
+	state.TempData = make(map[string]string)
+	b.saveState(state)
+
+	return c.Send(tr(c, MsgClarificationUpdated)+"\n\n"+contextSummary(localizer(c), goal), contextMarkup(localizer(c), goal))
+}
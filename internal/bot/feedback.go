@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"goal-helper/internal/i18n"
+	"goal-helper/internal/models"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// stepRatingMarkup возвращает inline кнопки оценки сложности выполненного шага
+func stepRatingMarkup(loc *i18n.Localizer, stepID string) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(
+		markup.Row(
+			markup.Data(loc.T(BtnTextTooEasy), CallbackStepRating, models.StepDifficultyTooEasy, stepID),
+			markup.Data(loc.T(BtnTextRight), CallbackStepRating, models.StepDifficultyRight, stepID),
+			markup.Data(loc.T(BtnTextTooHard), CallbackStepRating, models.StepDifficultyTooHard, stepID),
+		),
+		markup.Row(markup.Data(loc.T(BtnTextStepNoteSkip), CallbackStepNoteSkip)),
+	)
+	return markup
+}
+
+// handleStepRating сохраняет оценку сложности шага (данные: оценка|ID шага)
+// и предлагает написать, как прошло
+func (b *Bot) handleStepRating(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	args := c.Args()
+	if len(args) != 2 {
+		return c.Respond()
+	}
+	difficulty, stepID := args[0], args[1]
+
+	step, err := b.repo.GetStep(ctx, stepID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgCurrentStepError)})
+	}
+
+	goal, err := b.repo.GetGoal(ctx, step.GoalID)
+	if err != nil || goal.UserID != userID {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorGoal)})
+	}
+
+	if err := step.Rate(difficulty); err != nil {
+		return c.Respond()
+	}
+	if err := b.repo.UpdateStep(ctx, step); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUpdateStep)})
+	}
+
+	if err := c.Respond(&tele.CallbackResponse{Text: tr(c, MsgStepRatingSaved)}); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+	if _, err := b.bot.EditReplyMarkup(c.Message(), nil); err != nil {
+		log.Printf("❌ Ошибка при удалении кнопок оценки: %v", err)
+	}
+
+	// Ждем заметку о шаге; её можно не писать
+	state := b.getOrCreateState(c.Sender().ID)
+	state.State = StateWaitingStepNote
+	state.TempData = map[string]string{"step_id": step.ID}
+	b.saveState(state)
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(markup.Data(tr(c, BtnTextStepNoteSkip), CallbackStepNoteSkip)))
+	return c.Send(tr(c, MsgStepNotePrompt), markup)
+}
+
+// handleStepNoteSkip закрывает вопрос об отзыве без заметки
+func (b *Bot) handleStepNoteSkip(c tele.Context) error {
+	state := b.getOrCreateState(c.Sender().ID)
+	if state.State == StateWaitingStepNote {
+		state.State = StateIdle
+		state.TempData = make(map[string]string)
+		b.saveState(state)
+	}
+
+	if err := c.Respond(); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+	if _, err := b.bot.EditReplyMarkup(c.Message(), nil); err != nil {
+		log.Printf("❌ Ошибка при удалении кнопок отзыва: %v", err)
+	}
+	return c.Send(tr(c, MsgUseNextCommand))
+}
+
+// handleStepNoteInput сохраняет заметку пользователя о выполненном шаге
+func (b *Bot) handleStepNoteInput(c tele.Context, state *models.UserState) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+	text := strings.TrimSpace(c.Text())
+
+	step, err := b.repo.GetStep(ctx, state.TempData["step_id"])
+	if err != nil {
+		return c.Send(tr(c, MsgCurrentStepError))
+	}
+
+	goal, err := b.repo.GetGoal(ctx, step.GoalID)
+	if err != nil || goal.UserID != userID {
+		return c.Send(tr(c, MsgErrorGoal))
+	}
+
+	step.CompletionNote = text
+	if err := b.repo.UpdateStep(ctx, step); err != nil {
+		return c.Send(tr(c, MsgErrorUpdateStep))
+	}
+
+	state.State = StateIdle
+	state.TempData = make(map[string]string)
+	b.saveState(state)
+
+	return c.Send(tr(c, MsgStepNoteSaved))
+}
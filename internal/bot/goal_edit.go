@@ -0,0 +1,259 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"goal-helper/internal/i18n"
+	"goal-helper/internal/models"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// handleEditGoal обрабатывает команду /editgoal: показывает активную цель и что в ней можно изменить
+func (b *Bot) handleEditGoal(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorUserData))
+	}
+
+	if user.ActiveGoalID == "" {
+		return c.Send(tr(c, MsgNoActiveGoal))
+	}
+
+	goal, err := b.repo.GetGoal(ctx, user.ActiveGoalID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorGoal))
+	}
+
+	return c.Send(editGoalSummary(localizer(c), goal), editGoalMarkup(localizer(c), goal.ID))
+}
+
+// handleEditGoalField обрабатывает inline кнопки /editgoal (данные: поле|ID цели)
+func (b *Bot) handleEditGoalField(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	args := c.Args()
+	if len(args) != 2 {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgGoalNotFoundError)})
+	}
+	field, goalID := args[0], args[1]
+
+	goal, err := b.repo.GetGoal(ctx, goalID)
+	if err != nil || goal.UserID != userID {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorGoal)})
+	}
+
+	switch field {
+	case EditGoalFieldTitle:
+		return b.askGoalEditInput(c, goal.ID, StateEditingGoalTitle, tr(c, MsgEditGoalTitlePrompt))
+	case EditGoalFieldDescription:
+		return b.askGoalEditInput(c, goal.ID, StateEditingGoalDescription, tr(c, MsgEditGoalDescriptionPrompt))
+	case EditGoalFieldRetitle:
+		if err := c.Respond(&tele.CallbackResponse{Text: tr(c, MsgEditGoalRetitling)}); err != nil {
+			log.Printf("❌ Ошибка при ответе на callback: %v", err)
+		}
+
+		title, err := b.llmClient.GenerateGoalTitle(ctx, goal.Description)
+		if err != nil {
+			return c.Send(tr(c, MsgErrorGenerateStep))
+		}
+
+		goal.Title = title
+		if err := b.repo.UpdateGoal(ctx, goal); err != nil {
+			return c.Send(tr(c, MsgErrorUpdateGoal))
+		}
+		return c.Edit(editGoalSummary(localizer(c), goal), editGoalMarkup(localizer(c), goal.ID))
+	default:
+		return c.Respond()
+	}
+}
+
+// askGoalEditInput переводит пользователя в ожидание нового текста для цели
+func (b *Bot) askGoalEditInput(c tele.Context, goalID, stateName, prompt string) error {
+	state := b.getOrCreateState(c.Sender().ID)
+	state.State = stateName
+	state.TempData = map[string]string{"goal_id": goalID}
+	b.saveState(state)
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+	return c.Send(prompt)
+}
+
+// handleGoalEditInput обрабатывает новое название или описание цели
+func (b *Bot) handleGoalEditInput(c tele.Context, state *models.UserState) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+	text := strings.TrimSpace(c.Text())
+
+	goal, err := b.repo.GetGoal(ctx, state.TempData["goal_id"])
+	if err != nil || goal.UserID != userID {
+		return c.Send(tr(c, MsgGoalNotFoundError))
+	}
+
+	if text == "" {
+		return c.Send(tr(c, MsgEditGoalEmpty))
+	}
+
+	switch state.State {
+	case StateEditingGoalTitle:
+		if utf8.RuneCountInString(text) > GoalTitleMaxLength {
+			return c.Send(tr(c, MsgEditGoalTitleTooLong, GoalTitleMaxLength))
+		}
+		goal.Title = text
+	case StateEditingGoalDescription:
+		goal.Description = text
+		// Новое описание может поменять категорию цели, а с ней и стратегию шагов
+		b.classifyGoal(ctx, goal)
+	default:
+		return c.Send(tr(c, MsgHelpDefault))
+	}
+
+	if err := b.repo.UpdateGoal(ctx, goal); err != nil {
+		return c.Send(tr(c, MsgErrorUpdateGoal))
+	}
+
+	state.State = StateIdle
+	state.TempData = make(map[string]string)
+	b.saveState(state)
+
+	return c.Send(tr(c, MsgGoalUpdated)+"\n\n"+editGoalSummary(localizer(c), goal), editGoalMarkup(localizer(c), goal.ID))
+}
+
+// editGoalSummary описывает цель для экрана редактирования (без Markdown - текст вводит пользователь)
+func editGoalSummary(loc *i18n.Localizer, goal *models.Goal) string {
+	return loc.T(MsgEditGoalTemplate, goal.Title, goal.Description)
+}
+
+// editGoalMarkup возвращает inline кнопки редактирования цели
+func editGoalMarkup(loc *i18n.Localizer, goalID string) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(
+		markup.Row(
+			markup.Data(loc.T(BtnTextEditTitle), CallbackEditGoal, EditGoalFieldTitle, goalID),
+			markup.Data(loc.T(BtnTextEditDescription), CallbackEditGoal, EditGoalFieldDescription, goalID),
+		),
+		markup.Row(markup.Data(loc.T(BtnTextRetitle), CallbackEditGoal, EditGoalFieldRetitle, goalID)),
+	)
+	return markup
+}
+
+// handleDeleteGoal обрабатывает команду /deletegoal: предлагает выбрать цель для удаления
+func (b *Bot) handleDeleteGoal(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	goals, err := b.repo.GetUserGoals(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorSteps))
+	}
+
+	if len(goals) == 0 {
+		return c.Send(tr(c, MsgNoGoals))
+	}
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorUserData))
+	}
+
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0, len(goals))
+	for _, goal := range goals {
+		text := fmt.Sprintf("%s %s", goalStatusIcon(goal, user.ActiveGoalID), goal.Title)
+		rows = append(rows, markup.Row(markup.Data(text, CallbackDeleteGoal, goal.ID)))
+	}
+	markup.Inline(rows...)
+
+	return c.Send(tr(c, MsgDeleteGoalsPrompt), markup)
+}
+
+// handleDeleteGoalSelect обрабатывает выбор цели для удаления и просит подтверждение
+func (b *Bot) handleDeleteGoalSelect(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+	goalID := c.Callback().Data
+
+	goal, err := b.repo.GetGoal(ctx, goalID)
+	if err != nil || goal.UserID != userID {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorGoal)})
+	}
+
+	steps, err := b.repo.GetGoalSteps(ctx, goal.ID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorSteps)})
+	}
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(
+		markup.Data(tr(c, BtnTextDeleteConfirm), CallbackDeleteGoalConfirm, goal.ID),
+		markup.Data(tr(c, BtnTextDeleteCancel), CallbackDeleteGoalCancel),
+	))
+
+	if err := c.Respond(); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+	return c.Edit(tr(c, MsgDeleteGoalConfirmTemplate, goal.Title, len(steps)), markup)
+}
+
+// handleDeleteGoalConfirm удаляет цель после подтверждения
+// Если цель была в фокусе, фокус переходит на другую цель в работе
+func (b *Bot) handleDeleteGoalConfirm(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+	goalID := c.Callback().Data
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUserData)})
+	}
+
+	goal, err := b.repo.GetGoal(ctx, goalID)
+	if err != nil || goal.UserID != userID {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorGoal)})
+	}
+
+	if err := b.repo.DeleteGoal(ctx, goal.ID); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorDeleteGoal)})
+	}
+	rescheduleReminder(c)
+
+	// Фокус переводим только после удаления: если удалить не удалось, фокус остается на цели
+	var next *models.Goal
+	if user.ActiveGoalID == goal.ID {
+		if next, err = b.refocus(ctx, user, goal.ID); err != nil {
+			log.Printf("❌ Ошибка при смене цели в фокусе: %v", err)
+		}
+	}
+
+	// Незавершенный диалог об удаленной цели больше не нужен
+	state := b.getOrCreateState(c.Sender().ID)
+	if state.TempData["goal_id"] == goal.ID {
+		state.State = StateIdle
+		state.TempData = make(map[string]string)
+		b.saveState(state)
+	}
+
+	message := tr(c, MsgGoalDeletedTemplate, goal.Title)
+	if err := c.Respond(&tele.CallbackResponse{Text: message}); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+	return c.Edit(message + focusMovedNote(c, next))
+}
+
+// handleDeleteGoalCancel отменяет удаление цели
+func (b *Bot) handleDeleteGoalCancel(c tele.Context) error {
+	if err := c.Respond(); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+	return c.Edit(tr(c, MsgDeleteGoalCancelled))
+}
@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"goal-helper/internal/i18n"
+	"goal-helper/internal/models"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// handleHistory обрабатывает команду /history: показывает прежние формулировки шагов активной цели
+func (b *Bot) handleHistory(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorUserData))
+	}
+
+	if user.ActiveGoalID == "" {
+		return c.Send(tr(c, MsgNoActiveGoal))
+	}
+
+	goal, err := b.repo.GetGoal(ctx, user.ActiveGoalID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorGoal))
+	}
+
+	steps, err := b.repo.GetGoalSteps(ctx, goal.ID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorSteps))
+	}
+
+	var revised []*models.Step
+	for _, step := range steps {
+		if len(step.History) > 0 {
+			revised = append(revised, step)
+		}
+	}
+
+	if len(revised) == 0 {
+		return c.Send(tr(c, MsgHistoryEmpty))
+	}
+
+	// Длинная история не влезет в одно сообщение - показываем последние шаги
+	if len(revised) > HistoryMaxSteps {
+		revised = revised[len(revised)-HistoryMaxSteps:]
+	}
+
+	return c.Send(formatStepHistory(localizer(c), goal, revised, user.Settings.Location()))
+}
+
+// formatStepHistory описывает историю формулировок шагов (без Markdown - в тексте комментарии пользователя)
+func formatStepHistory(loc *i18n.Localizer, goal *models.Goal, steps []*models.Step, location *time.Location) string {
+	var message strings.Builder
+	message.WriteString(loc.T(MsgHistoryTitleTemplate, goal.Title))
+
+	for _, step := range steps {
+		icon := StatusIconActive
+		if step.IsCompleted() {
+			icon = StatusIconCompleted
+		} else if step.IsSkipped() {
+			icon = StatusIconSkipped
+		}
+		message.WriteString(loc.T(MsgHistoryStepTemplate, icon, step.Text))
+
+		for i, revision := range step.History {
+			changedAt := revision.ChangedAt.In(location).Format(HistoryDateLayout)
+			message.WriteString(loc.T(MsgHistoryRevisionTemplate, i+1, revision.Text, revisionReason(loc, revision), changedAt))
+		}
+		message.WriteString("\n")
+	}
+
+	return message.String()
+}
+
+// revisionReason описывает, почему формулировка была заменена
+func revisionReason(loc *i18n.Localizer, revision models.StepRevision) string {
+	if revision.Kind == models.StepChangeSimplify {
+		return loc.T(MsgHistorySimplified)
+	}
+	return loc.T(MsgHistoryRephrasedTemplate, revision.Comment)
+}
@@ -0,0 +1,149 @@
+package bot
+
+import (
+	"log"
+	"strconv"
+
+	"goal-helper/internal/i18n"
+	"goal-helper/internal/models"
+	"goal-helper/internal/persona"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// localizerKey ключ, под которым переводчик текущего апдейта хранится в tele.Context
+const localizerKey = "localizer"
+
+// localize middleware, которое определяет язык и персону пользователя и кладет переводчик в tele.Context,
+// а язык и персону - в контекст запроса, чтобы LLM отвечала на том же языке и в том же тоне.
+// Должно стоять после serializeUpdates: может один раз сохранить язык клиента пользователю без языка
+func (b *Bot) localize(next tele.HandlerFunc) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		if c.Sender() == nil {
+			return next(c)
+		}
+
+		ctx := requestContext(c)
+		clientCode := c.Sender().LanguageCode
+		language := i18n.Resolve("", clientCode)
+		personaID := persona.DefaultPersona
+
+		userID := strconv.FormatInt(c.Sender().ID, 10)
+		if user, err := b.repo.GetUser(ctx, userID); err == nil {
+			// Язык клиента запоминаем, только если его еще нет (пользователь создан до появления языков),
+			// чтобы не писать в хранилище на каждом апдейте. Обновляется он в /start
+			if user.LanguageCode == "" && rememberClientLanguage(user, clientCode) {
+				if err := b.repo.UpdateUser(ctx, user); err != nil {
+					log.Printf("❌ Ошибка при сохранении языка пользователя %s: %v", userID, err)
+				}
+			}
+			language = userLanguage(user)
+			personaID = userPersona(user)
+		}
+
+		c.Set(localizerKey, personaLocalizer(language, personaID))
+		c.Set(requestContextKey, persona.WithPersona(i18n.WithLanguage(ctx, language), personaID))
+		return next(c)
+	}
+}
+
+// rememberClientLanguage запоминает язык клиента Telegram, чтобы напоминания приходили на нем же.
+// Если пользователь выбрал язык в /language, язык клиента не трогаем.
+// Возвращает true, если пользователя нужно сохранить
+func rememberClientLanguage(user *models.User, clientCode string) bool {
+	if clientCode == "" || user.Settings.Language != "" || user.LanguageCode == clientCode {
+		return false
+	}
+	user.LanguageCode = clientCode
+	return true
+}
+
+// userLanguage возвращает язык пользователя: выбранный в /language или язык клиента Telegram
+func userLanguage(user *models.User) string {
+	return i18n.Resolve(user.Settings.Language, user.LanguageCode)
+}
+
+// localizer возвращает переводчик текущего апдейта
+func localizer(c tele.Context) *i18n.Localizer {
+	if loc, ok := c.Get(localizerKey).(*i18n.Localizer); ok {
+		return loc
+	}
+	if c.Sender() != nil {
+		return i18n.New(i18n.Resolve("", c.Sender().LanguageCode))
+	}
+	return i18n.New(i18n.DefaultLanguage)
+}
+
+// tr переводит сообщение на язык пользователя и подставляет аргументы
+func tr(c tele.Context, key string, args ...any) string {
+	return localizer(c).T(key, args...)
+}
+
+// handleLanguage обрабатывает команду /language: показывает текущий язык и варианты выбора
+func (b *Bot) handleLanguage(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorUserData))
+	}
+
+	return c.Send(languageSummary(localizer(c), user), languageMarkup(localizer(c)))
+}
+
+// languageSummary описывает текущий язык пользователя
+func languageSummary(loc *i18n.Localizer, user *models.User) string {
+	current := loc.T(MsgLanguageName)
+	if user.Settings.Language == "" {
+		current = loc.T(MsgLanguageAutoTemplate, current)
+	}
+	return loc.T(MsgLanguageTemplate, current)
+}
+
+// languageMarkup возвращает inline кнопки выбора языка
+// Названия языков пишутся на самих языках, чтобы их можно было найти в любом интерфейсе
+func languageMarkup(loc *i18n.Localizer) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	var languages []tele.Btn
+	for _, language := range i18n.Languages() {
+		languages = append(languages, markup.Data(i18n.New(language).T(MsgLanguageName), CallbackLanguage, language))
+	}
+	markup.Inline(
+		markup.Row(languages...),
+		markup.Row(markup.Data(loc.T(BtnTextLanguageAuto), CallbackLanguage, LanguageAuto)),
+	)
+	return markup
+}
+
+// handleLanguageSelect сохраняет выбранный язык
+func (b *Bot) handleLanguageSelect(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUserData)})
+	}
+
+	if choice := c.Callback().Data; choice == LanguageAuto {
+		user.Settings.Language = ""
+	} else if language, ok := i18n.Match(choice); ok {
+		user.Settings.Language = language
+	} else {
+		return c.Respond()
+	}
+
+	if err := b.repo.UpdateUser(ctx, user); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUpdateUser)})
+	}
+
+	// Дальше отвечаем уже на новом языке
+	loc := userLocalizer(user)
+	c.Set(localizerKey, loc)
+
+	if err := c.Respond(&tele.CallbackResponse{Text: loc.T(MsgLanguageSaved)}); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+	return c.Edit(languageSummary(loc, user), languageMarkup(loc))
+}
@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"goal-helper/internal/models"
+	"goal-helper/internal/repository"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+func TestLocalizeStoresClientLanguageOnce(t *testing.T) {
+	tests := []struct {
+		name         string
+		languageCode string // Сохраненный язык клиента
+		chosen       string // Язык, выбранный в /language
+		clientCode   string // Язык клиента в апдейте
+		wantCode     string
+		wantLanguage string // Язык переводчика апдейта
+	}{
+		{"user without language", "", "", "en", "en", "en"},
+		{"client language changed", "ru", "", "en", "ru", "ru"},
+		{"chosen language is kept", "", "ru", "en", "", "ru"},
+		{"no client language", "", "", "", "", "ru"},
+	}
+
+	teleBot, err := tele.NewBot(tele.Settings{Offline: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := repository.NewFileRepository(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repo.Close()
+
+			ctx := context.Background()
+			user := models.NewUser("1", "user", "User")
+			user.LanguageCode = tt.languageCode
+			user.Settings.Language = tt.chosen
+			if err := repo.CreateUser(ctx, user); err != nil {
+				t.Fatal(err)
+			}
+
+			b := &Bot{bot: teleBot, repo: repo}
+			var language string
+			handler := b.localize(func(c tele.Context) error {
+				language = localizer(c).Language()
+				return nil
+			})
+
+			c := teleBot.NewContext(tele.Update{Message: &tele.Message{
+				Sender: &tele.User{ID: 1, LanguageCode: tt.clientCode},
+			}})
+			if err := handler(c); err != nil {
+				t.Fatal(err)
+			}
+
+			saved, err := repo.GetUser(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if saved.LanguageCode != tt.wantCode {
+				t.Errorf("stored language code = %q, want %q", saved.LanguageCode, tt.wantCode)
+			}
+			if saved.Settings.Language != tt.chosen {
+				t.Errorf("chosen language = %q, want it kept as %q", saved.Settings.Language, tt.chosen)
+			}
+			if language != tt.wantLanguage {
+				t.Errorf("update language = %q, want %q", language, tt.wantLanguage)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"goal-helper/internal/models"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// goalStatusTitles ключи заголовков групп целей в /goals
+var goalStatusTitles = map[string]string{
+	models.GoalStatusActive:    MsgGoalsGroupActive,
+	models.GoalStatusPaused:    MsgGoalsGroupPaused,
+	models.GoalStatusAbandoned: MsgGoalsGroupAbandoned,
+	models.GoalStatusCompleted: MsgGoalsGroupCompleted,
+}
+
+// handlePause обрабатывает команду /pause [причина]
+func (b *Bot) handlePause(c tele.Context) error {
+	return b.setActiveGoalStatus(c, models.GoalStatusPaused, MsgGoalPausedTemplate)
+}
+
+// handleAbandon обрабатывает команду /abandon [причина]
+func (b *Bot) handleAbandon(c tele.Context) error {
+	return b.setActiveGoalStatus(c, models.GoalStatusAbandoned, MsgGoalAbandonedTemplate)
+}
+
+// setActiveGoalStatus переводит цель в фокусе в новый статус с причиной из аргументов команды
+// и переводит фокус на другую цель в работе: напоминания о ней больше не приходят
+func (b *Bot) setActiveGoalStatus(c tele.Context, status, template string) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorUserData))
+	}
+
+	if user.ActiveGoalID == "" {
+		return c.Send(tr(c, MsgNoActiveGoal))
+	}
+
+	goal, err := b.repo.GetGoal(ctx, user.ActiveGoalID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorGoal))
+	}
+
+	reason := strings.TrimSpace(c.Message().Payload)
+	if err := goal.SetStatus(status, reason); err != nil {
+		log.Printf("❌ Недопустимая смена статуса цели: %v", err)
+		return c.Send(tr(c, MsgGoalStatusChangeNotAllowed))
+	}
+
+	if err := b.repo.UpdateGoal(ctx, goal); err != nil {
+		return c.Send(tr(c, MsgErrorUpdateGoal))
+	}
+
+	next, err := b.refocus(ctx, user, goal.ID)
+	if err != nil {
+		log.Printf("❌ Ошибка при смене цели в фокусе: %v", err)
+		return c.Send(tr(c, MsgErrorUpdateUser))
+	}
+	rescheduleReminder(c)
+
+	return c.Send(tr(c, template, goal.Title) + focusMovedNote(c, next))
+}
+
+// handleResume обрабатывает команду /resume: предлагает выбрать отложенную или брошенную цель
+func (b *Bot) handleResume(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	goals, err := b.repo.GetUserGoals(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorSteps))
+	}
+
+	markup := &tele.ReplyMarkup{}
+	var rows []tele.Row
+	for _, goal := range goals {
+		if !goal.CanTransition(models.GoalStatusActive) {
+			continue
+		}
+		text := fmt.Sprintf("%s %s", goalStatusIcon(goal, ""), goal.Title)
+		rows = append(rows, markup.Row(markup.Data(text, CallbackResumeGoal, goal.ID)))
+	}
+
+	if len(rows) == 0 {
+		return c.Send(tr(c, MsgNoGoalsToResume))
+	}
+
+	markup.Inline(rows...)
+	return c.Send(tr(c, MsgResumeGoalsPrompt), markup)
+}
+
+// handleResumeGoal обрабатывает нажатие inline кнопки возврата цели в работу
+// Возвращенная цель сразу оказывается в фокусе
+func (b *Bot) handleResumeGoal(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+	goalID := c.Callback().Data
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUserData)})
+	}
+
+	goal, err := b.repo.GetGoal(ctx, goalID)
+	if err != nil || goal.UserID != userID {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorGoal)})
+	}
+
+	if err := goal.SetStatus(models.GoalStatusActive, ""); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgGoalStatusChangeNotAllowed), ShowAlert: true})
+	}
+
+	if err := b.repo.UpdateGoal(ctx, goal); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUpdateGoal)})
+	}
+
+	user.ActiveGoalID = goal.ID
+	if err := b.repo.UpdateUser(ctx, user); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUpdateUser)})
+	}
+	rescheduleReminder(c)
+
+	message := tr(c, MsgGoalResumedTemplate, goal.Title)
+	if err := c.Respond(&tele.CallbackResponse{Text: message}); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+
+	// Убираем кнопки, чтобы не вернуть цель по устаревшему списку
+	if err := c.Edit(message); err != nil {
+		log.Printf("❌ Ошибка при редактировании сообщения: %v", err)
+	}
+
+	// Показываем текущий шаг возвращенной цели
+	return b.handleStep(c)
+}
@@ -0,0 +1,365 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"goal-helper/internal/i18n"
+	"goal-helper/internal/llm"
+	"goal-helper/internal/models"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// handleMilestones обрабатывает команду /milestones: показывает этапы активной цели
+// Этапы необязательны - по умолчанию бот ведет к цели шаг за шагом без плана
+func (b *Bot) handleMilestones(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorUserData))
+	}
+
+	if user.ActiveGoalID == "" {
+		return c.Send(tr(c, MsgNoActiveGoal))
+	}
+
+	goal, err := b.repo.GetGoal(ctx, user.ActiveGoalID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorGoal))
+	}
+
+	return c.Send(milestonesSummary(localizer(c), goal), milestonesMarkup(localizer(c), goal))
+}
+
+// milestonesSummary описывает этапы цели (без Markdown - в тексте названия от пользователя и LLM)
+func milestonesSummary(loc *i18n.Localizer, goal *models.Goal) string {
+	var message strings.Builder
+	message.WriteString(loc.T(MsgMilestonesSummaryTemplate, goal.Title))
+
+	if !goal.HasMilestones() {
+		message.WriteString(loc.T(MsgMilestonesNone))
+		return message.String()
+	}
+
+	_, current := goal.CurrentMilestone()
+	for i, milestone := range goal.Milestones {
+		switch {
+		case milestone.IsCompleted():
+			message.WriteString(loc.T(MsgMilestoneDoneTemplate, i+1, milestone.Title))
+		case i == current:
+			message.WriteString(loc.T(MsgMilestoneCurrentTemplate, i+1, milestone.Title))
+		default:
+			message.WriteString(loc.T(MsgMilestoneUpcomingTemplate, i+1, milestone.Title))
+		}
+	}
+	message.WriteString(loc.T(MsgMilestonesHint))
+
+	return message.String()
+}
+
+// milestonesMarkup возвращает inline кнопки редактирования этапов цели
+func milestonesMarkup(loc *i18n.Localizer, goal *models.Goal) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	rows := make([]tele.Row, 0, len(goal.Milestones)+2)
+	for i := range goal.Milestones {
+		index := strconv.Itoa(i)
+		rows = append(rows, markup.Row(
+			markup.Data(loc.T(BtnTextMilestoneEditTemplate, i+1), CallbackMilestoneEdit, index, goal.ID),
+			markup.Data(loc.T(BtnTextMilestoneDeleteTemplate, i+1), CallbackMilestoneDelete, index, goal.ID),
+		))
+	}
+
+	row := tele.Row{markup.Data(loc.T(BtnTextMilestoneAdd), CallbackMilestoneAdd, goal.ID)}
+	if milestone, _ := goal.CurrentMilestone(); milestone != nil {
+		row = append(row, markup.Data(loc.T(BtnTextMilestoneDone), CallbackMilestoneDone, goal.ID))
+	}
+	rows = append(rows, row)
+
+	if goal.HasMilestones() {
+		rows = append(rows, markup.Row(
+			markup.Data(loc.T(BtnTextMilestonesRepropose), CallbackMilestonesPropose, goal.ID),
+			markup.Data(loc.T(BtnTextMilestonesClear), CallbackMilestonesClear, goal.ID),
+		))
+	} else {
+		rows = append(rows, markup.Row(markup.Data(loc.T(BtnTextMilestonesPropose), CallbackMilestonesPropose, goal.ID)))
+	}
+
+	markup.Inline(rows...)
+	return markup
+}
+
+// milestoneGoalFromCallback находит цель пользователя по ID из данных inline кнопки
+func (b *Bot) milestoneGoalFromCallback(c tele.Context, goalID string) (*models.Goal, error) {
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	goal, err := b.repo.GetGoal(requestContext(c), goalID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.UserID != userID {
+		return nil, fmt.Errorf("goal %s belongs to another user", goal.ID)
+	}
+	return goal, nil
+}
+
+// milestoneFromCallback находит цель и номер этапа по данным inline кнопки (номер|ID цели)
+func (b *Bot) milestoneFromCallback(c tele.Context) (*models.Goal, int, error) {
+	args := c.Args()
+	if len(args) != 2 {
+		return nil, 0, fmt.Errorf("unexpected callback data: %q", c.Callback().Data)
+	}
+
+	index, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid milestone index: %w", err)
+	}
+
+	goal, err := b.milestoneGoalFromCallback(c, args[1])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Список мог измениться с тех пор, как были показаны кнопки
+	if index < 0 || index >= len(goal.Milestones) {
+		return nil, 0, fmt.Errorf("goal %s has no milestone %d", goal.ID, index)
+	}
+
+	return goal, index, nil
+}
+
+// handleMilestoneEdit просит новое название этапа
+func (b *Bot) handleMilestoneEdit(c tele.Context) error {
+	goal, index, err := b.milestoneFromCallback(c)
+	if err != nil {
+		log.Printf("❌ Ошибка при выборе этапа: %v", err)
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgMilestoneNotFound), ShowAlert: true})
+	}
+
+	if err := c.Respond(); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+	return b.askMilestoneInput(c, goal.ID, strconv.Itoa(index), tr(c, MsgMilestoneEditTemplate, goal.Milestones[index].Title))
+}
+
+// handleMilestoneAdd просит название нового этапа
+func (b *Bot) handleMilestoneAdd(c tele.Context) error {
+	goal, err := b.milestoneGoalFromCallback(c, c.Callback().Data)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorGoal)})
+	}
+
+	if err := c.Respond(); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+	return b.askMilestoneInput(c, goal.ID, "", tr(c, MsgMilestoneAddPrompt))
+}
+
+// askMilestoneInput переводит пользователя в режим ввода названия этапа
+// Пустой номер этапа означает, что этап добавляется в конец плана
+func (b *Bot) askMilestoneInput(c tele.Context, goalID, index, prompt string) error {
+	state := b.getOrCreateState(c.Sender().ID)
+	state.State = StateEditingMilestone
+	state.TempData = map[string]string{
+		"goal_id":         goalID,
+		"milestone_index": index,
+	}
+	b.saveState(state)
+
+	return c.Send(prompt)
+}
+
+// handleMilestoneInput сохраняет название нового или исправленного этапа
+func (b *Bot) handleMilestoneInput(c tele.Context, state *models.UserState) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+	text := strings.TrimSpace(c.Text())
+
+	if text == "" {
+		return c.Send(tr(c, MsgEditGoalEmpty))
+	}
+
+	goal, err := b.repo.GetGoal(ctx, state.TempData["goal_id"])
+	if err != nil || goal.UserID != userID {
+		return c.Send(tr(c, MsgGoalNotFoundError))
+	}
+
+	result := MsgMilestoneAdded
+	if state.TempData["milestone_index"] == "" {
+		goal.AddMilestone(text)
+	} else {
+		index, err := strconv.Atoi(state.TempData["milestone_index"])
+		if err != nil {
+			return c.Send(tr(c, MsgMilestoneNotFound))
+		}
+		if err := goal.RenameMilestone(index, text); err != nil {
+			return c.Send(tr(c, MsgMilestoneNotFound))
+		}
+		result = MsgMilestoneUpdated
+	}
+
+	if err := b.repo.UpdateGoal(ctx, goal); err != nil {
+		return c.Send(tr(c, MsgErrorUpdateGoal))
+	}
+
+	state.State = StateIdle
+	state.TempData = make(map[string]string)
+	b.saveState(state)
+
+	return c.Send(tr(c, result)+"\n\n"+milestonesSummary(localizer(c), goal), milestonesMarkup(localizer(c), goal))
+}
+
+// handleMilestoneDelete удаляет этап и обновляет список
+func (b *Bot) handleMilestoneDelete(c tele.Context) error {
+	ctx := requestContext(c)
+
+	goal, index, err := b.milestoneFromCallback(c)
+	if err != nil {
+		log.Printf("❌ Ошибка при выборе этапа: %v", err)
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgMilestoneNotFound), ShowAlert: true})
+	}
+
+	if err := goal.RemoveMilestone(index); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgMilestoneNotFound), ShowAlert: true})
+	}
+	if err := b.repo.UpdateGoal(ctx, goal); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUpdateGoal)})
+	}
+
+	if err := c.Respond(&tele.CallbackResponse{Text: tr(c, MsgMilestoneDeleted)}); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+	return c.Edit(milestonesSummary(localizer(c), goal), milestonesMarkup(localizer(c), goal))
+}
+
+// handleMilestoneDone вручную отмечает текущий этап пройденным
+func (b *Bot) handleMilestoneDone(c tele.Context) error {
+	ctx := requestContext(c)
+
+	goal, err := b.milestoneGoalFromCallback(c, c.Callback().Data)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorGoal)})
+	}
+
+	milestone, err := goal.CompleteCurrentMilestone()
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgMilestoneNotFound), ShowAlert: true})
+	}
+	if err := b.repo.UpdateGoal(ctx, goal); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUpdateGoal)})
+	}
+
+	if err := c.Respond(&tele.CallbackResponse{Text: tr(c, MsgMilestoneDoneToastTemplate, milestone.Title)}); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+	return c.Edit(milestonesSummary(localizer(c), goal), milestonesMarkup(localizer(c), goal))
+}
+
+// handleMilestonesPropose просит LLM составить оставшиеся этапы цели
+// Пройденные этапы сохраняются, непройденные заменяются новым планом
+func (b *Bot) handleMilestonesPropose(c tele.Context) error {
+	ctx := requestContext(c)
+
+	goal, err := b.milestoneGoalFromCallback(c, c.Callback().Data)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorGoal)})
+	}
+
+	if err := c.Respond(); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+
+	steps, err := b.repo.GetGoalSteps(ctx, goal.ID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorSteps))
+	}
+	var completedSteps []*models.Step
+	for _, step := range steps {
+		if step.IsCompleted() {
+			completedSteps = append(completedSteps, step)
+		}
+	}
+
+	titles, err := b.llmClient.ProposeMilestones(ctx, goal, completedSteps)
+	if err != nil {
+		log.Printf("❌ Ошибка при составлении этапов: %v", err)
+		return c.Send(tr(c, MsgErrorProposeMilestones))
+	}
+
+	goal.PlanMilestones(titles)
+	if err := b.repo.UpdateGoal(ctx, goal); err != nil {
+		return c.Send(tr(c, MsgErrorUpdateGoal))
+	}
+
+	return c.Edit(milestonesSummary(localizer(c), goal), milestonesMarkup(localizer(c), goal))
+}
+
+// handleMilestonesClear убирает этапы цели
+func (b *Bot) handleMilestonesClear(c tele.Context) error {
+	ctx := requestContext(c)
+
+	goal, err := b.milestoneGoalFromCallback(c, c.Callback().Data)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorGoal)})
+	}
+
+	goal.ClearMilestones()
+	if err := b.repo.UpdateGoal(ctx, goal); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUpdateGoal)})
+	}
+
+	if err := c.Respond(&tele.CallbackResponse{Text: tr(c, MsgMilestonesCleared)}); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+	return c.Edit(milestonesSummary(localizer(c), goal), milestonesMarkup(localizer(c), goal))
+}
+
+// advanceMilestone отмечает текущий этап пройденным, если LLM так решила при генерации шага
+// Возвращает поздравление для начала сообщения с новым шагом (пустое, если этап не сменился)
+func (b *Bot) advanceMilestone(c tele.Context, goal *models.Goal, response *llm.StepResponse) string {
+	if !response.MilestoneCompleted {
+		return ""
+	}
+
+	milestone, err := goal.CompleteCurrentMilestone()
+	if err != nil {
+		// LLM могла отметить этап у цели без этапов
+		return ""
+	}
+	if err := b.repo.UpdateGoal(requestContext(c), goal); err != nil {
+		log.Printf("❌ Ошибка при сохранении пройденного этапа цели %s: %v", goal.ID, err)
+		return ""
+	}
+	log.Printf("🏁 Пройден этап %q цели %s", milestone.Title, goal.ID)
+
+	note := trGoal(c, goal, MsgMilestoneCompletedTemplate, milestone.Title)
+	if next, index := goal.CurrentMilestone(); next != nil {
+		note += tr(c, MsgMilestoneNextTemplate, index+1, len(goal.Milestones), next.Title)
+	}
+	return note
+}
+
+// milestoneStatus описывает прогресс по этапам для /status
+// Названия будущих этапов не показываются - только текущий этап
+func milestoneStatus(loc *i18n.Localizer, goal *models.Goal, steps []*models.Step) string {
+	if !goal.HasMilestones() {
+		return ""
+	}
+
+	milestone, index := goal.CurrentMilestone()
+	if milestone == nil {
+		return loc.T(MsgStatusMilestonesDoneTemplate, len(goal.Milestones))
+	}
+
+	done := 0
+	for _, step := range steps {
+		if step.MilestoneID == milestone.ID && step.IsCompleted() {
+			done++
+		}
+	}
+	return loc.T(MsgStatusMilestoneTemplate, index+1, len(goal.Milestones), milestone.Title, done)
+}
@@ -0,0 +1,221 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"goal-helper/internal/i18n"
+	"goal-helper/internal/models"
+	"goal-helper/internal/persona"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// userPersona возвращает персону, выбранную пользователем в /persona (или персону по умолчанию)
+func userPersona(user *models.User) string {
+	return persona.Resolve("", user.Settings.Persona)
+}
+
+// personaLocalizer возвращает переводчик, в котором шаблоны персоны заменяют стандартные сообщения
+func personaLocalizer(language, personaID string) *i18n.Localizer {
+	return i18n.New(language).WithOverrides(persona.Get(personaID).MessageOverrides(language))
+}
+
+// userLocalizer возвращает переводчик на языке и в тоне пользователя
+func userLocalizer(user *models.User) *i18n.Localizer {
+	return personaLocalizer(userLanguage(user), userPersona(user))
+}
+
+// goalLocalizer возвращает переводчик для сообщений о цели: у цели может быть своя персона
+func goalLocalizer(c tele.Context, goal *models.Goal) *i18n.Localizer {
+	if persona.Exists(goal.Persona) {
+		return personaLocalizer(localizer(c).Language(), goal.Persona)
+	}
+	return localizer(c)
+}
+
+// trGoal переводит сообщение о цели в тоне персоны цели
+func trGoal(c tele.Context, goal *models.Goal, key string, args ...any) string {
+	return goalLocalizer(c, goal).T(key, args...)
+}
+
+// personaTitle возвращает иконку и название персоны на языке language
+func personaTitle(p *persona.Persona, language string) string {
+	return p.Icon + " " + p.Name(language)
+}
+
+// personaButton добавляет к тексту кнопки отметку выбранного варианта
+func personaButton(text string, selected bool) string {
+	if selected {
+		return PersonaSelectedIcon + " " + text
+	}
+	return text
+}
+
+// activeGoal возвращает активную цель пользователя или nil, если ее нет
+func (b *Bot) activeGoal(ctx context.Context, user *models.User) *models.Goal {
+	if user.ActiveGoalID == "" {
+		return nil
+	}
+	goal, err := b.repo.GetGoal(ctx, user.ActiveGoalID)
+	if err != nil {
+		log.Printf("❌ Ошибка при загрузке активной цели %s: %v", user.ActiveGoalID, err)
+		return nil
+	}
+	return goal
+}
+
+// handlePersona обрабатывает команду /persona: показывает тон ассистента и варианты выбора
+func (b *Bot) handlePersona(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorUserData))
+	}
+
+	loc := localizer(c)
+	return c.Send(personaSummary(loc, user), personaMarkup(loc, user, b.activeGoal(ctx, user)))
+}
+
+// personaSummary описывает персону, выбранную для всех целей
+func personaSummary(loc *i18n.Localizer, user *models.User) string {
+	return loc.T(MsgPersonaTemplate, personaTitle(persona.Get(userPersona(user)), loc.Language()))
+}
+
+// personaMarkup возвращает inline кнопки выбора персоны для всех целей
+// Если есть активная цель, добавляется переход к выбору персоны только для нее
+func personaMarkup(loc *i18n.Localizer, user *models.User, goal *models.Goal) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	current := userPersona(user)
+
+	var rows []tele.Row
+	for _, p := range persona.All() {
+		text := personaButton(personaTitle(p, loc.Language()), p.ID == current)
+		rows = append(rows, markup.Row(markup.Data(text, CallbackPersona, p.ID)))
+	}
+	if goal != nil {
+		rows = append(rows, markup.Row(markup.Data(loc.T(BtnTextPersonaGoal), CallbackPersonaScope, PersonaScopeGoal)))
+	}
+
+	markup.Inline(rows...)
+	return markup
+}
+
+// goalPersonaSummary описывает персону активной цели (без Markdown - в тексте название цели)
+func goalPersonaSummary(loc *i18n.Localizer, user *models.User, goal *models.Goal) string {
+	current := loc.T(MsgPersonaInheritedTemplate, personaTitle(persona.Get(userPersona(user)), loc.Language()))
+	if persona.Exists(goal.Persona) {
+		current = personaTitle(persona.Get(goal.Persona), loc.Language())
+	}
+	return loc.T(MsgPersonaGoalTemplate, goal.Title, current)
+}
+
+// goalPersonaMarkup возвращает inline кнопки выбора персоны для активной цели
+// В данных кнопок нет ID цели: с ним идентификатор персоны мог бы не влезть в 64 байта
+func goalPersonaMarkup(loc *i18n.Localizer, goal *models.Goal) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+
+	var rows []tele.Row
+	for _, p := range persona.All() {
+		text := personaButton(personaTitle(p, loc.Language()), p.ID == goal.Persona)
+		rows = append(rows, markup.Row(markup.Data(text, CallbackGoalPersona, p.ID)))
+	}
+	rows = append(rows,
+		markup.Row(markup.Data(personaButton(loc.T(BtnTextPersonaInherit), !persona.Exists(goal.Persona)), CallbackGoalPersona, PersonaInherit)),
+		markup.Row(markup.Data(loc.T(BtnTextSettingsBack), CallbackPersonaScope, PersonaScopeUser)),
+	)
+
+	markup.Inline(rows...)
+	return markup
+}
+
+// handlePersonaScope переключает экран выбора персоны: для всех целей или для активной цели
+func (b *Bot) handlePersonaScope(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUserData)})
+	}
+
+	if err := c.Respond(); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+
+	loc := localizer(c)
+	goal := b.activeGoal(ctx, user)
+	if c.Callback().Data == PersonaScopeGoal && goal != nil {
+		return c.Edit(goalPersonaSummary(loc, user, goal), goalPersonaMarkup(loc, goal))
+	}
+	return c.Edit(personaSummary(loc, user), personaMarkup(loc, user, goal))
+}
+
+// handlePersonaSelect сохраняет персону для всех целей
+func (b *Bot) handlePersonaSelect(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	choice := c.Callback().Data
+	if !persona.Exists(choice) {
+		return c.Respond()
+	}
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUserData)})
+	}
+
+	user.Settings.Persona = choice
+	if err := b.repo.UpdateUser(ctx, user); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUpdateUser)})
+	}
+
+	// Дальше отвечаем уже в новом тоне
+	loc := userLocalizer(user)
+	c.Set(localizerKey, loc)
+
+	if err := c.Respond(&tele.CallbackResponse{Text: loc.T(MsgPersonaSaved)}); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+	return c.Edit(personaSummary(loc, user), personaMarkup(loc, user, b.activeGoal(ctx, user)))
+}
+
+// handleGoalPersonaSelect сохраняет персону для активной цели
+func (b *Bot) handleGoalPersonaSelect(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	choice := c.Callback().Data
+	if choice != PersonaInherit && !persona.Exists(choice) {
+		return c.Respond()
+	}
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUserData)})
+	}
+
+	goal := b.activeGoal(ctx, user)
+	if goal == nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgNoActiveGoal), ShowAlert: true})
+	}
+
+	if choice == PersonaInherit {
+		goal.Persona = ""
+	} else {
+		goal.Persona = choice
+	}
+	if err := b.repo.UpdateGoal(ctx, goal); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUpdateGoal)})
+	}
+
+	loc := localizer(c)
+	if err := c.Respond(&tele.CallbackResponse{Text: loc.T(MsgPersonaSaved)}); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+	return c.Edit(goalPersonaSummary(loc, user, goal), goalPersonaMarkup(loc, goal))
+}
@@ -0,0 +1,317 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"goal-helper/internal/i18n"
+	"goal-helper/internal/models"
+	"goal-helper/internal/persona"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// reminderIdleHours возвращает, через сколько часов бездействия напоминать о шаге
+func reminderIdleHours(settings models.ReminderSettings) int {
+	if settings.IdleHours > 0 {
+		return settings.IdleHours
+	}
+	return ReminderDefaultIdleHours
+}
+
+// nextReminderTime вычисляет время напоминания после активности в момент from:
+// через заданное число часов, но не раньше удобного пользователю времени,
+// в разрешенный день недели и вне тихих часов (все в местном времени пользователя)
+func nextReminderTime(from time.Time, settings models.UserSettings) time.Time {
+	reminders := settings.Reminders
+	due := from.In(settings.Location()).Add(time.Duration(reminderIdleHours(reminders)) * time.Hour)
+
+	if reminders.Time != "" {
+		if preferred, err := models.AtClock(due, reminders.Time); err == nil {
+			if preferred.Before(due) {
+				preferred = preferred.AddDate(0, 0, 1)
+			}
+			due = preferred
+		}
+	}
+
+	return allowedReminderTime(due, settings)
+}
+
+// allowedReminderTime переносит момент due на ближайшее время, когда пользователю можно напоминать:
+// в разрешенный день недели и вне тихих часов (в местном времени пользователя)
+func allowedReminderTime(due time.Time, settings models.UserSettings) time.Time {
+	reminders := settings.Reminders
+	due = due.In(settings.Location())
+
+	// Каждый перенос - либо на конец тихих часов, либо на следующий день,
+	// поэтому двух недель переносов хватает для любой комбинации настроек
+	for i := 0; i < ReminderMaxReschedules; i++ {
+		switch {
+		case settings.QuietHours.Contains(due):
+			due = settings.QuietHours.NextEnd(due)
+		case !reminders.AllowsDay(due.Weekday()):
+			due = due.AddDate(0, 0, 1)
+		default:
+			return due
+		}
+	}
+	return due
+}
+
+// reminderRetryTime возвращает время повторной отправки напоминания, которое не удалось отправить:
+// не раньше due, но с учетом тихих часов и дней напоминаний пользователя
+func (b *Bot) reminderRetryTime(ctx context.Context, reminder *models.Reminder, due time.Time) time.Time {
+	user, err := b.repo.GetUser(ctx, reminder.UserID)
+	if err != nil {
+		return due
+	}
+	return allowedReminderTime(due, user.Settings)
+}
+
+// refreshReminder переносит напоминание пользователя после изменения шага или настроек:
+// напоминаем о текущем шаге активной цели, а если шага нет - отменяем напоминание
+func (b *Bot) refreshReminder(ctx context.Context, userID string) {
+	reminder, err := b.pendingReminder(ctx, userID)
+	if err == nil {
+		if reminder == nil {
+			err = b.scheduler.Cancel(ctx, userID)
+		} else {
+			err = b.scheduler.Schedule(ctx, reminder)
+		}
+	}
+	if err != nil {
+		log.Printf("❌ Ошибка при планировании напоминания пользователя %s: %v", userID, err)
+	}
+}
+
+// pendingReminder возвращает напоминание о текущем шаге пользователя
+// или nil, если напоминать не о чем
+func (b *Bot) pendingReminder(ctx context.Context, userID string) (*models.Reminder, error) {
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		// Пользователь еще не нажал /start
+		return nil, nil
+	}
+
+	settings := user.Settings.Reminders
+	if settings.Disabled || user.ActiveGoalID == "" {
+		return nil, nil
+	}
+
+	currentStep, err := b.repo.GetCurrentStep(ctx, user.ActiveGoalID)
+	if err != nil {
+		// Все шаги выполнены
+		return nil, nil
+	}
+
+	return &models.Reminder{
+		UserID: userID,
+		GoalID: user.ActiveGoalID,
+		StepID: currentStep.ID,
+		DueAt:  nextReminderTime(time.Now(), user.Settings),
+	}, nil
+}
+
+// deliverReminder отправляет напоминание о шаге (вызывается планировщиком под мьютексом пользователя)
+// и возвращает следующее напоминание, если шаг так и не будет выполнен
+func (b *Bot) deliverReminder(ctx context.Context, reminder *models.Reminder) (*models.Reminder, error) {
+	current, err := b.pendingReminder(ctx, reminder.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil || current.GoalID != reminder.GoalID || current.StepID != reminder.StepID {
+		// Пока бот ждал, шаг выполнен или пользователь сменил цель
+		return nil, nil
+	}
+
+	goal, err := b.repo.GetGoal(ctx, reminder.GoalID)
+	if err != nil {
+		return nil, err
+	}
+	if !goal.IsActive() {
+		return nil, nil
+	}
+
+	step, err := b.repo.GetStep(ctx, reminder.StepID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := b.repo.GetUser(ctx, reminder.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	chatID, err := strconv.ParseInt(reminder.UserID, 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+
+	// Апдейта нет, поэтому язык и персону берем из сохраненных данных пользователя и цели
+	loc := personaLocalizer(userLanguage(user), persona.Resolve(goal.Persona, user.Settings.Persona))
+	message := loc.T(MsgReminderTemplate, goal.Title, step.Text)
+	_, err = b.bot.Send(tele.ChatID(chatID), message, &tele.SendOptions{
+		ParseMode:   tele.ModeMarkdown,
+		ReplyMarkup: reminderMarkup(loc, step.ID),
+	})
+	if errors.Is(err, tele.ErrBlockedByUser) || errors.Is(err, tele.ErrUserIsDeactivated) {
+		log.Printf("🔍 Пользователь %s недоступен, напоминания отменены", reminder.UserID)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔍 Отправлено напоминание пользователю %s о шаге %s", reminder.UserID, step.ID)
+
+	// Не надоедаем: после нескольких напоминаний ждем, пока пользователь вернется сам
+	next := *current
+	next.Nudges = reminder.Nudges + 1
+	if next.Nudges >= ReminderMaxNudges {
+		return nil, nil
+	}
+	return &next, nil
+}
+
+// reminderMarkup возвращает inline кнопки под напоминанием о шаге
+func reminderMarkup(loc *i18n.Localizer, stepID string) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(
+		markup.Data(loc.T(BtnTextDone), CallbackReminderDone, stepID),
+		markup.Data(loc.T(BtnTextSimpler), CallbackReminderSimpler, stepID),
+	))
+	return markup
+}
+
+// handleReminderDone обрабатывает кнопку "Выполнил" под напоминанием
+func (b *Bot) handleReminderDone(c tele.Context) error {
+	return b.handleReminderButton(c, b.handleDone)
+}
+
+// handleReminderSimpler обрабатывает кнопку "Упростить" под напоминанием
+func (b *Bot) handleReminderSimpler(c tele.Context) error {
+	return b.handleReminderButton(c, b.handleSimpler)
+}
+
+// handleReminderButton проверяет, что напоминание о текущем шаге, убирает кнопки
+// и передает управление обычному обработчику
+func (b *Bot) handleReminderButton(c tele.Context, next tele.HandlerFunc) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+	stepID := c.Callback().Data
+
+	reminder, err := b.pendingReminder(ctx, userID)
+	if err != nil || reminder == nil || reminder.StepID != stepID {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgReminderStale)})
+	}
+
+	if err := c.Respond(); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+	if _, err := b.bot.EditReplyMarkup(c.Message(), nil); err != nil {
+		log.Printf("❌ Ошибка при удалении кнопок напоминания: %v", err)
+	}
+
+	return next(c)
+}
+
+// handleRemind обрабатывает команду /remind
+// Без аргументов показывает настройки, иначе меняет их:
+// /remind 12h, /remind 2d, /remind 09:00, /remind any, /remind off, /remind on
+func (b *Bot) handleRemind(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorUserData))
+	}
+
+	args := c.Args()
+	if len(args) == 0 {
+		return c.Send(b.reminderStatus(ctx, localizer(c), user) + "\n\n" + tr(c, MsgRemindUsage))
+	}
+
+	settings, err := parseReminderSetting(user.Settings.Reminders, args[0])
+	if err != nil {
+		return c.Send(tr(c, MsgRemindInvalid) + "\n\n" + tr(c, MsgRemindUsage))
+	}
+
+	user.Settings.Reminders = settings
+	if err := b.repo.UpdateUser(ctx, user); err != nil {
+		return c.Send(tr(c, MsgErrorUpdateUser))
+	}
+
+	// Перепланируем сразу, а не после обработки апдейта, чтобы показать актуальное время
+	b.refreshReminder(ctx, userID)
+
+	return c.Send(tr(c, MsgRemindSaved) + "\n\n" + b.reminderStatus(ctx, localizer(c), user))
+}
+
+// parseReminderSetting применяет аргумент команды /remind к настройкам
+func parseReminderSetting(settings models.ReminderSettings, arg string) (models.ReminderSettings, error) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+
+	switch arg {
+	case "off":
+		settings.Disabled = true
+		return settings, nil
+	case "on":
+		settings.Disabled = false
+		return settings, nil
+	case "any":
+		settings.Time = ""
+		return settings, nil
+	}
+
+	if preferred, err := time.Parse(models.ClockLayout, arg); err == nil {
+		settings.Time = preferred.Format(models.ClockLayout)
+		settings.Disabled = false
+		return settings, nil
+	}
+
+	multiplier := 0
+	switch {
+	case strings.HasSuffix(arg, "h"):
+		multiplier = 1
+	case strings.HasSuffix(arg, "d"):
+		multiplier = 24
+	default:
+		return settings, fmt.Errorf("unknown reminder setting: %s", arg)
+	}
+
+	value, err := strconv.Atoi(arg[:len(arg)-1])
+	if err != nil || value <= 0 || value*multiplier > ReminderMaxIdleHours {
+		return settings, fmt.Errorf("invalid reminder delay: %s", arg)
+	}
+
+	settings.IdleHours = value * multiplier
+	settings.Disabled = false
+	return settings, nil
+}
+
+// reminderStatus описывает текущие настройки напоминаний пользователя
+func (b *Bot) reminderStatus(ctx context.Context, loc *i18n.Localizer, user *models.User) string {
+	settings := user.Settings.Reminders
+	if settings.Disabled {
+		return loc.T(MsgRemindDisabled)
+	}
+
+	status := loc.T(MsgRemindEnabledTemplate, reminderIdleHours(settings))
+	if settings.Time != "" {
+		status += loc.T(MsgRemindTimeTemplate, settings.Time)
+	}
+
+	reminder, err := b.scheduler.Get(ctx, user.ID)
+	if err == nil && reminder != nil {
+		dueAt := reminder.DueAt.In(user.Settings.Location())
+		status += loc.T(MsgRemindNextTemplate, dueAt.Format(ReminderDateLayout))
+	}
+	return status
+}
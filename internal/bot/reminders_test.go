@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"goal-helper/internal/models"
+)
+
+func TestAllowedReminderTime(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skip("timezone data is not available")
+	}
+	settings := models.UserSettings{
+		Timezone:   "Europe/Moscow",
+		QuietHours: models.QuietHours{Start: "22:00", End: "08:00"},
+		Reminders:  models.ReminderSettings{Days: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}},
+	}
+	at := func(day, hour, minute int) time.Time {
+		// Октябрь 2026: 12-е - понедельник
+		return time.Date(2026, time.October, day, hour, minute, 0, 0, location)
+	}
+
+	tests := []struct {
+		name string
+		due  time.Time
+		want time.Time
+	}{
+		{"allowed time stays", at(13, 12, 0), at(13, 12, 0)},
+		{"quiet hours in the evening", at(13, 22, 14), at(14, 8, 0)},
+		{"quiet hours after midnight", at(14, 3, 0), at(14, 8, 0)},
+		{"friday night moves past weekend", at(16, 23, 0), at(19, 8, 0)},
+		{"weekend moves to monday", at(17, 12, 0), at(19, 12, 0)},
+		{"other timezone is converted", at(13, 22, 30).UTC(), at(14, 8, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allowedReminderTime(tt.due, settings); !got.Equal(tt.want) {
+				t.Errorf("allowedReminderTime(%v) = %v, want %v", tt.due, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// requestContextKey ключ, под которым контекст запроса хранится в tele.Context
+const requestContextKey = "request_context"
+
+// requestTracker хранит функции отмены обрабатываемых (и ожидающих очереди)
+// апдейтов каждого пользователя, чтобы /cancel мог прервать запрос к LLM,
+// а остановка бота - дождаться завершения обработчиков
+type requestTracker struct {
+	mutex   sync.Mutex
+	nextID  uint64
+	cancels map[int64]map[uint64]context.CancelFunc
+	closed  bool           // Бот останавливается, новые апдейты не принимаются
+	active  sync.WaitGroup // Обрабатываемые апдейты
+
+	baseCtx    context.Context // Родительский контекст всех запросов
+	cancelBase context.CancelFunc
+}
+
+// newRequestTracker создает пустой набор запросов
+func newRequestTracker() *requestTracker {
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	return &requestTracker{
+		cancels:    make(map[int64]map[uint64]context.CancelFunc),
+		baseCtx:    baseCtx,
+		cancelBase: cancelBase,
+	}
+}
+
+// Start создает отменяемый контекст запроса пользователя.
+// Таймаут на него накладывается позже, когда апдейт дождется очереди (см. serializeUpdates).
+// Возвращаемую функцию нужно вызвать по окончании обработки.
+// После Close возвращает false: бот останавливается и апдейт обрабатывать не нужно
+func (t *requestTracker) Start(userID int64) (context.Context, func(), bool) {
+	t.mutex.Lock()
+	if t.closed {
+		t.mutex.Unlock()
+		return nil, nil, false
+	}
+
+	ctx, cancel := context.WithCancel(t.baseCtx)
+	t.active.Add(1)
+	t.nextID++
+	id := t.nextID
+	if t.cancels[userID] == nil {
+		t.cancels[userID] = make(map[uint64]context.CancelFunc)
+	}
+	t.cancels[userID][id] = cancel
+	t.mutex.Unlock()
+
+	return ctx, func() {
+		cancel()
+
+		t.mutex.Lock()
+		delete(t.cancels[userID], id)
+		if len(t.cancels[userID]) == 0 {
+			delete(t.cancels, userID)
+		}
+		t.mutex.Unlock()
+
+		t.active.Done()
+	}, true
+}
+
+// CancelAll отменяет все запросы пользователя и возвращает их количество
+func (t *requestTracker) CancelAll(userID int64) int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, cancel := range t.cancels[userID] {
+		cancel()
+	}
+	return len(t.cancels[userID])
+}
+
+// Close перестает принимать новые запросы и ждет завершения текущих.
+// Если ctx истекает раньше, все запросы отменяются и Close ждет еще grace,
+// чтобы обработчики успели освободить ресурсы
+func (t *requestTracker) Close(ctx context.Context, grace time.Duration) error {
+	t.mutex.Lock()
+	t.closed = true
+	t.mutex.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		t.active.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		t.cancelBase()
+		return nil
+	case <-ctx.Done():
+	}
+
+	// Время вышло - прерываем запросы к LLM и хранилищу
+	t.cancelBase()
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+
+	select {
+	case <-finished:
+	case <-timer.C:
+	}
+	return ctx.Err()
+}
+
+// trackRequests middleware, которое выдает каждому апдейту отменяемый контекст.
+// Должно стоять перед serializeUpdates: /cancel отменяет запросы пользователя
+// до того, как встанет в очередь за его мьютексом
+func (b *Bot) trackRequests(next tele.HandlerFunc) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		if c.Sender() == nil {
+			return next(c)
+		}
+
+		userID := c.Sender().ID
+		if isCancelCommand(c) {
+			if cancelled := b.requests.CancelAll(userID); cancelled > 0 {
+				log.Printf("🔍 Отменено запросов пользователя %d: %d", userID, cancelled)
+			}
+		}
+
+		ctx, done, ok := b.requests.Start(userID)
+		if !ok {
+			log.Printf("🔍 Бот останавливается, апдейт пользователя %d пропущен", userID)
+			return nil
+		}
+		defer done()
+
+		c.Set(requestContextKey, ctx)
+		return next(&cancelableContext{Context: c, ctx: ctx})
+	}
+}
+
+// requestContext возвращает контекст текущего запроса
+func requestContext(c tele.Context) context.Context {
+	if ctx, ok := c.Get(requestContextKey).(context.Context); ok {
+		return ctx
+	}
+	return context.Background()
+}
+
+// isCancelCommand проверяет, является ли апдейт командой /cancel (в том числе /cancel@bot)
+func isCancelCommand(c tele.Context) bool {
+	fields := strings.Fields(c.Text())
+	if len(fields) == 0 {
+		return false
+	}
+	command, _, _ := strings.Cut(fields[0], "@")
+	return command == CmdCancel
+}
+
+// cancelableContext ничего не показывает пользователю после отмены запроса:
+// ответ на /cancel уже отправлен, а новый шаг или сообщение об ошибке LLM только запутают
+type cancelableContext struct {
+	tele.Context
+	ctx context.Context
+}
+
+// cancelled проверяет, отменен ли запрос
+func (c *cancelableContext) cancelled() bool {
+	return errors.Is(c.ctx.Err(), context.Canceled)
+}
+
+// Send отправляет сообщение, если запрос не был отменен
+func (c *cancelableContext) Send(what any, opts ...any) error {
+	if c.cancelled() {
+		return nil
+	}
+	return c.Context.Send(what, opts...)
+}
+
+// Reply отвечает на сообщение, если запрос не был отменен
+func (c *cancelableContext) Reply(what any, opts ...any) error {
+	if c.cancelled() {
+		return nil
+	}
+	return c.Context.Reply(what, opts...)
+}
+
+// Edit редактирует сообщение, если запрос не был отменен
+func (c *cancelableContext) Edit(what any, opts ...any) error {
+	if c.cancelled() {
+		return nil
+	}
+	return c.Context.Edit(what, opts...)
+}
+
+// Respond отвечает на callback, если запрос не был отменен
+func (c *cancelableContext) Respond(resp ...*tele.CallbackResponse) error {
+	if c.cancelled() {
+		return nil
+	}
+	return c.Context.Respond(resp...)
+}
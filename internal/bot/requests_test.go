@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// recordingContext записывает, какие сообщения обработчик пытался показать пользователю
+type recordingContext struct {
+	tele.Context
+	calls []string
+}
+
+func (c *recordingContext) Send(what any, opts ...any) error {
+	c.calls = append(c.calls, "send")
+	return nil
+}
+
+func (c *recordingContext) Reply(what any, opts ...any) error {
+	c.calls = append(c.calls, "reply")
+	return nil
+}
+
+func (c *recordingContext) Edit(what any, opts ...any) error {
+	c.calls = append(c.calls, "edit")
+	return nil
+}
+
+func (c *recordingContext) Respond(resp ...*tele.CallbackResponse) error {
+	c.calls = append(c.calls, "respond")
+	return nil
+}
+
+// showAll вызывает все способы показать что-то пользователю
+func showAll(c tele.Context) {
+	c.Send("text")
+	c.Reply("text")
+	c.Edit("text")
+	c.Respond()
+}
+
+func TestCancelableContextSuppressesOutputAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	recorder := &recordingContext{}
+	c := &cancelableContext{Context: recorder, ctx: ctx}
+
+	showAll(c)
+	if len(recorder.calls) != 4 {
+		t.Fatalf("calls before cancel = %v, want all 4", recorder.calls)
+	}
+
+	cancel()
+	recorder.calls = nil
+	showAll(c)
+	if len(recorder.calls) != 0 {
+		t.Errorf("calls after cancel = %v, want none", recorder.calls)
+	}
+}
+
+func TestCancelableContextKeepsOutputOnTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	// Об истекшем таймауте пользователю нужно сообщить
+	recorder := &recordingContext{}
+	showAll(&cancelableContext{Context: recorder, ctx: ctx})
+	if len(recorder.calls) != 4 {
+		t.Errorf("calls after timeout = %v, want all 4", recorder.calls)
+	}
+}
+
+func TestRequestTimeoutStartsAfterUserLock(t *testing.T) {
+	teleBot, err := tele.NewBot(tele.Settings{Offline: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &Bot{bot: teleBot, userLocks: newUserLocks()}
+
+	var deadline time.Time
+	var acquired time.Time
+	handler := b.serializeUpdates(func(c tele.Context) error {
+		acquired = time.Now()
+		deadline, _ = requestContext(c).Deadline()
+		return nil
+	})
+
+	// Пока апдейт ждет в очереди, его таймаут не идет
+	unlock := b.userLocks.Lock(1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler(teleBot.NewContext(tele.Update{Message: &tele.Message{Sender: &tele.User{ID: 1}}}))
+	}()
+	time.Sleep(50 * time.Millisecond)
+	unlock()
+	<-done
+
+	if deadline.IsZero() {
+		t.Fatal("request context has no deadline")
+	}
+	if want := acquired.Add(BotRequestTimeoutSeconds * time.Second); deadline.Before(want.Add(-10 * time.Millisecond)) {
+		t.Errorf("deadline = %v, want about %v (timeout counted from lock acquisition)", deadline, want)
+	}
+}
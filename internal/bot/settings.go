@@ -0,0 +1,527 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"goal-helper/internal/i18n"
+	"goal-helper/internal/models"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// Варианты, которые предлагаются кнопками в /settings
+var (
+	settingsTimezones = []string{
+		"Europe/Kaliningrad", "Europe/Moscow",
+		"Europe/Samara", "Asia/Yekaterinburg",
+		"Asia/Omsk", "Asia/Novosibirsk",
+		"Asia/Irkutsk", "Asia/Vladivostok",
+		"Europe/Minsk", "Asia/Almaty",
+		"Europe/Berlin", "UTC",
+	}
+	settingsQuietHours = []models.QuietHours{
+		{Start: "22:00", End: "08:00"},
+		{Start: "23:00", End: "07:00"},
+		{Start: "00:00", End: "09:00"},
+		{Start: "21:00", End: "09:00"},
+	}
+	settingsReminderTimes = []string{"08:00", "09:00", "12:00", "18:00", "20:00", "21:00"}
+
+	// Дни недели в порядке отображения (с понедельника) и ключи их коротких названий
+	settingsWeekdays = []time.Weekday{
+		time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+	}
+	weekdayNames = map[time.Weekday]string{
+		time.Monday: MsgWeekdayMonday, time.Tuesday: MsgWeekdayTuesday, time.Wednesday: MsgWeekdayWednesday,
+		time.Thursday: MsgWeekdayThursday, time.Friday: MsgWeekdayFriday, time.Saturday: MsgWeekdaySaturday,
+		time.Sunday: MsgWeekdaySunday,
+	}
+)
+
+// handleSettings обрабатывает команду /settings
+func (b *Bot) handleSettings(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorUserData))
+	}
+
+	return c.Send(settingsSummary(localizer(c), user), settingsMainMarkup(localizer(c), user))
+}
+
+// handleSettingsMenu переключает разделы меню настроек
+func (b *Bot) handleSettingsMenu(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUserData)})
+	}
+
+	var markup *tele.ReplyMarkup
+	switch c.Callback().Data {
+	case SettingsSectionTimezone:
+		markup = settingsTimezoneMarkup(localizer(c))
+	case SettingsSectionQuietHours:
+		markup = settingsQuietHoursMarkup(localizer(c))
+	case SettingsSectionReminderTime:
+		markup = settingsReminderTimeMarkup(localizer(c))
+	case SettingsSectionDays:
+		markup = settingsDaysMarkup(localizer(c), user.Settings.Reminders)
+	default:
+		markup = settingsMainMarkup(localizer(c), user)
+	}
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+	return c.Edit(settingsSummary(localizer(c), user), markup)
+}
+
+// handleSettingsTimezone сохраняет часовой пояс, выбранный кнопкой
+func (b *Bot) handleSettingsTimezone(c tele.Context) error {
+	value := c.Callback().Data
+	if value == SettingsManualInput {
+		return b.askSettingsInput(c, StateWaitingTimezone, tr(c, MsgSettingsTimezonePrompt))
+	}
+
+	timezone, err := parseTimezone(value)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgSettingsInvalidTimezone)})
+	}
+
+	return b.updateSettingsFromCallback(c, func(settings *models.UserSettings) error {
+		settings.Timezone = timezone
+		return nil
+	})
+}
+
+// handleSettingsQuietHours сохраняет тихие часы, выбранные кнопкой
+func (b *Bot) handleSettingsQuietHours(c tele.Context) error {
+	value := c.Callback().Data
+	if value == SettingsManualInput {
+		return b.askSettingsInput(c, StateWaitingQuietHours, tr(c, MsgSettingsQuietHoursPrompt))
+	}
+
+	quietHours, err := parseQuietHours(value)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgSettingsInvalidQuietHours)})
+	}
+
+	return b.updateSettingsFromCallback(c, func(settings *models.UserSettings) error {
+		settings.QuietHours = quietHours
+		return nil
+	})
+}
+
+// handleSettingsReminderTime сохраняет удобное время напоминаний
+func (b *Bot) handleSettingsReminderTime(c tele.Context) error {
+	value := c.Callback().Data
+
+	return b.updateSettingsFromCallback(c, func(settings *models.UserSettings) error {
+		if value == SettingsAnyTime {
+			settings.Reminders.Time = ""
+			return nil
+		}
+		if _, err := time.Parse(models.ClockLayout, value); err != nil {
+			return err
+		}
+		settings.Reminders.Time = value
+		return nil
+	})
+}
+
+// handleSettingsDay включает или выключает день недели для напоминаний
+// Меню дней остается открытым, чтобы можно было отметить несколько дней подряд
+func (b *Bot) handleSettingsDay(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUserData)})
+	}
+
+	days, err := toggleReminderDay(user.Settings.Reminders.Days, c.Callback().Data)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgSettingsNoDays), ShowAlert: true})
+	}
+
+	user.Settings.Reminders.Days = days
+	if err := b.repo.UpdateUser(ctx, user); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUpdateUser)})
+	}
+	rescheduleReminder(c)
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+	return c.Edit(settingsSummary(localizer(c), user), settingsDaysMarkup(localizer(c), user.Settings.Reminders))
+}
+
+// handleSettingsReminders включает или выключает напоминания
+func (b *Bot) handleSettingsReminders(c tele.Context) error {
+	return b.updateSettingsFromCallback(c, func(settings *models.UserSettings) error {
+		settings.Reminders.Disabled = !settings.Reminders.Disabled
+		return nil
+	})
+}
+
+// updateSettingsFromCallback применяет изменение настроек и возвращает главное меню
+func (b *Bot) updateSettingsFromCallback(c tele.Context, update func(settings *models.UserSettings) error) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.saveSettings(ctx, userID, update)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUpdateUser)})
+	}
+	rescheduleReminder(c)
+
+	if err := c.Respond(&tele.CallbackResponse{Text: tr(c, MsgSettingsSaved)}); err != nil {
+		return err
+	}
+	return c.Edit(settingsSummary(localizer(c), user), settingsMainMarkup(localizer(c), user))
+}
+
+// saveSettings загружает пользователя, применяет изменение настроек и сохраняет его
+func (b *Bot) saveSettings(ctx context.Context, userID string, update func(settings *models.UserSettings) error) (*models.User, error) {
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := update(&user.Settings); err != nil {
+		return nil, err
+	}
+
+	if err := b.repo.UpdateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// askSettingsInput переводит пользователя в ожидание ручного ввода настройки
+func (b *Bot) askSettingsInput(c tele.Context, stateName, prompt string) error {
+	state := b.getOrCreateState(c.Sender().ID)
+	state.State = stateName
+	state.TempData = make(map[string]string)
+	b.saveState(state)
+
+	if err := c.Respond(); err != nil {
+		return err
+	}
+	return c.Send(prompt)
+}
+
+// handleSettingsInput обрабатывает ручной ввод часового пояса или тихих часов
+func (b *Bot) handleSettingsInput(c tele.Context, state *models.UserState) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+	text := strings.TrimSpace(c.Text())
+
+	var update func(settings *models.UserSettings) error
+	switch state.State {
+	case StateWaitingTimezone:
+		timezone, err := parseTimezone(text)
+		if err != nil {
+			return c.Send(tr(c, MsgSettingsInvalidTimezone) + "\n\n" + tr(c, MsgSettingsTimezonePrompt))
+		}
+		update = func(settings *models.UserSettings) error {
+			settings.Timezone = timezone
+			return nil
+		}
+	case StateWaitingQuietHours:
+		quietHours, err := parseQuietHours(text)
+		if err != nil {
+			return c.Send(tr(c, MsgSettingsInvalidQuietHours) + "\n\n" + tr(c, MsgSettingsQuietHoursPrompt))
+		}
+		update = func(settings *models.UserSettings) error {
+			settings.QuietHours = quietHours
+			return nil
+		}
+	default:
+		return c.Send(tr(c, MsgHelpDefault))
+	}
+
+	user, err := b.saveSettings(ctx, userID, update)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorUpdateUser))
+	}
+	rescheduleReminder(c)
+
+	state.State = StateIdle
+	state.TempData = make(map[string]string)
+	b.saveState(state)
+
+	return c.Send(tr(c, MsgSettingsSaved)+"\n\n"+settingsSummary(localizer(c), user), settingsMainMarkup(localizer(c), user))
+}
+
+// settingsSummary описывает текущие настройки пользователя
+func settingsSummary(loc *i18n.Localizer, user *models.User) string {
+	settings := user.Settings
+
+	timezone := settings.Timezone
+	if timezone == "" {
+		timezone = loc.T(MsgSettingsServerTimezone)
+	}
+
+	quietHours := loc.T(MsgSettingsOff)
+	if settings.QuietHours.Enabled() {
+		quietHours = settings.QuietHours.String()
+	}
+
+	reminders := loc.T(MsgSettingsOff)
+	if !settings.Reminders.Disabled {
+		reminders = loc.T(MsgSettingsRemindersTemplate, reminderIdleHours(settings.Reminders))
+		if settings.Reminders.Time != "" {
+			reminders += loc.T(MsgRemindTimeTemplate, settings.Reminders.Time)
+		}
+	}
+
+	return loc.T(MsgSettingsTemplate,
+		timezone, settings.Now().Format(models.ClockLayout),
+		quietHours,
+		reminders,
+		formatReminderDays(loc, settings.Reminders.Days),
+	)
+}
+
+// formatReminderDays возвращает дни напоминаний в виде "Пн, Ср, Пт"
+func formatReminderDays(loc *i18n.Localizer, days []time.Weekday) string {
+	if len(days) == 0 {
+		return loc.T(MsgSettingsEveryDay)
+	}
+
+	names := make([]string, 0, len(days))
+	for _, day := range settingsWeekdays {
+		if slices.Contains(days, day) {
+			names = append(names, loc.T(weekdayNames[day]))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// settingsMainMarkup возвращает главное меню настроек
+func settingsMainMarkup(loc *i18n.Localizer, user *models.User) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+
+	toggle := loc.T(BtnTextRemindersOff)
+	if user.Settings.Reminders.Disabled {
+		toggle = loc.T(BtnTextRemindersOn)
+	}
+
+	markup.Inline(
+		markup.Row(
+			markup.Data(loc.T(BtnTextSettingsTimezone), CallbackSettingsMenu, SettingsSectionTimezone),
+			markup.Data(loc.T(BtnTextSettingsQuietHours), CallbackSettingsMenu, SettingsSectionQuietHours),
+		),
+		markup.Row(
+			markup.Data(loc.T(BtnTextSettingsReminderTime), CallbackSettingsMenu, SettingsSectionReminderTime),
+			markup.Data(loc.T(BtnTextSettingsDays), CallbackSettingsMenu, SettingsSectionDays),
+		),
+		markup.Row(markup.Data(toggle, CallbackSettingsReminders)),
+	)
+	return markup
+}
+
+// settingsTimezoneMarkup возвращает меню выбора часового пояса
+func settingsTimezoneMarkup(loc *i18n.Localizer) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+
+	var buttons []tele.Btn
+	for _, timezone := range settingsTimezones {
+		buttons = append(buttons, markup.Data(timezoneLabel(timezone), CallbackSettingsTimezone, timezone))
+	}
+
+	rows := markup.Split(2, buttons)
+	rows = append(rows,
+		markup.Row(markup.Data(loc.T(BtnTextSettingsManual), CallbackSettingsTimezone, SettingsManualInput)),
+		markup.Row(markup.Data(loc.T(BtnTextSettingsBack), CallbackSettingsMenu, SettingsSectionMain)),
+	)
+	markup.Inline(rows...)
+	return markup
+}
+
+// timezoneLabel возвращает подпись часового пояса с текущим смещением от UTC
+func timezoneLabel(timezone string) string {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return timezone
+	}
+	return fmt.Sprintf("%s (%s)", timezone, time.Now().In(location).Format("UTC-07:00"))
+}
+
+// settingsQuietHoursMarkup возвращает меню выбора тихих часов
+func settingsQuietHoursMarkup(loc *i18n.Localizer) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+
+	var buttons []tele.Btn
+	for _, quietHours := range settingsQuietHours {
+		buttons = append(buttons, markup.Data(quietHours.String(), CallbackSettingsQuietHours, quietHours.String()))
+	}
+
+	rows := markup.Split(2, buttons)
+	rows = append(rows,
+		markup.Row(
+			markup.Data(loc.T(BtnTextSettingsManual), CallbackSettingsQuietHours, SettingsManualInput),
+			markup.Data(loc.T(BtnTextSettingsDisable), CallbackSettingsQuietHours, SettingsDisabled),
+		),
+		markup.Row(markup.Data(loc.T(BtnTextSettingsBack), CallbackSettingsMenu, SettingsSectionMain)),
+	)
+	markup.Inline(rows...)
+	return markup
+}
+
+// settingsReminderTimeMarkup возвращает меню выбора времени напоминаний
+func settingsReminderTimeMarkup(loc *i18n.Localizer) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+
+	var buttons []tele.Btn
+	for _, clock := range settingsReminderTimes {
+		buttons = append(buttons, markup.Data(clock, CallbackSettingsReminderTime, clock))
+	}
+
+	rows := markup.Split(3, buttons)
+	rows = append(rows,
+		markup.Row(markup.Data(loc.T(BtnTextSettingsAnyTime), CallbackSettingsReminderTime, SettingsAnyTime)),
+		markup.Row(markup.Data(loc.T(BtnTextSettingsBack), CallbackSettingsMenu, SettingsSectionMain)),
+	)
+	markup.Inline(rows...)
+	return markup
+}
+
+// settingsDaysMarkup возвращает меню выбора дней недели с отметками выбранных дней
+func settingsDaysMarkup(loc *i18n.Localizer, reminders models.ReminderSettings) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+
+	var buttons []tele.Btn
+	for _, day := range settingsWeekdays {
+		mark := SettingsDayOffIcon
+		if reminders.AllowsDay(day) {
+			mark = SettingsDayOnIcon
+		}
+		buttons = append(buttons, markup.Data(mark+" "+loc.T(weekdayNames[day]), CallbackSettingsDay, strconv.Itoa(int(day))))
+	}
+
+	rows := markup.Split(4, buttons)
+	rows = append(rows,
+		markup.Row(
+			markup.Data(loc.T(BtnTextSettingsEveryDay), CallbackSettingsDay, SettingsDaysAll),
+			markup.Data(loc.T(BtnTextSettingsWeekdays), CallbackSettingsDay, SettingsDaysWeekdays),
+		),
+		markup.Row(markup.Data(loc.T(BtnTextSettingsDone), CallbackSettingsMenu, SettingsSectionMain)),
+	)
+	markup.Inline(rows...)
+	return markup
+}
+
+// toggleReminderDay применяет нажатие кнопки дня недели к списку дней
+// Пустой список означает "каждый день"
+func toggleReminderDay(days []time.Weekday, value string) ([]time.Weekday, error) {
+	switch value {
+	case SettingsDaysAll:
+		return nil, nil
+	case SettingsDaysWeekdays:
+		return []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < int(time.Sunday) || number > int(time.Saturday) {
+		return days, fmt.Errorf("invalid weekday: %s", value)
+	}
+	day := time.Weekday(number)
+
+	// "Каждый день" раскрываем в полный список, чтобы можно было снять один день
+	if len(days) == 0 {
+		days = slices.Clone(settingsWeekdays)
+	} else {
+		days = slices.Clone(days)
+	}
+
+	if index := slices.Index(days, day); index >= 0 {
+		days = slices.Delete(days, index, index+1)
+	} else {
+		days = append(days, day)
+	}
+
+	if len(days) == 0 {
+		return nil, errors.New("at least one reminder day is required")
+	}
+	if len(days) == len(settingsWeekdays) {
+		return nil, nil
+	}
+
+	slices.Sort(days)
+	return days, nil
+}
+
+// parseTimezone разбирает часовой пояс: название IANA ("Europe/Moscow")
+// или смещение в целых часах ("UTC+3", "GMT-5", "+3")
+func parseTimezone(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" || strings.EqualFold(input, "local") {
+		return "", errors.New("empty timezone")
+	}
+
+	if _, err := time.LoadLocation(input); err == nil {
+		return input, nil
+	}
+
+	offset := strings.ToUpper(input)
+	offset = strings.TrimPrefix(offset, "UTC")
+	offset = strings.TrimPrefix(offset, "GMT")
+	if offset == "" {
+		return "UTC", nil
+	}
+
+	hours, err := strconv.Atoi(offset)
+	if err != nil || hours < -12 || hours > 14 {
+		return "", fmt.Errorf("unknown timezone: %s", input)
+	}
+	if hours == 0 {
+		return "UTC", nil
+	}
+
+	// В зонах Etc/GMT знак инвертирован: Etc/GMT-3 - это UTC+3
+	return fmt.Sprintf("Etc/GMT%+d", -hours), nil
+}
+
+// parseQuietHours разбирает тихие часы "ЧЧ:ММ-ЧЧ:ММ" или "off"
+func parseQuietHours(input string) (models.QuietHours, error) {
+	input = strings.TrimSpace(input)
+	if strings.EqualFold(input, SettingsDisabled) {
+		return models.QuietHours{}, nil
+	}
+
+	start, end, found := strings.Cut(strings.ReplaceAll(input, "–", "-"), "-")
+	if !found {
+		return models.QuietHours{}, fmt.Errorf("invalid quiet hours: %s", input)
+	}
+
+	startTime, err := time.Parse(models.ClockLayout, strings.TrimSpace(start))
+	if err != nil {
+		return models.QuietHours{}, fmt.Errorf("invalid quiet hours start: %w", err)
+	}
+	endTime, err := time.Parse(models.ClockLayout, strings.TrimSpace(end))
+	if err != nil {
+		return models.QuietHours{}, fmt.Errorf("invalid quiet hours end: %w", err)
+	}
+	if startTime.Equal(endTime) {
+		return models.QuietHours{}, errors.New("quiet hours start and end must differ")
+	}
+
+	return models.QuietHours{
+		Start: startTime.Format(models.ClockLayout),
+		End:   endTime.Format(models.ClockLayout),
+	}, nil
+}
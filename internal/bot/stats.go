@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"goal-helper/internal/i18n"
+	"goal-helper/internal/stats"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// handleStats обрабатывает команду /stats
+func (b *Bot) handleStats(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorUserData))
+	}
+
+	userStats, err := stats.ForUser(ctx, b.repo, user, time.Now())
+	if err != nil {
+		return c.Send(tr(c, MsgErrorStats))
+	}
+
+	if userStats.GoalsTotal == 0 {
+		return c.Send(tr(c, MsgNoGoals))
+	}
+
+	return c.Send(formatStats(localizer(c), userStats, user.ActiveGoalID))
+}
+
+// formatStats формирует текст статистики (без Markdown - в названиях целей бывают спецсимволы)
+func formatStats(loc *i18n.Localizer, userStats *stats.Stats, activeGoalID string) string {
+	var message strings.Builder
+	message.WriteString(loc.T(MsgStatsTitle))
+
+	message.WriteString(loc.T(MsgStatsStreakTemplate, userStats.CurrentStreak, userStats.LongestStreak))
+	message.WriteString(loc.T(MsgStatsStepsTemplate, userStats.StepsCompleted))
+
+	weeks := make([]string, 0, len(userStats.Weeks))
+	for _, week := range userStats.Weeks {
+		weeks = append(weeks, strconv.Itoa(week.Steps))
+	}
+	message.WriteString(loc.T(MsgStatsWeeksTemplate, strings.Join(weeks, " · "), userStats.AveragePerWeek))
+
+	if userStats.StepsCompleted > 0 {
+		message.WriteString(loc.T(MsgStatsAverageTemplate, formatDuration(loc, userStats.AverageCompletion)))
+	}
+	message.WriteString(loc.T(MsgStatsGoalsTemplate, userStats.GoalsCompleted, userStats.GoalsTotal))
+
+	message.WriteString(loc.T(MsgStatsByGoal))
+	for _, goalStats := range userStats.Goals {
+		message.WriteString(loc.T(MsgStatsGoalTemplate,
+			goalStatusIcon(goalStats.Goal, activeGoalID), goalStats.Goal.Title,
+			goalStats.StepsCompleted, goalStats.StepsTotal,
+			goalStats.Rephrases, goalStats.Simplifications))
+	}
+
+	return message.String()
+}
+
+// formatDuration описывает длительность в днях, часах или минутах
+func formatDuration(loc *i18n.Localizer, d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+
+	switch {
+	case days > 0:
+		return loc.T(MsgDurationDaysTemplate, days, hours)
+	case hours > 0:
+		return loc.T(MsgDurationHoursTemplate, hours, int(d%time.Hour/time.Minute))
+	default:
+		return loc.T(MsgDurationMinutesTemplate, int(d/time.Minute))
+	}
+}
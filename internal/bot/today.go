@@ -0,0 +1,229 @@
+package bot
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"goal-helper/internal/i18n"
+	"goal-helper/internal/models"
+
+	"github.com/google/uuid"
+	tele "gopkg.in/telebot.v3"
+)
+
+// В работе может быть несколько целей (статус active), одна из них в фокусе (User.ActiveGoalID):
+// к ней относятся /step, /done, /next и напоминания. /today показывает шаги всех целей в работе
+
+// activeGoals возвращает цели пользователя в работе: сначала цель в фокусе, затем остальные по порядку создания
+func (b *Bot) activeGoals(ctx context.Context, user *models.User) ([]*models.Goal, error) {
+	goals, err := b.repo.GetUserGoals(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	models.SortGoals(goals)
+
+	var focus *models.Goal
+	var others []*models.Goal
+	for _, goal := range goals {
+		if !goal.IsActive() {
+			continue
+		}
+		if goal.ID == user.ActiveGoalID {
+			focus = goal
+		} else {
+			others = append(others, goal)
+		}
+	}
+
+	if focus == nil {
+		return others, nil
+	}
+	return append([]*models.Goal{focus}, others...), nil
+}
+
+// refocus переводит фокус с цели leftGoalID (отложенной, завершенной или удаленной)
+// на цель в работе, которую пользователь трогал последней
+// Возвращает новую цель в фокусе или nil, если других целей в работе нет
+func (b *Bot) refocus(ctx context.Context, user *models.User, leftGoalID string) (*models.Goal, error) {
+	goals, err := b.activeGoals(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load goals: %w", err)
+	}
+
+	var next *models.Goal
+	for _, goal := range goals {
+		if goal.ID == leftGoalID || !goal.IsActive() {
+			continue
+		}
+		if next == nil || goal.UpdatedAt.After(next.UpdatedAt) {
+			next = goal
+		}
+	}
+
+	user.ActiveGoalID = ""
+	if next != nil {
+		user.ActiveGoalID = next.ID
+	}
+	if err := b.repo.UpdateUser(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return next, nil
+}
+
+// focusMovedNote сообщает, какая цель теперь в фокусе (пусто, если целей в работе не осталось)
+func focusMovedNote(c tele.Context, next *models.Goal) string {
+	if next == nil {
+		return ""
+	}
+	return tr(c, MsgFocusMovedTemplate, next.Title)
+}
+
+// handleToday обрабатывает команду /today: текущие шаги всех целей в работе
+func (b *Bot) handleToday(c tele.Context) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Send(tr(c, MsgErrorUserData))
+	}
+
+	message, markup, err := b.todayDigest(ctx, localizer(c), user)
+	if err != nil {
+		log.Printf("❌ Ошибка при составлении шагов на сегодня: %v", err)
+		return c.Send(tr(c, MsgErrorSteps))
+	}
+
+	return c.Send(message, markup, &tele.SendOptions{ParseMode: tele.ModeMarkdown})
+}
+
+// todayDigest составляет сводку текущих шагов по целям в работе
+// У каждого шага свои кнопки "Выполнил" и "Упростить" с ID цели и шага
+func (b *Bot) todayDigest(ctx context.Context, loc *i18n.Localizer, user *models.User) (string, *tele.ReplyMarkup, error) {
+	goals, err := b.activeGoals(ctx, user)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(goals) == 0 {
+		return loc.T(MsgTodayNoGoals), nil, nil
+	}
+
+	var message strings.Builder
+	message.WriteString(loc.T(MsgTodayTitle))
+
+	markup := &tele.ReplyMarkup{}
+	var rows []tele.Row
+	for i, goal := range goals {
+		message.WriteString(loc.T(MsgTodayGoalTemplate, goalStatusIcon(goal, user.ActiveGoalID), i+1, goal.Title))
+
+		// Ошибка здесь означает, что текущего шага нет: все шаги выполнены или пропущены
+		step, err := b.repo.GetCurrentStep(ctx, goal.ID)
+		if err != nil {
+			message.WriteString(loc.T(MsgTodayNoStep))
+			continue
+		}
+
+		message.WriteString(loc.T(MsgTodayStepTemplate, step.Text))
+		goalID, stepID := compactID(goal.ID), compactID(step.ID)
+		rows = append(rows, markup.Row(
+			markup.Data(loc.T(BtnTextTodayDoneTemplate, i+1), CallbackTodayDone, goalID, stepID),
+			markup.Data(loc.T(BtnTextTodaySimplerTemplate, i+1), CallbackTodaySimpler, goalID, stepID),
+		))
+	}
+	message.WriteString(loc.T(MsgTodayHint))
+
+	if len(rows) == 0 {
+		return message.String(), nil, nil
+	}
+	markup.Inline(rows...)
+	return message.String(), markup, nil
+}
+
+// handleTodayDone обрабатывает кнопку "Выполнил" в /today
+func (b *Bot) handleTodayDone(c tele.Context) error {
+	return b.handleTodayButton(c, func(goal *models.Goal, step *models.Step) error {
+		return b.completeStep(c, goal, step)
+	})
+}
+
+// handleTodaySimpler обрабатывает кнопку "Упростить" в /today
+func (b *Bot) handleTodaySimpler(c tele.Context) error {
+	return b.handleTodayButton(c, func(goal *models.Goal, step *models.Step) error {
+		return b.simplifyStep(c, goal, step, nil)
+	})
+}
+
+// handleTodayButton проверяет, что шаг из кнопки все еще текущий, и выполняет над ним действие;
+// после него сводка /today обновляется. Цель в фокусе при этом не меняется
+func (b *Bot) handleTodayButton(c tele.Context, action func(goal *models.Goal, step *models.Step) error) error {
+	ctx := requestContext(c)
+	userID := strconv.FormatInt(c.Sender().ID, 10)
+
+	args := c.Args()
+	if len(args) != 2 {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgTodayStale)})
+	}
+	goalID, stepID := expandID(args[0]), expandID(args[1])
+
+	user, err := b.repo.GetUser(ctx, userID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgErrorUserData)})
+	}
+
+	goal, err := b.repo.GetGoal(ctx, goalID)
+	if err != nil || goal.UserID != userID || !goal.IsActive() {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgTodayStale)})
+	}
+
+	step, err := b.repo.GetCurrentStep(ctx, goal.ID)
+	if err != nil || step.ID != stepID {
+		return c.Respond(&tele.CallbackResponse{Text: tr(c, MsgTodayStale)})
+	}
+
+	if err := c.Respond(); err != nil {
+		log.Printf("❌ Ошибка при ответе на callback: %v", err)
+	}
+
+	if err := action(goal, step); err != nil {
+		return err
+	}
+
+	message, markup, err := b.todayDigest(ctx, localizer(c), user)
+	if err != nil {
+		log.Printf("❌ Ошибка при обновлении шагов на сегодня: %v", err)
+		return nil
+	}
+	if err := c.Edit(message, markup, &tele.SendOptions{ParseMode: tele.ModeMarkdown}); err != nil {
+		log.Printf("❌ Ошибка при редактировании сообщения: %v", err)
+	}
+	return nil
+}
+
+// compactID сжимает UUID до 22 символов base64: два ID целиком не влезают в 64 байта данных кнопки
+// ID другого формата возвращается как есть
+func compactID(id string) string {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return id
+	}
+	return base64.RawURLEncoding.EncodeToString(parsed[:])
+}
+
+// expandID восстанавливает ID, сжатый compactID
+func expandID(id string) string {
+	data, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return id
+	}
+	parsed, err := uuid.FromBytes(data)
+	if err != nil {
+		return id
+	}
+	return parsed.String()
+}
@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// userLocks выдает отдельный мьютекс на каждого пользователя.
+// telebot запускает обработчики параллельно, поэтому апдейты одного
+// пользователя (например, двойное нажатие "✅ Выполнил") нужно
+// обрабатывать строго последовательно.
+type userLocks struct {
+	mutex sync.Mutex
+	locks map[int64]*userLock
+}
+
+// userLock мьютекс пользователя со счетчиком ожидающих обработчиков
+type userLock struct {
+	mutex sync.Mutex
+	refs  int
+}
+
+// newUserLocks создает набор мьютексов пользователей
+func newUserLocks() *userLocks {
+	return &userLocks{
+		locks: make(map[int64]*userLock),
+	}
+}
+
+// Lock захватывает мьютекс пользователя и возвращает функцию для его освобождения
+func (l *userLocks) Lock(userID int64) func() {
+	l.mutex.Lock()
+	lock, exists := l.locks[userID]
+	if !exists {
+		lock = &userLock{}
+		l.locks[userID] = lock
+	}
+	lock.refs++
+	l.mutex.Unlock()
+
+	lock.mutex.Lock()
+
+	return func() {
+		lock.mutex.Unlock()
+
+		// Удаляем мьютекс, когда его больше никто не ждет
+		l.mutex.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(l.locks, userID)
+		}
+		l.mutex.Unlock()
+	}
+}
+
+// lockUserByID захватывает мьютекс пользователя по строковому ID из репозитория
+func (b *Bot) lockUserByID(userID string) func() {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return func() {}
+	}
+	return b.userLocks.Lock(id)
+}
+
+// reminderChangedKey ключ tele.Context, которым обработчик отмечает, что напоминание нужно перепланировать
+const reminderChangedKey = "reminder_changed"
+
+// rescheduleReminder отмечает, что апдейт изменил текущий шаг, цель в фокусе или настройки напоминаний:
+// после обработки напоминание будет перенесено (см. serializeUpdates)
+func rescheduleReminder(c tele.Context) {
+	c.Set(reminderChangedKey, true)
+}
+
+// serializeUpdates middleware, которое обрабатывает апдейты одного пользователя по очереди
+// Если обработчик отметил изменение шага или настроек (rescheduleReminder),
+// напоминание переносится здесь же, под мьютексом пользователя
+func (b *Bot) serializeUpdates(next tele.HandlerFunc) tele.HandlerFunc {
+	return func(c tele.Context) error {
+		if c.Sender() == nil {
+			return next(c)
+		}
+
+		unlock := b.userLocks.Lock(c.Sender().ID)
+		defer unlock()
+
+		// Таймаут отсчитывается с момента, когда апдейт дождался своей очереди
+		ctx, cancel := context.WithTimeout(requestContext(c), BotRequestTimeoutSeconds*time.Second)
+		defer cancel()
+		c.Set(requestContextKey, ctx)
+
+		err := next(c)
+
+		if changed, _ := c.Get(reminderChangedKey).(bool); changed {
+			// Даже если запрос отменен через /cancel, сделанные изменения нужно учесть
+			ctx := context.WithoutCancel(requestContext(c))
+			b.refreshReminder(ctx, strconv.FormatInt(c.Sender().ID, 10))
+		}
+
+		return err
+	}
+}
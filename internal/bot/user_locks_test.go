@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"goal-helper/internal/i18n"
+	"goal-helper/internal/llm"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// TestSerializeUpdatesConcurrent отправляет апдейты нескольких пользователей параллельно
+// (запускать с -race): апдейты одного пользователя не должны пересекаться,
+// а общий кэш промптов должен выдерживать одновременные запросы к LLM
+func TestSerializeUpdatesConcurrent(t *testing.T) {
+	// Загрузчик ищет промпты относительно рабочей папки, а go test запускается в папке пакета
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir("../.."); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	teleBot, err := tele.NewBot(tele.Settings{Offline: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loader := llm.NewPromptLoader()
+	b := &Bot{bot: teleBot, userLocks: newUserLocks()}
+
+	const users, updatesPerUser = 8, 20
+	var inFlight [users]int32
+	var overlaps int32
+
+	handler := b.serializeUpdates(func(c tele.Context) error {
+		id := c.Sender().ID
+		if atomic.AddInt32(&inFlight[id], 1) != 1 {
+			atomic.AddInt32(&overlaps, 1)
+		}
+		defer atomic.AddInt32(&inFlight[id], -1)
+
+		languages := i18n.Languages()
+		ctx := i18n.WithLanguage(requestContext(c), languages[int(id)%len(languages)])
+		if _, err := loader.LoadPrompt(ctx, "step_generation", map[string]string{"goal_title": c.Text()}); err != nil {
+			return err
+		}
+		if id == 0 {
+			loader.ClearCache()
+		}
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, users*updatesPerUser)
+	for user := 0; user < users; user++ {
+		for i := 0; i < updatesPerUser; i++ {
+			wg.Add(1)
+			go func(user int64, i int) {
+				defer wg.Done()
+				c := teleBot.NewContext(tele.Update{Message: &tele.Message{
+					Sender: &tele.User{ID: user},
+					Text:   fmt.Sprintf("update %d", i),
+				}})
+				if err := handler(c); err != nil {
+					errs <- err
+				}
+			}(int64(user), i)
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("handler failed: %v", err)
+	}
+	if overlaps > 0 {
+		t.Errorf("updates of one user overlapped %d times", overlaps)
+	}
+}
@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// WebhookConfig настройки получения апдейтов через webhook.
+// Если конфигурация не задана, бот использует long polling
+type WebhookConfig struct {
+	Listen      string // Адрес HTTP сервера, например ":8443"
+	PublicURL   string // Публичный URL, на который Telegram отправляет апдейты
+	SecretToken string // Секрет из заголовка X-Telegram-Bot-Api-Secret-Token
+	TLSCert     string // Путь к сертификату (опционально, без него сервер работает по HTTP)
+	TLSKey      string // Путь к ключу сертификата
+	SelfSigned  bool   // Загрузить TLSCert в Telegram (для самоподписанного сертификата)
+}
+
+// Validate проверяет конфигурацию webhook
+func (c *WebhookConfig) Validate() error {
+	if c.PublicURL == "" {
+		return errors.New("webhook public URL is required")
+	}
+	if _, err := url.Parse(c.PublicURL); err != nil {
+		return fmt.Errorf("invalid webhook public URL: %w", err)
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return errors.New("webhook TLS requires both certificate and key")
+	}
+	if c.SelfSigned && c.TLSCert == "" {
+		return errors.New("self-signed webhook requires TLS certificate")
+	}
+	return nil
+}
+
+// webhookPoller принимает апдейты от Telegram по HTTP.
+// Используется вместо tele.Webhook: в telebot v3.1 он паникует при остановке бота
+// (повторно закрывает канал stop) и молча отвечает 200 на запросы с неверным секретом
+type webhookPoller struct {
+	config WebhookConfig
+	errs   chan error // Ошибка, с которой упал HTTP сервер
+	stop   func()     // Останавливает бота, если сервер упал
+}
+
+// newWebhookPoller создает поллер для webhook
+func newWebhookPoller(config WebhookConfig) *webhookPoller {
+	return &webhookPoller{
+		config: config,
+		errs:   make(chan error, 1),
+	}
+}
+
+// Poll запускает HTTP сервер и передает апдейты боту до закрытия stop
+func (p *webhookPoller) Poll(b *tele.Bot, dest chan tele.Update, stop chan struct{}) {
+	publicURL, _ := url.Parse(p.config.PublicURL)
+	path := publicURL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, p.updatesHandler(dest, stop))
+	mux.HandleFunc(WebhookHealthPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := &http.Server{
+		Addr:              p.config.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: WebhookReadTimeoutSeconds * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("🔍 Webhook сервер слушает %s (путь %s)", p.config.Listen, path)
+		if p.config.TLSCert != "" {
+			serveErr <- server.ListenAndServeTLS(p.config.TLSCert, p.config.TLSKey)
+		} else {
+			serveErr <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case <-stop:
+		ctx, cancel := context.WithTimeout(context.Background(), WebhookShutdownSeconds*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("❌ Ошибка при остановке webhook сервера: %v", err)
+		}
+	case err := <-serveErr:
+		// Без сервера апдейты не придут - останавливаем бота, Start вернет ошибку
+		log.Printf("❌ Webhook сервер остановлен с ошибкой: %v", err)
+		p.errs <- fmt.Errorf("webhook server failed: %w", err)
+		go p.stop()
+	}
+}
+
+// Err возвращает ошибку HTTP сервера, если он упал
+func (p *webhookPoller) Err() error {
+	select {
+	case err := <-p.errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+// updatesHandler проверяет секрет и передает апдейт в канал бота
+func (p *webhookPoller) updatesHandler(dest chan tele.Update, stop chan struct{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		if p.config.SecretToken != "" {
+			token := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(p.config.SecretToken)) != 1 {
+				log.Printf("❌ Webhook запрос с неверным секретом от %s", r.RemoteAddr)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		var update tele.Update
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			log.Printf("❌ Не удалось разобрать апдейт из webhook: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case dest <- update:
+			w.WriteHeader(http.StatusOK)
+		case <-stop:
+			// Бот останавливается - Telegram повторит доставку позже
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}
+}
+
+// register сообщает Telegram публичный URL webhook
+func (p *webhookPoller) register(b *tele.Bot) error {
+	webhook := &tele.Webhook{
+		SecretToken: p.config.SecretToken,
+		Endpoint:    &tele.WebhookEndpoint{PublicURL: p.config.PublicURL},
+	}
+	if p.config.SelfSigned {
+		webhook.Endpoint.Cert = p.config.TLSCert
+	}
+
+	if err := b.SetWebhook(webhook); err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package calibration
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"goal-helper/internal/models"
+)
+
+// Level размер шагов, который подходит пользователю
+type Level int
+
+// Уровни размера шагов
+const (
+	LevelTiny   Level = iota + 1 // Минимальные шаги: пользователю сейчас тяжело
+	LevelSmall                   // Шаги поменьше обычного
+	LevelNormal                  // Обычные шаги (5 минут - 1 день)
+	LevelLarge                   // Шаги побольше: пользователь легко справляется
+	LevelHuge                    // Крупные шаги для опытного пользователя
+)
+
+const (
+	// Window сколько последних закрытых шагов учитывать, чтобы уровень успевал меняться
+	Window = 10
+
+	// MinSignals сколько закрытых шагов нужно, чтобы судить об уровне по цели
+	MinSignals = 3
+
+	// Быстрое выполнение говорит о том, что шаги можно делать больше, медленное - что меньше
+	fastCompletion = 2 * time.Hour
+	slowCompletion = 3 * 24 * time.Hour
+)
+
+// Signals сигналы о сложности шагов, собранные из истории
+type Signals struct {
+	Finished        int           // Закрытых шагов (выполненных и пропущенных)
+	Pending         int           // Незакрытых шагов: их упрощения тоже учитываются
+	Completed       int           // Выполненных шагов
+	Skipped         int           // Пропущенных шагов
+	Simplifications int           // Сколько раз шаги упрощались
+	TooEasy         int           // Оценок "слишком легко"
+	TooHard         int           // Оценок "слишком сложно"
+	Rated           int           // Всего оценок
+	MedianDuration  time.Duration // Медианное время от появления шага до выполнения
+}
+
+// Collect собирает сигналы по последним Window закрытым шагам
+// и упрощениям текущего, еще не закрытого шага
+func Collect(steps []*models.Step) Signals {
+	var signals Signals
+	var finished []*models.Step
+	for _, step := range steps {
+		if step.IsFinished() {
+			finished = append(finished, step)
+			continue
+		}
+		// Шаг упрощают, пока он не закрыт, поэтому упрощения текущего шага - самый свежий сигнал
+		signals.Pending++
+		signals.Simplifications += step.Simplifications
+	}
+
+	// Шаги разных целей перемешаны - упорядочиваем по времени создания
+	sort.Slice(finished, func(i, j int) bool { return finished[i].CreatedAt.Before(finished[j].CreatedAt) })
+	if len(finished) > Window {
+		finished = finished[len(finished)-Window:]
+	}
+
+	var durations []time.Duration
+	for _, step := range finished {
+		signals.Finished++
+		signals.Simplifications += step.Simplifications
+
+		if step.IsSkipped() {
+			signals.Skipped++
+			continue
+		}
+
+		signals.Completed++
+		if duration := step.CompletedAt.Sub(step.CreatedAt); duration > 0 {
+			durations = append(durations, duration)
+		}
+
+		switch step.Difficulty {
+		case models.StepDifficultyTooEasy:
+			signals.TooEasy++
+			signals.Rated++
+		case models.StepDifficultyTooHard:
+			signals.TooHard++
+			signals.Rated++
+		case models.StepDifficultyRight:
+			signals.Rated++
+		}
+	}
+
+	if len(durations) > 0 {
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		signals.MedianDuration = durations[len(durations)/2]
+	}
+
+	return signals
+}
+
+// Level вычисляет уровень по сигналам
+// Явные оценки весят больше всего, затем упрощения и пропуски, затем скорость выполнения
+func (s Signals) Level() Level {
+	if s.Finished == 0 && s.Simplifications == 0 {
+		return LevelNormal
+	}
+
+	score := 0.0
+	if s.Rated > 0 {
+		score += 2 * float64(s.TooEasy-s.TooHard) / float64(s.Rated)
+	}
+	score -= 2 * float64(s.Simplifications) / float64(s.Finished+s.Pending)
+	if s.Finished > 0 {
+		score -= float64(s.Skipped) / float64(s.Finished)
+	}
+
+	if s.MedianDuration > 0 {
+		switch {
+		case s.MedianDuration <= fastCompletion:
+			score += 0.5
+		case s.MedianDuration >= slowCompletion:
+			score -= 0.5
+		}
+	}
+
+	switch {
+	case score >= 1.5:
+		return LevelHuge
+	case score >= 0.5:
+		return LevelLarge
+	case score <= -1.5:
+		return LevelTiny
+	case score <= -0.5:
+		return LevelSmall
+	default:
+		return LevelNormal
+	}
+}
+
+// Calibrate возвращает уровень для цели по её шагам.
+// Если по цели сигналов мало (например, цель новая), уровень считается по последним
+// закрытым шагам пользователя recentSteps вместе с текущим шагом цели
+func Calibrate(goalSteps, recentSteps []*models.Step) Level {
+	if signals := Collect(goalSteps); signals.Finished >= MinSignals {
+		return signals.Level()
+	}
+
+	steps := slices.Clip(recentSteps)
+	for _, step := range goalSteps {
+		if !step.IsFinished() {
+			steps = append(steps, step)
+		}
+	}
+	return Collect(steps).Level()
+}
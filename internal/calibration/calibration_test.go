@@ -0,0 +1,138 @@
+package calibration
+
+import (
+	"testing"
+	"time"
+
+	"goal-helper/internal/models"
+)
+
+var start = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+// completed возвращает шаг, выполненный за duration после создания, с оценкой difficulty
+func completed(n int, duration time.Duration, difficulty string) *models.Step {
+	createdAt := start.Add(time.Duration(n) * 24 * time.Hour)
+	completedAt := createdAt.Add(duration)
+	return &models.Step{CreatedAt: createdAt, CompletedAt: &completedAt, Difficulty: difficulty}
+}
+
+// skipped возвращает пропущенный шаг
+func skipped(n int) *models.Step {
+	createdAt := start.Add(time.Duration(n) * 24 * time.Hour)
+	skippedAt := createdAt.Add(time.Hour)
+	return &models.Step{CreatedAt: createdAt, SkippedAt: &skippedAt}
+}
+
+// current возвращает незакрытый шаг, упрощенный simplifications раз
+func current(n, simplifications int) *models.Step {
+	createdAt := start.Add(time.Duration(n) * 24 * time.Hour)
+	return &models.Step{CreatedAt: createdAt, Simplifications: simplifications}
+}
+
+func TestCollectUsesLastWindow(t *testing.T) {
+	var steps []*models.Step
+	for i := 0; i < Window+5; i++ {
+		difficulty := models.StepDifficultyTooEasy
+		if i < 5 {
+			difficulty = models.StepDifficultyTooHard
+		}
+		steps = append(steps, completed(i, 12*time.Hour, difficulty))
+	}
+	// Порядок во входных данных не важен: шаги разных целей перемешаны
+	steps[0], steps[len(steps)-1] = steps[len(steps)-1], steps[0]
+
+	signals := Collect(steps)
+	if signals.Finished != Window || signals.TooHard != 0 || signals.TooEasy != Window {
+		t.Errorf("signals = %+v, want only the last %d too easy steps", signals, Window)
+	}
+}
+
+func TestCollectCountsCurrentStep(t *testing.T) {
+	signals := Collect([]*models.Step{
+		completed(0, 12*time.Hour, ""),
+		skipped(1),
+		current(2, 2),
+	})
+	want := Signals{Finished: 2, Pending: 1, Completed: 1, Skipped: 1, Simplifications: 2, MedianDuration: 12 * time.Hour}
+	if signals != want {
+		t.Errorf("signals = %+v, want %+v", signals, want)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []*models.Step
+		want  Level
+	}{
+		{"no history", nil, LevelNormal},
+		{"unsimplified current step", []*models.Step{current(0, 0)}, LevelNormal},
+		{"simplified current step", []*models.Step{current(0, 1)}, LevelTiny},
+		{"fast and too easy", []*models.Step{
+			completed(0, time.Hour, models.StepDifficultyTooEasy),
+			completed(1, time.Hour, models.StepDifficultyTooEasy),
+			completed(2, time.Hour, models.StepDifficultyTooEasy),
+		}, LevelHuge},
+		{"fast only", []*models.Step{
+			completed(0, time.Hour, ""),
+			completed(1, time.Hour, ""),
+		}, LevelLarge},
+		{"slow and too hard", []*models.Step{
+			completed(0, 4*24*time.Hour, models.StepDifficultyTooHard),
+			completed(1, 4*24*time.Hour, models.StepDifficultyTooHard),
+		}, LevelTiny},
+		{"right with simplified current step", []*models.Step{
+			completed(0, 12*time.Hour, models.StepDifficultyRight),
+			completed(1, 12*time.Hour, models.StepDifficultyRight),
+			completed(2, 12*time.Hour, models.StepDifficultyRight),
+			current(3, 1),
+		}, LevelSmall},
+		{"mostly skipped", []*models.Step{
+			skipped(0),
+			skipped(1),
+			completed(2, 12*time.Hour, ""),
+		}, LevelSmall},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Collect(tt.steps).Level(); got != tt.want {
+				t.Errorf("Level() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	easy := []*models.Step{
+		completed(0, time.Hour, models.StepDifficultyTooEasy),
+		completed(1, time.Hour, models.StepDifficultyTooEasy),
+		completed(2, time.Hour, models.StepDifficultyTooEasy),
+	}
+	right := []*models.Step{
+		completed(3, 12*time.Hour, models.StepDifficultyRight),
+		completed(4, 12*time.Hour, models.StepDifficultyRight),
+		completed(5, 12*time.Hour, models.StepDifficultyRight),
+	}
+
+	tests := []struct {
+		name        string
+		goalSteps   []*models.Step
+		recentSteps []*models.Step
+		want        Level
+	}{
+		{"new user", nil, nil, LevelNormal},
+		{"enough goal history", right, easy, LevelNormal},
+		{"new goal uses user history", nil, easy, LevelHuge},
+		{"short goal history uses user history", right[:1], easy, LevelHuge},
+		{"user history with simplified current step", []*models.Step{current(6, 2)}, right, LevelSmall},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Calibrate(tt.goalSteps, tt.recentSteps); got != tt.want {
+				t.Errorf("Calibrate() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Поддерживаемые языки
+const (
+	LanguageRussian = "ru"
+	LanguageEnglish = "en"
+
+	// DefaultLanguage язык по умолчанию: на нем написаны исходные тексты бота
+	DefaultLanguage = LanguageRussian
+)
+
+// languages поддерживаемые языки в порядке показа пользователю
+var languages = []string{LanguageRussian, LanguageEnglish}
+
+// Каталоги сообщений лежат в locales/<язык>.json и встраиваются в бинарник
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalogs сообщения по языкам: ключ -> текст
+var catalogs = loadCatalogs()
+
+// loadCatalogs читает каталоги всех поддерживаемых языков
+// Каталоги встроены в бинарник, поэтому ошибка в них - ошибка сборки, а не окружения
+func loadCatalogs() map[string]map[string]string {
+	result := make(map[string]map[string]string, len(languages))
+	for _, language := range languages {
+		data, err := localeFiles.ReadFile(path.Join("locales", language+".json"))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog for %s: %v", language, err))
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog for %s: %v", language, err))
+		}
+		result[language] = messages
+	}
+	return result
+}
+
+// Languages возвращает поддерживаемые языки
+func Languages() []string {
+	return append([]string(nil), languages...)
+}
+
+// Match подбирает поддерживаемый язык по коду языка Telegram (например, "en-US" -> "en")
+func Match(code string) (string, bool) {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(code)), "-")
+	if _, ok := catalogs[base]; ok {
+		return base, true
+	}
+	return "", false
+}
+
+// Resolve выбирает язык пользователя: явно выбранный, иначе язык клиента Telegram, иначе язык по умолчанию
+func Resolve(chosen, clientCode string) string {
+	if language, ok := Match(chosen); ok {
+		return language
+	}
+	if language, ok := Match(clientCode); ok {
+		return language
+	}
+	return DefaultLanguage
+}
+
+// Has проверяет, что сообщение с таким ключом есть в каталоге по умолчанию
+func Has(key string) bool {
+	_, ok := catalogs[DefaultLanguage][key]
+	return ok
+}
+
+// Localizer переводит сообщения на один язык
+type Localizer struct {
+	language  string
+	messages  map[string]string
+	overrides map[string]string // Шаблоны, заменяющие сообщения каталога (например, в тоне персоны)
+}
+
+// New создает переводчик; неподдерживаемый язык заменяется языком по умолчанию
+func New(language string) *Localizer {
+	language = Resolve(language, "")
+	return &Localizer{language: language, messages: catalogs[language]}
+}
+
+// Language возвращает язык переводчика
+func (l *Localizer) Language() string {
+	return l.language
+}
+
+// WithOverrides возвращает переводчик, у которого сообщения из overrides заменяют сообщения каталога
+func (l *Localizer) WithOverrides(overrides map[string]string) *Localizer {
+	if len(overrides) == 0 {
+		return l
+	}
+	return &Localizer{language: l.language, messages: l.messages, overrides: overrides}
+}
+
+// T возвращает текст сообщения по ключу и подставляет аргументы, если они есть
+// Если перевода нет, берется текст на языке по умолчанию, а если нет и его - сам ключ
+func (l *Localizer) T(key string, args ...any) string {
+	message, ok := l.overrides[key]
+	if !ok {
+		message, ok = l.messages[key]
+	}
+	if !ok {
+		if message, ok = catalogs[DefaultLanguage][key]; !ok {
+			message = key
+		}
+	}
+
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// languageKey ключ языка в context.Context
+type languageKey struct{}
+
+// WithLanguage возвращает контекст, в котором запрос выполняется на языке language
+// Так язык пользователя доходит до LLM без отдельного параметра в каждом методе
+func WithLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, languageKey{}, language)
+}
+
+// FromContext возвращает язык запроса (язык по умолчанию, если он не задан)
+func FromContext(ctx context.Context) string {
+	if language, ok := ctx.Value(languageKey{}).(string); ok {
+		return Resolve(language, "")
+	}
+	return DefaultLanguage
+}
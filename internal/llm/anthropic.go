@@ -0,0 +1,129 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// AnthropicClient представляет провайдера для Anthropic Messages API
+// Структурированный вывод достигается через принудительный вызов инструмента,
+// входная схема которого совпадает с JSON схемой ответа
+type AnthropicClient struct {
+	apiKey      string
+	httpClient  *http.Client
+	retryPolicy RetryPolicy
+	baseURL     string
+	model       string
+}
+
+// NewAnthropicProvider создает провайдера Anthropic
+// baseURL может быть пустым - тогда используется официальный API
+func NewAnthropicProvider(apiKey, baseURL, model string) *AnthropicClient {
+	if baseURL == "" {
+		baseURL = AnthropicBaseURL
+	}
+	if model == "" {
+		model = DefaultAnthropicModel
+	}
+
+	return &AnthropicClient{
+		apiKey:      apiKey,
+		httpClient:  newHTTPClient(),
+		retryPolicy: DefaultRetryPolicy(),
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		model:       model,
+	}
+}
+
+// NewAnthropicClient создает клиент для Anthropic
+func NewAnthropicClient(apiKey, baseURL, model string) Client {
+	return NewPromptClient(NewAnthropicProvider(apiKey, baseURL, model))
+}
+
+// Name возвращает название провайдера
+func (c *AnthropicClient) Name() string {
+	return "Anthropic"
+}
+
+// Complete отправляет промпт в Anthropic Messages API
+func (c *AnthropicClient) Complete(ctx context.Context, prompt string, responseSchema map[string]any) (string, error) {
+	if c.apiKey == "" {
+		return "", fmt.Errorf("Anthropic API key is not set")
+	}
+
+	requestBody := map[string]any{
+		"model":       c.model,
+		"max_tokens":  DefaultMaxTokens,
+		"temperature": DefaultTemperature,
+		"system":      systemMessage(ctx, SystemMessageResponses),
+		"messages": []map[string]string{
+			{
+				"role":    "user",
+				"content": prompt,
+			},
+		},
+		"tools": []map[string]any{
+			{
+				"name":         SchemaName,
+				"description":  AnthropicToolDescription,
+				"input_schema": responseSchema,
+			},
+		},
+		"tool_choice": map[string]string{
+			"type": "tool",
+			"name": SchemaName,
+		},
+	}
+
+	headers := map[string]string{
+		"x-api-key":         c.apiKey,
+		"anthropic-version": AnthropicVersion,
+	}
+
+	body, err := postJSON(ctx, c.httpClient, c.retryPolicy, c.Name(), c.baseURL+AnthropicMessagesPath, headers, requestBody)
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf(LogRawAPIResponse, string(body))
+
+	var messagesResponse struct {
+		Content []struct {
+			Type  string          `json:"type"`
+			Text  string          `json:"text"`
+			Name  string          `json:"name"`
+			Input json.RawMessage `json:"input"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+	}
+
+	if err := json.Unmarshal(body, &messagesResponse); err != nil {
+		log.Printf(LogParseResponseError, err)
+		return "", fmt.Errorf("failed to parse Anthropic response: %w", err)
+	}
+
+	// Ищем вызов инструмента со структурированным ответом
+	var text strings.Builder
+	for _, block := range messagesResponse.Content {
+		switch block.Type {
+		case "tool_use":
+			content := string(block.Input)
+			log.Printf(LogContentReceived, content)
+			return content, nil
+		case "text":
+			text.WriteString(block.Text)
+		}
+	}
+
+	// Модель ответила текстом - пробуем извлечь JSON из него
+	if text.Len() > 0 {
+		return text.String(), nil
+	}
+
+	log.Printf(LogNoToolUse, c.Name())
+	return "", fmt.Errorf("no tool_use content in Anthropic response (stop_reason: %s)", messagesResponse.StopReason)
+}
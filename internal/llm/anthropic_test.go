@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAnthropicToolUse(t *testing.T) {
+	server, requests := newTestServer(t, testResponse{status: http.StatusOK, body: `{
+		"content": [
+			{"type": "text", "text": "Sure"},
+			{"type": "tool_use", "name": "goal_assistant_response", "input": {"status": "ok"}}
+		],
+		"stop_reason": "tool_use"
+	}`})
+
+	provider := NewAnthropicProvider("key", server.URL+"/v1/", "claude")
+	provider.retryPolicy = testRetryPolicy(1)
+
+	content, err := provider.Complete(context.Background(), "prompt", testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != `{"status": "ok"}` {
+		t.Errorf("content = %q", content)
+	}
+
+	request := requests()[0]
+	if request.Path != "/v1"+AnthropicMessagesPath {
+		t.Errorf("path = %q", request.Path)
+	}
+	if key := request.Header.Get("x-api-key"); key != "key" {
+		t.Errorf("x-api-key = %q", key)
+	}
+	if version := request.Header.Get("anthropic-version"); version != AnthropicVersion {
+		t.Errorf("anthropic-version = %q", version)
+	}
+	if model := jsonPath(t, request.Body, "model"); model != "claude" {
+		t.Errorf("model = %v", model)
+	}
+	if prompt := jsonPath(t, request.Body, "messages", 0, "content"); prompt != "prompt" {
+		t.Errorf("user prompt = %v", prompt)
+	}
+	if description := jsonPath(t, request.Body, "tools", 0, "description"); description != AnthropicToolDescription {
+		t.Errorf("tool description = %v", description)
+	}
+	if schema := jsonPath(t, request.Body, "tools", 0, "input_schema"); !reflect.DeepEqual(schema, testSchema) {
+		t.Errorf("input_schema = %v, want %v", schema, testSchema)
+	}
+	if choice := jsonPath(t, request.Body, "tool_choice", "name"); choice != SchemaName {
+		t.Errorf("tool_choice = %v", choice)
+	}
+}
+
+func TestAnthropicTextFallback(t *testing.T) {
+	server, _ := newTestServer(t, testResponse{status: http.StatusOK, body: `{
+		"content": [{"type": "text", "text": "{\"status\":\"ok\"}"}],
+		"stop_reason": "end_turn"
+	}`})
+	provider := NewAnthropicProvider("key", server.URL, "claude")
+	provider.retryPolicy = testRetryPolicy(1)
+
+	content, err := provider.Complete(context.Background(), "prompt", testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != `{"status":"ok"}` {
+		t.Errorf("content = %q", content)
+	}
+}
+
+func TestAnthropicErrors(t *testing.T) {
+	server, requests := newTestServer(t, testResponse{status: http.StatusBadRequest, body: `{"type":"error"}`})
+	provider := NewAnthropicProvider("key", server.URL, "claude")
+	provider.retryPolicy = testRetryPolicy(3)
+
+	_, err := provider.Complete(context.Background(), "prompt", testSchema)
+	assertAPIError(t, err, http.StatusBadRequest, false)
+	if n := len(requests()); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+
+	if _, err := NewAnthropicProvider("", server.URL, "claude").Complete(context.Background(), "prompt", testSchema); err == nil {
+		t.Error("expected error without API key")
+	}
+}
+
+func TestAnthropicEmptyContent(t *testing.T) {
+	server, _ := newTestServer(t, testResponse{status: http.StatusOK, body: `{"content": [], "stop_reason": "max_tokens"}`})
+	provider := NewAnthropicProvider("key", server.URL, "claude")
+	provider.retryPolicy = testRetryPolicy(1)
+
+	if _, err := provider.Complete(context.Background(), "prompt", testSchema); err == nil {
+		t.Fatal("expected error for response without content")
+	}
+}
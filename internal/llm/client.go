@@ -1,16 +1,25 @@
 package llm
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"goal-helper/internal/calibration"
 	"goal-helper/internal/models"
 )
 
 // Client представляет интерфейс для работы с LLM
+// Отмена контекста прерывает запрос к провайдеру (например, по команде /cancel)
 type Client interface {
-	GenerateStep(goal *models.Goal, completedSteps []*models.Step) (*StepResponse, error)
-	RephraseStep(goal *models.Goal, currentStep *models.Step, userComment string) (*StepResponse, error)
-	ClarifyGoal(goalTitle, goalDescription string) (*ClarificationResponse, error)
-	GenerateGoalTitle(description string) (string, error)
-	GatherContext(goal *models.Goal) (*ContextResponse, error)
+	GenerateStep(ctx context.Context, goal *models.Goal, completedSteps, skippedSteps []*models.Step, level calibration.Level) (*StepResponse, error)
+	RephraseStep(ctx context.Context, goal *models.Goal, currentStep *models.Step, userComment string) (*StepResponse, error)
+	ClarifyGoal(ctx context.Context, goalTitle, goalDescription string) (*ClarificationResponse, error)
+	GenerateGoalTitle(ctx context.Context, description string) (string, error)
+	GatherContext(ctx context.Context, goal *models.Goal) (*ContextResponse, error)
+	ClassifyGoal(ctx context.Context, goalTitle, goalDescription string) (string, error)
+	ProposeMilestones(ctx context.Context, goal *models.Goal, completedSteps []*models.Step) ([]string, error)
 }
 
 // StepResponse представляет ответ LLM на генерацию шага
@@ -19,6 +28,8 @@ type StepResponse struct {
 	Step             string `json:"step"`              // Текст шага (может быть пустым, если нужна дополнительная информация)
 	Question         string `json:"question"`          // Уточняющий вопрос (может быть пустым, если шаг понятен)
 	CompletionReason string `json:"completion_reason"` // Причина завершения цели (может быть пустым, если цель не завершена)
+
+	MilestoneCompleted bool `json:"milestone_completed"` // Текущий этап цели пройден, шаг относится уже к следующему
 }
 
 // ClarificationResponse представляет ответ LLM на уточнение цели
@@ -33,3 +44,46 @@ type ContextResponse struct {
 	Question string `json:"question"` // Вопрос для сбора контекста (может быть пустым, если контекст собран)
 	Context  string `json:"context"`  // Собранный контекст (может быть пустым, если нужен дополнительный контекст)
 }
+
+// ProviderConfig описывает выбор LLM провайдера
+type ProviderConfig struct {
+	Provider string // "openai" (по умолчанию), "openai_compatible", "anthropic", "ollama"
+	APIKey   string // API ключ (не нужен для локальных серверов)
+	Model    string // Модель (пусто - модель провайдера по умолчанию)
+	BaseURL  string // Базовый URL API, например прокси (пусто - адрес провайдера по умолчанию)
+}
+
+// NewClient создает клиент для провайдера, указанного в конфигурации
+func NewClient(config ProviderConfig) (Client, error) {
+	var provider Provider
+
+	switch config.Provider {
+	case "", ProviderOpenAI:
+		apiConfig := DefaultAPIConfig()
+		if config.Model != "" {
+			apiConfig.Model = config.Model
+		}
+		if config.BaseURL != "" {
+			// Прокси или собственный адрес OpenAI API: используется тот же Responses API
+			apiConfig.BaseURL = strings.TrimRight(config.BaseURL, "/") + ResponsesAPIPath
+		}
+		provider = NewOpenAIProvider(config.APIKey, apiConfig)
+	case ProviderOpenAICompatible:
+		if config.BaseURL == "" {
+			return nil, fmt.Errorf("base URL is required for %s provider", config.Provider)
+		}
+		if config.Model == "" {
+			return nil, fmt.Errorf("model is required for %s provider", config.Provider)
+		}
+		provider = NewOpenAIProvider(config.APIKey, CompatibleAPIConfig(config.BaseURL, config.Model))
+	case ProviderAnthropic:
+		provider = NewAnthropicProvider(config.APIKey, config.BaseURL, config.Model)
+	case ProviderOllama:
+		provider = NewOllamaProvider(config.BaseURL, config.Model)
+	default:
+		return nil, fmt.Errorf("unknown LLM provider: %s", config.Provider)
+	}
+
+	log.Printf(LogProviderSelected, provider.Name())
+	return NewPromptClient(provider), nil
+}
@@ -1,12 +1,16 @@
 package llm
 
+import "time"
+
 // Названия промптов (файлы без расширения .md)
 const (
-	PromptStepGeneration    = "step_generation"
-	PromptStepRephrase      = "step_rephrase"
-	PromptGoalClarification = "goal_clarification"
-	PromptTitleGeneration   = "title_generation"
-	PromptContextGathering  = "context_gathering"
+	PromptStepGeneration     = "step_generation"
+	PromptStepRephrase       = "step_rephrase"
+	PromptGoalClarification  = "goal_clarification"
+	PromptTitleGeneration    = "title_generation"
+	PromptContextGathering   = "context_gathering"
+	PromptGoalClassification = "goal_classification"
+	PromptMilestonePlanning  = "milestone_planning"
 )
 
 // Статусы ответов
@@ -20,26 +24,48 @@ const (
 
 // Плейсхолдеры для промптов
 const (
-	PlaceholderGoalTitle       = "goal_title"
-	PlaceholderGoalDescription = "goal_description"
-	PlaceholderUserContext     = "user_context"
-	PlaceholderCompletedSteps  = "completed_steps"
-	PlaceholderCurrentStep     = "current_step"
-	PlaceholderUserComment     = "user_comment"
-	PlaceholderDescription     = "description"
-	PlaceholderExistingContext = "existing_context"
+	PlaceholderGoalTitle        = "goal_title"
+	PlaceholderGoalDescription  = "goal_description"
+	PlaceholderUserContext      = "user_context"
+	PlaceholderCompletedSteps   = "completed_steps"
+	PlaceholderCurrentStep      = "current_step"
+	PlaceholderUserComment      = "user_comment"
+	PlaceholderDescription      = "description"
+	PlaceholderExistingContext  = "existing_context"
+	PlaceholderRejectedSteps    = "rejected_steps"
+	PlaceholderSkippedSteps     = "skipped_steps"
+	PlaceholderStepSize         = "step_size"
+	PlaceholderCategoryStrategy = "category_strategy"
+	PlaceholderMilestones       = "milestones"
+)
+
+// LLM провайдеры
+const (
+	ProviderOpenAI           = "openai"
+	ProviderOpenAICompatible = "openai_compatible"
+	ProviderAnthropic        = "anthropic"
+	ProviderOllama           = "ollama"
 )
 
 // API endpoints
 const (
-	ResponsesAPIEndpoint   = "https://api.openai.com/v1/responses"
-	CompletionsAPIEndpoint = "https://api.openai.com/v1/chat/completions"
+	OpenAIBaseURL          = "https://api.openai.com/v1"
+	ResponsesAPIPath       = "/responses"
+	CompletionsAPIPath     = "/chat/completions"
+	ResponsesAPIEndpoint   = OpenAIBaseURL + ResponsesAPIPath
+	CompletionsAPIEndpoint = OpenAIBaseURL + CompletionsAPIPath
+	AnthropicBaseURL       = "https://api.anthropic.com/v1"
+	AnthropicMessagesPath  = "/messages"
+	OllamaBaseURL          = "http://localhost:11434"
+	OllamaChatPath         = "/api/chat"
 )
 
 // Модели по умолчанию
 const (
-	DefaultModel     = "gpt-4o-mini-2024-07-18"
-	CompletionsModel = "gpt-4.1-nano-2025-04-14"
+	DefaultModel          = "gpt-4o-mini-2024-07-18"
+	CompletionsModel      = "gpt-4.1-nano-2025-04-14"
+	DefaultAnthropicModel = "claude-3-5-haiku-20241022"
+	DefaultOllamaModel    = "llama3.1"
 )
 
 // Настройки API
@@ -47,14 +73,26 @@ const (
 	DefaultTemperature = 0.7
 	DefaultMaxTokens   = 500
 	DefaultTimeout     = 30 // секунды
+	AnthropicVersion   = "2023-06-01"
+	SchemaName         = "goal_assistant_response" // Имя схемы/инструмента для структурированного вывода
+
+	// Описание инструмента Anthropic для структурированного вывода (не зависит от языка пользователя)
+	AnthropicToolDescription = "Return the answer strictly according to this schema"
+)
+
+// Настройки повторных попыток
+const (
+	DefaultRetryAttempts  = 3
+	DefaultRetryBaseDelay = time.Second
+	DefaultRetryMaxDelay  = 30 * time.Second
 )
 
 // Сообщения для логирования
 const (
-	LogSendingRequest          = "🔍 Отправляем запрос к OpenAI для цели: %s"
+	LogSendingRequest          = "🔍 Отправляем запрос к %s для цели: %s"
 	LogPromptLength            = "🔍 Длина промпта: %d символов"
-	LogOpenAIError             = "❌ Ошибка при вызове OpenAI: %v"
-	LogOpenAIResponse          = "🔍 Получен ответ от OpenAI: %s"
+	LogLLMError                = "❌ Ошибка при вызове LLM: %v"
+	LogLLMResponse             = "🔍 Получен ответ от LLM: %s"
 	LogParsingError            = "❌ Ошибка при парсинге ответа OpenAI: %v"
 	LogRawResponse             = "🔍 Сырой ответ: %s"
 	LogJSONParsingAttempt      = "🔍 Попытка парсинга найденного JSON: %s"
@@ -74,7 +112,7 @@ const (
 	LogAPIKeyMissing           = "❌ OpenAI API ключ не установлен"
 	LogSendingHTTPRequest      = "🔍 Отправляем HTTP запрос к %s"
 	LogHTTPResponse            = "🔍 Получен HTTP ответ: статус %d"
-	LogAPIError                = "❌ OpenAI API вернул ошибку: %s - %s"
+	LogAPIError                = "❌ %s API вернул ошибку: %s - %s"
 	LogResponseSize            = "🔍 Размер ответа: %d байт"
 	LogRawResponseBody         = "🔍 Сырое тело ответа: %s"
 	LogNoChoices               = "❌ OpenAI вернул пустой список choices"
@@ -105,19 +143,84 @@ const (
 	LogAltResponseModel        = "  - Model: %s"
 	LogAltResponseChoicesCount = "  - Choices count: %d"
 	LogEmptyAltChoices         = "❌ Пустой список choices в альтернативной структуре"
+	LogProviderSelected        = "🔍 LLM провайдер: %s"
+	LogRetrying                = "⚠️ %s: попытка %d/%d не удалась, повтор через %s: %v"
+	LogFallback                = "⚠️ %s: клиент %d/%d не справился, пробуем следующий: %v"
+	LogNoToolUse               = "❌ %s не вернул вызов инструмента со структурированным ответом"
+	LogClassification          = "🔍 Определяем категорию цели: %s"
+	LogClassificationError     = "❌ Ошибка при классификации цели: %v"
+	LogClassificationSuccess   = "🔍 Категория цели: %s"
+	LogUnknownCategory         = "⚠️ LLM вернула неизвестную категорию цели %q, используем %q"
+	LogMilestonePlanning       = "🔍 Составляем этапы для цели: %s"
+	LogMilestonePlanningError  = "❌ Ошибка при составлении этапов: %v"
+	LogMilestonePlanningResult = "🔍 Предложено этапов: %d"
 )
 
-// Системные сообщения для промптов
+// Ключи системных сообщений для промптов
+// Тексты фрагментов промптов лежат в каталогах internal/i18n/locales
 const (
-	SystemMessageResponses   = "Ты помощник для достижения целей. Всегда отвечай в соответствии с указанной JSON схемой."
-	SystemMessageCompletions = "Ты помощник для достижения целей. Всегда отвечай в формате JSON."
+	SystemMessageResponses   = "prompt_system_responses"
+	SystemMessageCompletions = "prompt_system_completions"
 )
 
 // Форматы для форматирования строк
 const (
-	FormatDescription   = "Описание: %s"
-	FormatClarification = "%d. %s\n"
-	FormatStep          = "%d. %s\n"
+	FormatClarificationAnswer = "%d. %s\n"
+	FormatStep                = "%d. %s\n"
+)
+
+// Ключи форматов фрагментов промптов
+const (
+	FormatDescription     = "prompt_description"
+	FormatClarification   = "prompt_clarification"
+	FormatSkippedStep     = "prompt_skipped_step"
+	FormatStepFeedback    = "prompt_step_feedback"
+	FormatStepNote        = "prompt_step_note"
+	FormatNoRejectedSteps = "prompt_no_rejected_steps"
+	FormatNoSkippedSteps  = "prompt_no_skipped_steps"
+)
+
+// Ключи форматов этапов цели для промптов
+const (
+	FormatNoMilestones      = "prompt_no_milestones"
+	FormatMilestoneDone     = "prompt_milestone_done"
+	FormatMilestoneCurrent  = "prompt_milestone_current"
+	FormatMilestoneUpcoming = "prompt_milestone_upcoming"
+)
+
+// Ключи оценок сложности выполненных шагов для промптов
+const (
+	DifficultyTooEasy = "prompt_difficulty_too_easy"
+	DifficultyRight   = "prompt_difficulty_right"
+	DifficultyTooHard = "prompt_difficulty_too_hard"
+)
+
+// Ключи размеров шагов для промпта генерации (по уровню калибровки)
+const (
+	StepSizeTiny   = "prompt_step_size_tiny"
+	StepSizeSmall  = "prompt_step_size_small"
+	StepSizeNormal = "prompt_step_size_normal"
+	StepSizeLarge  = "prompt_step_size_large"
+	StepSizeHuge   = "prompt_step_size_huge"
+)
+
+// Ключи правил размера шага для категорий целей (дополняют размер по уровню калибровки)
+const (
+	StepSizeRuleCreative    = "prompt_step_size_rule_creative"
+	StepSizeRuleEducational = "prompt_step_size_rule_educational"
+	StepSizeRuleHousehold   = "prompt_step_size_rule_household"
+	StepSizeRuleHealth      = "prompt_step_size_rule_health"
+	StepSizeRuleCareer      = "prompt_step_size_rule_career"
+)
+
+// Ключи стратегий генерации шагов для категорий целей
+const (
+	CategoryStrategyCreative    = "prompt_category_strategy_creative"
+	CategoryStrategyEducational = "prompt_category_strategy_educational"
+	CategoryStrategyHousehold   = "prompt_category_strategy_household"
+	CategoryStrategyHealth      = "prompt_category_strategy_health"
+	CategoryStrategyCareer      = "prompt_category_strategy_career"
+	CategoryStrategyOther       = "prompt_category_strategy_other"
 )
 
 // JSON ключи
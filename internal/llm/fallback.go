@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"goal-helper/internal/calibration"
+	"goal-helper/internal/models"
+)
+
+// FallbackClient декоратор над цепочкой клиентов: если клиент не справился
+// (после своих повторных попыток), запрос передается следующему.
+// Например: основная модель -> более дешевая модель -> другой провайдер
+type FallbackClient struct {
+	clients []Client
+}
+
+// NewFallbackClient создает клиент с цепочкой резервных клиентов
+// Если передан один клиент, он возвращается как есть
+func NewFallbackClient(clients ...Client) Client {
+	if len(clients) == 1 {
+		return clients[0]
+	}
+	return &FallbackClient{clients: clients}
+}
+
+// withFallback вызывает call для клиентов по очереди до первого успеха
+func withFallback[T any](ctx context.Context, c *FallbackClient, operation string, call func(Client) (T, error)) (T, error) {
+	var zero T
+	var errs []error
+
+	for i, client := range c.clients {
+		result, err := call(client)
+		if err == nil {
+			return result, nil
+		}
+
+		errs = append(errs, err)
+
+		// Запрос отменен - резервные клиенты не помогут
+		if ctx.Err() != nil {
+			return zero, err
+		}
+
+		if i < len(c.clients)-1 {
+			log.Printf(LogFallback, operation, i+1, len(c.clients), err)
+		}
+	}
+
+	return zero, fmt.Errorf("all %d LLM clients failed: %w", len(c.clients), errors.Join(errs...))
+}
+
+// GenerateStep генерирует следующий шаг для цели
+func (c *FallbackClient) GenerateStep(ctx context.Context, goal *models.Goal, completedSteps, skippedSteps []*models.Step, level calibration.Level) (*StepResponse, error) {
+	return withFallback(ctx, c, "генерация шага", func(client Client) (*StepResponse, error) {
+		return client.GenerateStep(ctx, goal, completedSteps, skippedSteps, level)
+	})
+}
+
+// RephraseStep переформулирует текущий шаг
+func (c *FallbackClient) RephraseStep(ctx context.Context, goal *models.Goal, currentStep *models.Step, userComment string) (*StepResponse, error) {
+	return withFallback(ctx, c, "переформулировка шага", func(client Client) (*StepResponse, error) {
+		return client.RephraseStep(ctx, goal, currentStep, userComment)
+	})
+}
+
+// ClarifyGoal запрашивает уточнение цели
+func (c *FallbackClient) ClarifyGoal(ctx context.Context, goalTitle, goalDescription string) (*ClarificationResponse, error) {
+	return withFallback(ctx, c, "уточнение цели", func(client Client) (*ClarificationResponse, error) {
+		return client.ClarifyGoal(ctx, goalTitle, goalDescription)
+	})
+}
+
+// GenerateGoalTitle генерирует название цели на основе описания
+func (c *FallbackClient) GenerateGoalTitle(ctx context.Context, description string) (string, error) {
+	return withFallback(ctx, c, "генерация названия цели", func(client Client) (string, error) {
+		return client.GenerateGoalTitle(ctx, description)
+	})
+}
+
+// GatherContext собирает контекст пользователя
+func (c *FallbackClient) GatherContext(ctx context.Context, goal *models.Goal) (*ContextResponse, error) {
+	return withFallback(ctx, c, "сбор контекста", func(client Client) (*ContextResponse, error) {
+		return client.GatherContext(ctx, goal)
+	})
+}
+
+// ClassifyGoal определяет категорию цели
+func (c *FallbackClient) ClassifyGoal(ctx context.Context, goalTitle, goalDescription string) (string, error) {
+	return withFallback(ctx, c, "классификация цели", func(client Client) (string, error) {
+		return client.ClassifyGoal(ctx, goalTitle, goalDescription)
+	})
+}
+
+// ProposeMilestones предлагает этапы для большой цели
+func (c *FallbackClient) ProposeMilestones(ctx context.Context, goal *models.Goal, completedSteps []*models.Step) ([]string, error) {
+	return withFallback(ctx, c, "составление этапов", func(client Client) ([]string, error) {
+		return client.ProposeMilestones(ctx, goal, completedSteps)
+	})
+}
@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"goal-helper/internal/calibration"
+	"goal-helper/internal/models"
+)
+
+// fakeClient клиент, который записывает вызовы и возвращает заданную ошибку
+type fakeClient struct {
+	name  string
+	err   error
+	calls *[]string
+}
+
+func (c *fakeClient) call() error {
+	*c.calls = append(*c.calls, c.name)
+	return c.err
+}
+
+func (c *fakeClient) GenerateStep(ctx context.Context, goal *models.Goal, completedSteps, skippedSteps []*models.Step, level calibration.Level) (*StepResponse, error) {
+	if err := c.call(); err != nil {
+		return nil, err
+	}
+	return &StepResponse{Status: "ok", Step: c.name}, nil
+}
+
+func (c *fakeClient) RephraseStep(ctx context.Context, goal *models.Goal, currentStep *models.Step, userComment string) (*StepResponse, error) {
+	if err := c.call(); err != nil {
+		return nil, err
+	}
+	return &StepResponse{Status: "ok", Step: c.name}, nil
+}
+
+func (c *fakeClient) ClarifyGoal(ctx context.Context, goalTitle, goalDescription string) (*ClarificationResponse, error) {
+	if err := c.call(); err != nil {
+		return nil, err
+	}
+	return &ClarificationResponse{Question: c.name}, nil
+}
+
+func (c *fakeClient) GenerateGoalTitle(ctx context.Context, description string) (string, error) {
+	if err := c.call(); err != nil {
+		return "", err
+	}
+	return c.name, nil
+}
+
+func (c *fakeClient) GatherContext(ctx context.Context, goal *models.Goal) (*ContextResponse, error) {
+	if err := c.call(); err != nil {
+		return nil, err
+	}
+	return &ContextResponse{Context: c.name}, nil
+}
+
+func (c *fakeClient) ClassifyGoal(ctx context.Context, goalTitle, goalDescription string) (string, error) {
+	if err := c.call(); err != nil {
+		return "", err
+	}
+	return c.name, nil
+}
+
+func (c *fakeClient) ProposeMilestones(ctx context.Context, goal *models.Goal, completedSteps []*models.Step) ([]string, error) {
+	if err := c.call(); err != nil {
+		return nil, err
+	}
+	return []string{c.name}, nil
+}
+
+func TestFallbackClientOrder(t *testing.T) {
+	errPrimary := errors.New("primary failed")
+	errSecondary := errors.New("secondary failed")
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls []string
+		wantTitle string
+		wantErrs  []error
+	}{
+		{"first succeeds", []error{nil, nil, nil}, []string{"primary"}, "primary", nil},
+		{"falls back in order", []error{errPrimary, nil, nil}, []string{"primary", "secondary"}, "secondary", nil},
+		{"uses last", []error{errPrimary, errSecondary, nil}, []string{"primary", "secondary", "tertiary"}, "tertiary", nil},
+		{"all fail", []error{errPrimary, errSecondary, errSecondary}, []string{"primary", "secondary", "tertiary"}, "", []error{errPrimary, errSecondary}},
+	}
+
+	names := []string{"primary", "secondary", "tertiary"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			var clients []Client
+			for i, name := range names {
+				clients = append(clients, &fakeClient{name: name, err: tt.errs[i], calls: &calls})
+			}
+
+			title, err := NewFallbackClient(clients...).GenerateGoalTitle(context.Background(), "description")
+			if title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", title, tt.wantTitle)
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("error %v does not wrap %v", err, want)
+				}
+			}
+			if tt.wantErrs == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if len(calls) != len(tt.wantCalls) {
+				t.Fatalf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			for i := range calls {
+				if calls[i] != tt.wantCalls[i] {
+					t.Fatalf("calls = %v, want %v", calls, tt.wantCalls)
+				}
+			}
+		})
+	}
+}
+
+func TestFallbackClientStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls []string
+	client := NewFallbackClient(
+		&fakeClient{name: "primary", err: context.Canceled, calls: &calls},
+		&fakeClient{name: "secondary", calls: &calls},
+	)
+
+	if _, err := client.GenerateStep(ctx, &models.Goal{}, nil, nil, calibration.LevelNormal); !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if len(calls) != 1 {
+		t.Errorf("calls = %v, want only the primary client", calls)
+	}
+}
+
+func TestNewFallbackClientSingle(t *testing.T) {
+	var calls []string
+	single := &fakeClient{name: "only", calls: &calls}
+	if client := NewFallbackClient(single); client != single {
+		t.Errorf("single client should be returned as is, got %T", client)
+	}
+}
@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// APIError представляет ошибку, которую вернул HTTP API провайдера
+type APIError struct {
+	Provider   string        // Название провайдера
+	StatusCode int           // HTTP статус
+	Status     string        // HTTP статус в текстовом виде
+	Body       string        // Тело ответа
+	RetryAfter time.Duration // Значение заголовка Retry-After (0, если не задан)
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API error: %s - %s", e.Provider, e.Status, e.Body)
+}
+
+// newHTTPClient создает HTTP клиент с таймаутом по умолчанию
+func newHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: DefaultTimeout * time.Second,
+	}
+}
+
+// postJSON отправляет POST запрос с JSON телом и возвращает тело успешного ответа.
+// Временные ошибки (429, 5xx, таймауты) повторяются согласно политике повторов.
+// Ответ с кодом, отличным от 200, возвращается как *APIError
+func postJSON(ctx context.Context, httpClient *http.Client, policy RetryPolicy, provider, url string, headers map[string]string, payload any) ([]byte, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf(LogMarshalingError, err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	log.Printf(LogRequestBody, string(jsonData))
+
+	for attempt := 1; ; attempt++ {
+		body, err := doPostJSON(ctx, httpClient, provider, url, headers, jsonData)
+		if err == nil {
+			return body, nil
+		}
+
+		if ctx.Err() != nil || !IsRetryable(err) || attempt >= policy.MaxAttempts {
+			return nil, err
+		}
+
+		delay, ok := policy.delay(attempt, err)
+		if !ok {
+			return nil, err
+		}
+
+		log.Printf(LogRetrying, provider, attempt, policy.MaxAttempts, delay, err)
+
+		// Ждем перед повтором, но прерываемся при отмене запроса
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// doPostJSON выполняет одну попытку HTTP запроса
+func doPostJSON(ctx context.Context, httpClient *http.Client, provider, url string, headers map[string]string, jsonData []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonData))
+	if err != nil {
+		log.Printf(LogRequestError, err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	log.Printf(LogSendingHTTPRequest, url)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		log.Printf(LogHTTPRequestError, err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	log.Printf(LogHTTPResponse, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf(LogReadResponseError, err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf(LogAPIError, provider, resp.Status, string(body))
+		return nil, &APIError{
+			Provider:   provider,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       string(body),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	log.Printf(LogResponseSize, len(body))
+	return body, nil
+}
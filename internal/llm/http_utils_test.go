@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testSchema схема ответа, которую тесты передают провайдерам
+var testSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"status": map[string]any{"type": "string"},
+	},
+	"required": []any{"status"},
+}
+
+// recordedRequest запрос, который получил тестовый сервер
+type recordedRequest struct {
+	Path   string
+	Header http.Header
+	Body   map[string]any
+}
+
+// newTestServer запускает сервер, который записывает запросы и отвечает по очереди
+// ответами responses (последний ответ повторяется). Возвращает функцию для получения записанных запросов
+func newTestServer(t *testing.T, responses ...testResponse) (*httptest.Server, func() []recordedRequest) {
+	t.Helper()
+
+	var mutex sync.Mutex
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read request: %v", err)
+		}
+		var body map[string]any
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		requests = append(requests, recordedRequest{Path: r.URL.Path, Header: r.Header.Clone(), Body: body})
+
+		response := responses[min(len(requests), len(responses))-1]
+		for key, value := range response.header {
+			w.Header().Set(key, value)
+		}
+		w.WriteHeader(response.status)
+		io.WriteString(w, response.body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []recordedRequest {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]recordedRequest(nil), requests...)
+	}
+}
+
+// testResponse ответ тестового сервера
+type testResponse struct {
+	status int
+	body   string
+	header map[string]string
+}
+
+// testRetryPolicy политика повторов без долгих задержек
+func testRetryPolicy(attempts int) RetryPolicy {
+	return RetryPolicy{MaxAttempts: attempts, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+}
+
+// jsonPath достает значение из разобранного JSON по цепочке ключей и индексов
+func jsonPath(t *testing.T, v any, path ...any) any {
+	t.Helper()
+	for _, key := range path {
+		switch k := key.(type) {
+		case string:
+			m, ok := v.(map[string]any)
+			if !ok {
+				t.Fatalf("%v: not an object at %q", path, k)
+			}
+			v = m[k]
+		case int:
+			a, ok := v.([]any)
+			if !ok || k >= len(a) {
+				t.Fatalf("%v: no element %d", path, k)
+			}
+			v = a[k]
+		}
+	}
+	return v
+}
+
+// assertAPIError проверяет, что ошибка - *APIError с нужным статусом
+func assertAPIError(t *testing.T, err error, status int, retryable bool) {
+	t.Helper()
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("error = %v (%T), want *APIError", err, err)
+	}
+	if apiErr.StatusCode != status {
+		t.Errorf("status = %d, want %d", apiErr.StatusCode, status)
+	}
+	if IsRetryable(err) != retryable {
+		t.Errorf("IsRetryable = %v, want %v", !retryable, retryable)
+	}
+}
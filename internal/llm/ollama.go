@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// OllamaClient представляет провайдера для локального сервера Ollama
+// Для llama.cpp server используйте OpenAI-совместимый провайдер
+type OllamaClient struct {
+	httpClient  *http.Client
+	retryPolicy RetryPolicy
+	baseURL     string
+	model       string
+}
+
+// NewOllamaProvider создает провайдера Ollama
+// baseURL может быть пустым - тогда используется http://localhost:11434
+func NewOllamaProvider(baseURL, model string) *OllamaClient {
+	if baseURL == "" {
+		baseURL = OllamaBaseURL
+	}
+	if model == "" {
+		model = DefaultOllamaModel
+	}
+
+	return &OllamaClient{
+		httpClient:  newHTTPClient(),
+		retryPolicy: DefaultRetryPolicy(),
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		model:       model,
+	}
+}
+
+// NewOllamaClient создает клиент для Ollama
+func NewOllamaClient(baseURL, model string) Client {
+	return NewPromptClient(NewOllamaProvider(baseURL, model))
+}
+
+// Name возвращает название провайдера
+func (c *OllamaClient) Name() string {
+	return "Ollama"
+}
+
+// Complete отправляет промпт в Ollama Chat API
+// JSON схема передается в поле format, и Ollama ограничивает вывод модели этой схемой
+func (c *OllamaClient) Complete(ctx context.Context, prompt string, responseSchema map[string]any) (string, error) {
+	requestBody := map[string]any{
+		"model": c.model,
+		"messages": []map[string]string{
+			{
+				"role":    "system",
+				"content": systemMessage(ctx, SystemMessageResponses),
+			},
+			{
+				"role":    "user",
+				"content": prompt,
+			},
+		},
+		"stream": false,
+		"format": responseSchema,
+		"options": map[string]any{
+			"temperature": DefaultTemperature,
+		},
+	}
+
+	body, err := postJSON(ctx, c.httpClient, c.retryPolicy, c.Name(), c.baseURL+OllamaChatPath, nil, requestBody)
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf(LogRawAPIResponse, string(body))
+
+	var chatResponse struct {
+		Model   string `json:"model"`
+		Message struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"message"`
+		Done bool `json:"done"`
+	}
+
+	if err := json.Unmarshal(body, &chatResponse); err != nil {
+		log.Printf(LogParseResponseError, err)
+		return "", fmt.Errorf("failed to parse Ollama response: %w", err)
+	}
+
+	if chatResponse.Message.Content == "" {
+		return "", fmt.Errorf("empty content in Ollama response")
+	}
+
+	log.Printf(LogContentReceived, chatResponse.Message.Content)
+	return chatResponse.Message.Content, nil
+}
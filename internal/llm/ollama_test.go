@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestOllamaChat(t *testing.T) {
+	server, requests := newTestServer(t, testResponse{status: http.StatusOK, body: `{
+		"model": "llama",
+		"message": {"role": "assistant", "content": "{\"status\":\"ok\"}"},
+		"done": true
+	}`})
+
+	provider := NewOllamaProvider(server.URL+"/", "llama")
+	provider.retryPolicy = testRetryPolicy(1)
+
+	content, err := provider.Complete(context.Background(), "prompt", testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != `{"status":"ok"}` {
+		t.Errorf("content = %q", content)
+	}
+
+	request := requests()[0]
+	if request.Path != OllamaChatPath {
+		t.Errorf("path = %q", request.Path)
+	}
+	if model := jsonPath(t, request.Body, "model"); model != "llama" {
+		t.Errorf("model = %v", model)
+	}
+	if stream := jsonPath(t, request.Body, "stream"); stream != false {
+		t.Errorf("stream = %v, want false", stream)
+	}
+	if prompt := jsonPath(t, request.Body, "messages", 1, "content"); prompt != "prompt" {
+		t.Errorf("user prompt = %v", prompt)
+	}
+	if format := jsonPath(t, request.Body, "format"); !reflect.DeepEqual(format, testSchema) {
+		t.Errorf("format = %v, want %v", format, testSchema)
+	}
+}
+
+func TestOllamaErrors(t *testing.T) {
+	server, requests := newTestServer(t, testResponse{status: http.StatusNotFound, body: `{"error":"model not found"}`})
+	provider := NewOllamaProvider(server.URL, "missing")
+	provider.retryPolicy = testRetryPolicy(3)
+
+	_, err := provider.Complete(context.Background(), "prompt", testSchema)
+	assertAPIError(t, err, http.StatusNotFound, false)
+	if n := len(requests()); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+}
+
+func TestOllamaEmptyContent(t *testing.T) {
+	server, _ := newTestServer(t, testResponse{status: http.StatusOK, body: `{"message": {"role": "assistant", "content": ""}, "done": true}`})
+	provider := NewOllamaProvider(server.URL, "llama")
+	provider.retryPolicy = testRetryPolicy(1)
+
+	if _, err := provider.Complete(context.Background(), "prompt", testSchema); err == nil {
+		t.Fatal("expected error for empty content")
+	}
+}
@@ -1,31 +1,29 @@
 package llm
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
-	"time"
-
-	"goal-helper/internal/models"
+	"strings"
 )
 
-// OpenAIClient представляет клиент для работы с OpenAI API
+// OpenAIClient представляет провайдера для OpenAI API и любых OpenAI-совместимых серверов
+// (vLLM, llama.cpp server, LM Studio и т.д.)
 type OpenAIClient struct {
-	apiKey       string
-	httpClient   *http.Client
-	baseURL      string
-	model        string
-	promptLoader *PromptLoader // Загрузчик промптов из файлов
-	promptUtils  *PromptUtils  // Утилиты для подготовки плейсхолдеров
+	name        string // Название для логов и ошибок
+	apiKey      string
+	httpClient  *http.Client
+	retryPolicy RetryPolicy
+	baseURL     string
+	model       string
 }
 
 // APIConfig представляет конфигурацию для API запроса
 type APIConfig struct {
 	Model   string // Модель для использования
-	BaseURL string // Базовый URL (responses или completions)
+	BaseURL string // Полный URL endpoint (responses или chat/completions)
 }
 
 // DefaultAPIConfig возвращает конфигурацию по умолчанию
@@ -44,25 +42,42 @@ func CompletionsAPIConfig() APIConfig {
 	}
 }
 
+// CompatibleAPIConfig возвращает конфигурацию для OpenAI-совместимого сервера
+// baseURL - базовый адрес API (например, http://localhost:8080/v1)
+func CompatibleAPIConfig(baseURL, model string) APIConfig {
+	return APIConfig{
+		Model:   model,
+		BaseURL: strings.TrimRight(baseURL, "/") + CompletionsAPIPath,
+	}
+}
+
 // NewOpenAIClient создает новый OpenAI клиент
 func NewOpenAIClient(apiKey string) Client {
 	return NewOpenAIClientWithResponsesAPI(apiKey)
 }
 
-// NewOpenAIClientWithConfig создает новый OpenAI клиент с кастомной конфигурацией
-func NewOpenAIClientWithConfig(apiKey string, config APIConfig) Client {
+// NewOpenAIProvider создает провайдера OpenAI с кастомной конфигурацией
+func NewOpenAIProvider(apiKey string, config APIConfig) *OpenAIClient {
+	name := "OpenAI"
+	if !strings.HasPrefix(config.BaseURL, OpenAIBaseURL) {
+		name = "OpenAI-compatible"
+	}
+
 	return &OpenAIClient{
-		apiKey: apiKey,
-		httpClient: &http.Client{
-			Timeout: DefaultTimeout * time.Second,
-		},
-		baseURL:      config.BaseURL,
-		model:        config.Model,
-		promptLoader: NewPromptLoader(),
-		promptUtils:  NewPromptUtils(),
+		name:        name,
+		apiKey:      apiKey,
+		httpClient:  newHTTPClient(),
+		retryPolicy: DefaultRetryPolicy(),
+		baseURL:     config.BaseURL,
+		model:       config.Model,
 	}
 }
 
+// NewOpenAIClientWithConfig создает новый OpenAI клиент с кастомной конфигурацией
+func NewOpenAIClientWithConfig(apiKey string, config APIConfig) Client {
+	return NewPromptClient(NewOpenAIProvider(apiKey, config))
+}
+
 // NewOpenAIClientWithCompletionsAPI создает клиент для работы со старым Completions API
 func NewOpenAIClientWithCompletionsAPI(apiKey string) Client {
 	return NewOpenAIClientWithConfig(apiKey, CompletionsAPIConfig())
@@ -73,155 +88,25 @@ func NewOpenAIClientWithResponsesAPI(apiKey string) Client {
 	return NewOpenAIClientWithConfig(apiKey, DefaultAPIConfig())
 }
 
-// GenerateStepWithConfig генерирует следующий шаг для цели с кастомной конфигурацией
-func (c *OpenAIClient) GenerateStepWithConfig(goal *models.Goal, completedSteps []*models.Step, config APIConfig) (*StepResponse, error) {
-	// Загружаем промпт из файла
-	placeholders := c.promptUtils.BuildStepPromptPlaceholders(goal, completedSteps)
-	prompt, err := c.promptLoader.LoadPrompt(PromptStepGeneration, placeholders)
-	if err != nil {
-		log.Printf(LogPromptLoadError, err)
-		return nil, fmt.Errorf("failed to load prompt: %w", err)
-	}
-
-	log.Printf(LogSendingRequest, goal.Title)
-	log.Printf(LogPromptLength, len(prompt))
-
-	response, err := c.callOpenAI(prompt, config, StepResponseSchema)
-	if err != nil {
-		log.Printf(LogOpenAIError, err)
-		return nil, fmt.Errorf("failed to call OpenAI: %w", err)
-	}
-
-	log.Printf(LogOpenAIResponse, response)
-
-	var stepResponse StepResponse
-	if err := UnmarshalLLMResponseWithLogging(response, &stepResponse, "генерация шага"); err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
-	}
-
-	log.Printf(LogSuccessResponse, stepResponse.Status)
-	return &stepResponse, nil
+// Name возвращает название провайдера
+func (c *OpenAIClient) Name() string {
+	return c.name
 }
 
-// GenerateStep генерирует следующий шаг для цели
-func (c *OpenAIClient) GenerateStep(goal *models.Goal, completedSteps []*models.Step) (*StepResponse, error) {
-	return c.GenerateStepWithConfig(goal, completedSteps, DefaultAPIConfig())
+// Complete отправляет промпт в OpenAI API с конфигурацией клиента
+func (c *OpenAIClient) Complete(ctx context.Context, prompt string, responseSchema map[string]any) (string, error) {
+	return c.callOpenAI(ctx, prompt, APIConfig{Model: c.model, BaseURL: c.baseURL}, responseSchema)
 }
 
-// RephraseStep переформулирует текущий шаг
-func (c *OpenAIClient) RephraseStep(goal *models.Goal, currentStep *models.Step, userComment string) (*StepResponse, error) {
-	// Загружаем промпт из файла
-	placeholders := c.promptUtils.BuildRephrasePromptPlaceholders(goal, currentStep, userComment)
-
-	prompt, err := c.promptLoader.LoadPrompt(PromptStepRephrase, placeholders)
-	if err != nil {
-		log.Printf(LogPromptLoadError, err)
-		return nil, fmt.Errorf("failed to load prompt: %w", err)
-	}
-
-	response, err := c.callOpenAI(prompt, DefaultAPIConfig(), RephraseResponseSchema)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call OpenAI: %w", err)
-	}
-
-	var stepResponse StepResponse
-	if err := UnmarshalLLMResponseWithLogging(response, &stepResponse, "переформулировка шага"); err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
-	}
-
-	return &stepResponse, nil
-}
-
-// ClarifyGoal запрашивает уточнение цели
-func (c *OpenAIClient) ClarifyGoal(goalTitle, goalDescription string) (*ClarificationResponse, error) {
-	// Загружаем промпт из файла
-	placeholders := c.promptUtils.BuildClarificationPromptPlaceholders(goalTitle, goalDescription)
-
-	prompt, err := c.promptLoader.LoadPrompt(PromptGoalClarification, placeholders)
-	if err != nil {
-		log.Printf(LogPromptLoadError, err)
-		return nil, fmt.Errorf("failed to load prompt: %w", err)
-	}
-
-	response, err := c.callOpenAI(prompt, DefaultAPIConfig(), ClarificationResponseSchema)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call OpenAI: %w", err)
-	}
-
-	var clarificationResponse ClarificationResponse
-	if err := UnmarshalLLMResponseWithLogging(response, &clarificationResponse, "уточнение цели"); err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
-	}
-
-	return &clarificationResponse, nil
-}
-
-// GenerateGoalTitle генерирует название цели на основе описания
-func (c *OpenAIClient) GenerateGoalTitle(description string) (string, error) {
-	// Загружаем промпт из файла
-	placeholders := c.promptUtils.BuildTitlePromptPlaceholders(description)
-
-	prompt, err := c.promptLoader.LoadPrompt(PromptTitleGeneration, placeholders)
-	if err != nil {
-		log.Printf(LogPromptLoadError, err)
-		return "", fmt.Errorf("failed to load prompt: %w", err)
-	}
-
-	log.Printf(LogTitleGeneration, description)
-	log.Printf(LogTitlePrompt, prompt)
-
-	response, err := c.callOpenAI(prompt, DefaultAPIConfig(), TitleResponseSchema)
-	if err != nil {
-		log.Printf(LogTitleError, err)
-		return "", fmt.Errorf("failed to call OpenAI: %w", err)
-	}
-
-	log.Printf(LogTitleResponse, response)
-
-	var titleResponse struct {
-		Title string `json:"title"`
-	}
-	if err := UnmarshalLLMResponseWithLogging(response, &titleResponse, "генерация названия цели"); err != nil {
-		return "", fmt.Errorf("failed to parse OpenAI response: %w", err)
-	}
-
-	log.Printf(LogTitleSuccess, titleResponse.Title)
-	return titleResponse.Title, nil
-}
-
-// GatherContext собирает контекст пользователя для более точной генерации шагов
-func (c *OpenAIClient) GatherContext(goal *models.Goal) (*ContextResponse, error) {
-	// Загружаем промпт из файла
-	placeholders := c.promptUtils.BuildContextPromptPlaceholders(goal)
-	prompt, err := c.promptLoader.LoadPrompt(PromptContextGathering, placeholders)
-	if err != nil {
-		log.Printf(LogPromptLoadError, err)
-		return nil, fmt.Errorf("failed to load prompt: %w", err)
-	}
-
-	log.Printf(LogContextGathering, goal.Title)
-
-	response, err := c.callOpenAI(prompt, DefaultAPIConfig(), ContextResponseSchema)
-	if err != nil {
-		log.Printf(LogContextError, err)
-		return nil, fmt.Errorf("failed to call OpenAI: %w", err)
-	}
-
-	log.Printf(LogContextResponse, response)
-
-	var contextResponse ContextResponse
-	if err := UnmarshalLLMResponseWithLogging(response, &contextResponse, "сбор контекста"); err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
-	}
-
-	log.Printf(LogContextSuccess, contextResponse.Status)
-	return &contextResponse, nil
+// isResponsesAPI проверяет, указывает ли URL на новый Responses API
+func isResponsesAPI(url string) bool {
+	return strings.HasSuffix(url, ResponsesAPIPath)
 }
 
 // callOpenAI отправляет запрос к OpenAI API с поддержкой нового Responses API
-func (c *OpenAIClient) callOpenAI(prompt string, config APIConfig, responseSchema map[string]any) (string, error) {
-	// Проверяем, что API ключ установлен
-	if c.apiKey == "" {
+func (c *OpenAIClient) callOpenAI(ctx context.Context, prompt string, config APIConfig, responseSchema map[string]any) (string, error) {
+	// Проверяем, что API ключ установлен (локальным OpenAI-совместимым серверам он не нужен)
+	if c.apiKey == "" && strings.HasPrefix(config.BaseURL, OpenAIBaseURL) {
 		log.Printf(LogAPIKeyMissing)
 		return "", fmt.Errorf("OpenAI API key is not set")
 	}
@@ -231,14 +116,14 @@ func (c *OpenAIClient) callOpenAI(prompt string, config APIConfig, responseSchem
 	// Определяем, какой API использовать
 	var requestBody map[string]any
 
-	if config.BaseURL == ResponsesAPIEndpoint {
+	if isResponsesAPI(config.BaseURL) {
 		// Новый Responses API с JSON Schema
 		requestBody = map[string]any{
 			"model": config.Model,
 			"input": []map[string]string{
 				{
 					"role":    "system",
-					"content": SystemMessageResponses,
+					"content": systemMessage(ctx, SystemMessageResponses),
 				},
 				{
 					"role":    "user",
@@ -248,20 +133,20 @@ func (c *OpenAIClient) callOpenAI(prompt string, config APIConfig, responseSchem
 			"text": map[string]any{
 				"format": map[string]any{
 					"type":   "json_schema",
-					"name":   "goal_assistant_response",
+					"name":   SchemaName,
 					"schema": responseSchema,
 					"strict": true,
 				},
 			},
 		}
 	} else {
-		// Старый Completions API
+		// Chat Completions API (OpenAI и совместимые серверы) с JSON Schema
 		requestBody = map[string]any{
 			"model": config.Model,
 			"messages": []map[string]string{
 				{
 					"role":    "system",
-					"content": SystemMessageCompletions,
+					"content": systemMessage(ctx, SystemMessageCompletions),
 				},
 				{
 					"role":    "user",
@@ -270,62 +155,37 @@ func (c *OpenAIClient) callOpenAI(prompt string, config APIConfig, responseSchem
 			},
 			"temperature": DefaultTemperature,
 			"max_tokens":  DefaultMaxTokens,
-			"response_format": map[string]string{
-				"type": "json_object",
+			"response_format": map[string]any{
+				"type": "json_schema",
+				"json_schema": map[string]any{
+					"name":   SchemaName,
+					"schema": responseSchema,
+					"strict": true,
+				},
 			},
 		}
 	}
 
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		log.Printf(LogMarshalingError, err)
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-
 	// Логируем запрос для диагностики
 	log.Printf(LogSendingRequestDetails)
 	log.Printf(LogRequestURL, config.BaseURL)
 	log.Printf(LogRequestModel, config.Model)
-	log.Printf(LogRequestBody, string(jsonData))
-
-	req, err := http.NewRequest("POST", config.BaseURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		log.Printf(LogRequestError, err)
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 
-	log.Printf(LogSendingHTTPRequest, config.BaseURL)
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		log.Printf(LogHTTPRequestError, err)
-		return "", fmt.Errorf("failed to send request: %w", err)
+	headers := map[string]string{}
+	if c.apiKey != "" {
+		headers["Authorization"] = "Bearer " + c.apiKey
 	}
-	defer resp.Body.Close()
-
-	log.Printf(LogHTTPResponse, resp.StatusCode)
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		log.Printf(LogAPIError, resp.Status, string(body))
-		return "", fmt.Errorf("OpenAI API error: %s - %s", resp.Status, string(body))
-	}
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := postJSON(ctx, c.httpClient, c.retryPolicy, c.Name(), config.BaseURL, headers, requestBody)
 	if err != nil {
-		log.Printf(LogReadResponseError, err)
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return "", err
 	}
 
-	log.Printf(LogResponseSize, len(body))
-
 	// Логируем сырой ответ для диагностики
 	log.Printf(LogRawAPIResponse, string(body))
 
 	// Парсим ответ OpenAI
-	if config.BaseURL == ResponsesAPIEndpoint {
+	if isResponsesAPI(config.BaseURL) {
 		// Новый Responses API имеет другую структуру
 		var responsesAPIResponse struct {
 			ID     string `json:"id"`
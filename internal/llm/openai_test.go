@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestOpenAIResponsesAPI(t *testing.T) {
+	server, requests := newTestServer(t, testResponse{status: http.StatusOK, body: `{
+		"id": "resp_1",
+		"output": [{"type": "message", "content": [{"type": "output_text", "text": "{\"status\":\"ok\"}"}]}]
+	}`})
+
+	client, err := NewClient(ProviderConfig{Provider: ProviderOpenAI, APIKey: "key", Model: "model", BaseURL: server.URL + "/v1/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := client.(*PromptClient).provider.(*OpenAIClient)
+	provider.retryPolicy = testRetryPolicy(1)
+
+	content, err := provider.Complete(context.Background(), "prompt", testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != `{"status":"ok"}` {
+		t.Errorf("content = %q", content)
+	}
+
+	got := requests()
+	if len(got) != 1 {
+		t.Fatalf("requests = %d, want 1", len(got))
+	}
+	request := got[0]
+	if request.Path != "/v1"+ResponsesAPIPath {
+		t.Errorf("path = %q, want the configured base URL to be used", request.Path)
+	}
+	if auth := request.Header.Get("Authorization"); auth != "Bearer key" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if model := jsonPath(t, request.Body, "model"); model != "model" {
+		t.Errorf("model = %v", model)
+	}
+	if prompt := jsonPath(t, request.Body, "input", 1, "content"); prompt != "prompt" {
+		t.Errorf("user prompt = %v", prompt)
+	}
+	if name := jsonPath(t, request.Body, "text", "format", "name"); name != SchemaName {
+		t.Errorf("schema name = %v", name)
+	}
+	if schema := jsonPath(t, request.Body, "text", "format", "schema"); !reflect.DeepEqual(schema, testSchema) {
+		t.Errorf("schema = %v, want %v", schema, testSchema)
+	}
+}
+
+func TestOpenAICompatibleChatCompletions(t *testing.T) {
+	server, requests := newTestServer(t, testResponse{status: http.StatusOK, body: `{
+		"choices": [{"message": {"content": "{\"status\":\"ok\"}"}}]
+	}`})
+
+	provider := NewOpenAIProvider("", CompatibleAPIConfig(server.URL+"/v1", "local"))
+	provider.retryPolicy = testRetryPolicy(1)
+
+	content, err := provider.Complete(context.Background(), "prompt", testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != `{"status":"ok"}` {
+		t.Errorf("content = %q", content)
+	}
+
+	request := requests()[0]
+	if request.Path != "/v1"+CompletionsAPIPath {
+		t.Errorf("path = %q", request.Path)
+	}
+	if auth := request.Header.Get("Authorization"); auth != "" {
+		t.Errorf("Authorization = %q, want none without API key", auth)
+	}
+	if prompt := jsonPath(t, request.Body, "messages", 1, "content"); prompt != "prompt" {
+		t.Errorf("user prompt = %v", prompt)
+	}
+	if schema := jsonPath(t, request.Body, "response_format", "json_schema", "schema"); !reflect.DeepEqual(schema, testSchema) {
+		t.Errorf("schema = %v, want %v", schema, testSchema)
+	}
+}
+
+func TestOpenAIErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []testResponse
+		attempts  int
+		status    int
+		retryable bool
+	}{
+		{"unauthorized is not retried", []testResponse{{status: http.StatusUnauthorized, body: `{"error":"bad key"}`}}, 1, http.StatusUnauthorized, false},
+		{"server error is retried", []testResponse{{status: http.StatusServiceUnavailable, body: "busy"}}, 3, http.StatusServiceUnavailable, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newTestServer(t, tt.responses...)
+			provider := NewOpenAIProvider("key", CompatibleAPIConfig(server.URL, "model"))
+			provider.retryPolicy = testRetryPolicy(3)
+
+			_, err := provider.Complete(context.Background(), "prompt", testSchema)
+			assertAPIError(t, err, tt.status, tt.retryable)
+			if n := len(requests()); n != tt.attempts {
+				t.Errorf("requests = %d, want %d", n, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestOpenAIRetriesUntilSuccess(t *testing.T) {
+	server, requests := newTestServer(t,
+		testResponse{status: http.StatusTooManyRequests, body: "slow down"},
+		testResponse{status: http.StatusOK, body: `{"choices": [{"message": {"content": "{\"status\":\"ok\"}"}}]}`},
+	)
+	provider := NewOpenAIProvider("key", CompatibleAPIConfig(server.URL, "model"))
+	provider.retryPolicy = testRetryPolicy(3)
+
+	if _, err := provider.Complete(context.Background(), "prompt", testSchema); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(requests()); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+}
+
+func TestOpenAIEmptyChoices(t *testing.T) {
+	server, _ := newTestServer(t, testResponse{status: http.StatusOK, body: `{"choices": []}`})
+	provider := NewOpenAIProvider("key", CompatibleAPIConfig(server.URL, "model"))
+	provider.retryPolicy = testRetryPolicy(1)
+
+	if _, err := provider.Complete(context.Background(), "prompt", testSchema); err == nil {
+		t.Fatal("expected error for response without choices")
+	}
+}
@@ -0,0 +1,274 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"goal-helper/internal/calibration"
+	"goal-helper/internal/i18n"
+	"goal-helper/internal/models"
+)
+
+// Provider представляет конкретного LLM провайдера (OpenAI, Anthropic, Ollama, ...)
+// Провайдер получает готовый промпт и JSON схему ответа и возвращает текст с JSON
+type Provider interface {
+	Name() string
+	Complete(ctx context.Context, prompt string, responseSchema map[string]any) (string, error)
+}
+
+// PromptClient реализует Client поверх любого Provider:
+// загружает промпты из файлов, отправляет их провайдеру и парсит JSON ответы
+type PromptClient struct {
+	provider     Provider
+	promptLoader *PromptLoader // Загрузчик промптов из файлов
+	promptUtils  *PromptUtils  // Утилиты для подготовки плейсхолдеров
+}
+
+// NewPromptClient создает клиент для указанного провайдера
+func NewPromptClient(provider Provider) *PromptClient {
+	return &PromptClient{
+		provider:     provider,
+		promptLoader: NewPromptLoader(),
+		promptUtils:  NewPromptUtils(),
+	}
+}
+
+// GenerateStep генерирует следующий шаг для цели
+// Пропущенные шаги передаются отдельно, чтобы LLM учла, что они не подошли,
+// level задает размер шага, подобранный под пользователя
+func (c *PromptClient) GenerateStep(ctx context.Context, goal *models.Goal, completedSteps, skippedSteps []*models.Step, level calibration.Level) (*StepResponse, error) {
+	// У цели может быть своя персона
+	ctx = withGoalPersona(ctx, goal)
+
+	// Загружаем промпт из файла на языке пользователя
+	loc := i18n.New(i18n.FromContext(ctx))
+	placeholders := c.promptUtils.BuildStepPromptPlaceholders(loc, goal, completedSteps, skippedSteps, level)
+	prompt, err := c.promptLoader.LoadPrompt(ctx, PromptStepGeneration, placeholders)
+	if err != nil {
+		log.Printf(LogPromptLoadError, err)
+		return nil, fmt.Errorf("failed to load prompt: %w", err)
+	}
+
+	log.Printf(LogSendingRequest, c.provider.Name(), goal.Title)
+	log.Printf(LogPromptLength, len(prompt))
+
+	response, err := c.provider.Complete(ctx, prompt, StepResponseSchema)
+	if err != nil {
+		log.Printf(LogLLMError, err)
+		return nil, fmt.Errorf("failed to call %s: %w", c.provider.Name(), err)
+	}
+
+	log.Printf(LogLLMResponse, response)
+
+	var stepResponse StepResponse
+	if err := UnmarshalLLMResponseWithLogging(response, &stepResponse, "генерация шага"); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", c.provider.Name(), err)
+	}
+
+	log.Printf(LogSuccessResponse, stepResponse.Status)
+	return &stepResponse, nil
+}
+
+// RephraseStep переформулирует текущий шаг
+func (c *PromptClient) RephraseStep(ctx context.Context, goal *models.Goal, currentStep *models.Step, userComment string) (*StepResponse, error) {
+	// У цели может быть своя персона
+	ctx = withGoalPersona(ctx, goal)
+
+	// Загружаем промпт из файла на языке пользователя
+	loc := i18n.New(i18n.FromContext(ctx))
+	placeholders := c.promptUtils.BuildRephrasePromptPlaceholders(loc, goal, currentStep, userComment)
+
+	prompt, err := c.promptLoader.LoadPrompt(ctx, PromptStepRephrase, placeholders)
+	if err != nil {
+		log.Printf(LogPromptLoadError, err)
+		return nil, fmt.Errorf("failed to load prompt: %w", err)
+	}
+
+	response, err := c.provider.Complete(ctx, prompt, RephraseResponseSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", c.provider.Name(), err)
+	}
+
+	var stepResponse StepResponse
+	if err := UnmarshalLLMResponseWithLogging(response, &stepResponse, "переформулировка шага"); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", c.provider.Name(), err)
+	}
+
+	return &stepResponse, nil
+}
+
+// ClarifyGoal запрашивает уточнение цели
+func (c *PromptClient) ClarifyGoal(ctx context.Context, goalTitle, goalDescription string) (*ClarificationResponse, error) {
+	// Загружаем промпт из файла на языке пользователя
+	loc := i18n.New(i18n.FromContext(ctx))
+	placeholders := c.promptUtils.BuildClarificationPromptPlaceholders(loc, goalTitle, goalDescription)
+
+	prompt, err := c.promptLoader.LoadPrompt(ctx, PromptGoalClarification, placeholders)
+	if err != nil {
+		log.Printf(LogPromptLoadError, err)
+		return nil, fmt.Errorf("failed to load prompt: %w", err)
+	}
+
+	response, err := c.provider.Complete(ctx, prompt, ClarificationResponseSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", c.provider.Name(), err)
+	}
+
+	var clarificationResponse ClarificationResponse
+	if err := UnmarshalLLMResponseWithLogging(response, &clarificationResponse, "уточнение цели"); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", c.provider.Name(), err)
+	}
+
+	return &clarificationResponse, nil
+}
+
+// GenerateGoalTitle генерирует название цели на основе описания
+func (c *PromptClient) GenerateGoalTitle(ctx context.Context, description string) (string, error) {
+	// Загружаем промпт из файла на языке пользователя
+	placeholders := c.promptUtils.BuildTitlePromptPlaceholders(description)
+
+	prompt, err := c.promptLoader.LoadPrompt(ctx, PromptTitleGeneration, placeholders)
+	if err != nil {
+		log.Printf(LogPromptLoadError, err)
+		return "", fmt.Errorf("failed to load prompt: %w", err)
+	}
+
+	log.Printf(LogTitleGeneration, description)
+	log.Printf(LogTitlePrompt, prompt)
+
+	response, err := c.provider.Complete(ctx, prompt, TitleResponseSchema)
+	if err != nil {
+		log.Printf(LogTitleError, err)
+		return "", fmt.Errorf("failed to call %s: %w", c.provider.Name(), err)
+	}
+
+	log.Printf(LogTitleResponse, response)
+
+	var titleResponse struct {
+		Title string `json:"title"`
+	}
+	if err := UnmarshalLLMResponseWithLogging(response, &titleResponse, "генерация названия цели"); err != nil {
+		return "", fmt.Errorf("failed to parse %s response: %w", c.provider.Name(), err)
+	}
+
+	log.Printf(LogTitleSuccess, titleResponse.Title)
+	return titleResponse.Title, nil
+}
+
+// GatherContext собирает контекст пользователя для более точной генерации шагов
+func (c *PromptClient) GatherContext(ctx context.Context, goal *models.Goal) (*ContextResponse, error) {
+	// У цели может быть своя персона
+	ctx = withGoalPersona(ctx, goal)
+
+	// Загружаем промпт из файла на языке пользователя
+	loc := i18n.New(i18n.FromContext(ctx))
+	placeholders := c.promptUtils.BuildContextPromptPlaceholders(loc, goal)
+	prompt, err := c.promptLoader.LoadPrompt(ctx, PromptContextGathering, placeholders)
+	if err != nil {
+		log.Printf(LogPromptLoadError, err)
+		return nil, fmt.Errorf("failed to load prompt: %w", err)
+	}
+
+	log.Printf(LogContextGathering, goal.Title)
+
+	response, err := c.provider.Complete(ctx, prompt, ContextResponseSchema)
+	if err != nil {
+		log.Printf(LogContextError, err)
+		return nil, fmt.Errorf("failed to call %s: %w", c.provider.Name(), err)
+	}
+
+	log.Printf(LogContextResponse, response)
+
+	var contextResponse ContextResponse
+	if err := UnmarshalLLMResponseWithLogging(response, &contextResponse, "сбор контекста"); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", c.provider.Name(), err)
+	}
+
+	log.Printf(LogContextSuccess, contextResponse.Status)
+	return &contextResponse, nil
+}
+
+// ClassifyGoal определяет категорию цели (models.GoalCategory...)
+// Неизвестная категория в ответе заменяется на models.GoalCategoryOther
+func (c *PromptClient) ClassifyGoal(ctx context.Context, goalTitle, goalDescription string) (string, error) {
+	// Загружаем промпт из файла на языке пользователя
+	loc := i18n.New(i18n.FromContext(ctx))
+	placeholders := c.promptUtils.BuildClassificationPromptPlaceholders(loc, goalTitle, goalDescription)
+
+	prompt, err := c.promptLoader.LoadPrompt(ctx, PromptGoalClassification, placeholders)
+	if err != nil {
+		log.Printf(LogPromptLoadError, err)
+		return "", fmt.Errorf("failed to load prompt: %w", err)
+	}
+
+	log.Printf(LogClassification, goalTitle)
+
+	response, err := c.provider.Complete(ctx, prompt, ClassificationResponseSchema)
+	if err != nil {
+		log.Printf(LogClassificationError, err)
+		return "", fmt.Errorf("failed to call %s: %w", c.provider.Name(), err)
+	}
+
+	var classificationResponse struct {
+		Category string `json:"category"`
+	}
+	if err := UnmarshalLLMResponseWithLogging(response, &classificationResponse, "классификация цели"); err != nil {
+		return "", fmt.Errorf("failed to parse %s response: %w", c.provider.Name(), err)
+	}
+
+	// Провайдеры без структурированного вывода могут вернуть что угодно
+	category := classificationResponse.Category
+	if !models.IsGoalCategory(category) {
+		log.Printf(LogUnknownCategory, category, models.GoalCategoryOther)
+		category = models.GoalCategoryOther
+	}
+
+	log.Printf(LogClassificationSuccess, category)
+	return category, nil
+}
+
+// ProposeMilestones предлагает этапы для большой цели
+// Пройденные этапы и выполненные шаги передаются в промпт, поэтому в ответе только оставшиеся этапы
+func (c *PromptClient) ProposeMilestones(ctx context.Context, goal *models.Goal, completedSteps []*models.Step) ([]string, error) {
+	// У цели может быть своя персона
+	ctx = withGoalPersona(ctx, goal)
+
+	// Загружаем промпт из файла на языке пользователя
+	loc := i18n.New(i18n.FromContext(ctx))
+	placeholders := c.promptUtils.BuildMilestonePromptPlaceholders(loc, goal, completedSteps)
+	prompt, err := c.promptLoader.LoadPrompt(ctx, PromptMilestonePlanning, placeholders)
+	if err != nil {
+		log.Printf(LogPromptLoadError, err)
+		return nil, fmt.Errorf("failed to load prompt: %w", err)
+	}
+
+	log.Printf(LogMilestonePlanning, goal.Title)
+
+	response, err := c.provider.Complete(ctx, prompt, MilestonesResponseSchema)
+	if err != nil {
+		log.Printf(LogMilestonePlanningError, err)
+		return nil, fmt.Errorf("failed to call %s: %w", c.provider.Name(), err)
+	}
+
+	var milestonesResponse struct {
+		Milestones []string `json:"milestones"`
+	}
+	if err := UnmarshalLLMResponseWithLogging(response, &milestonesResponse, "составление этапов"); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", c.provider.Name(), err)
+	}
+
+	var milestones []string
+	for _, title := range milestonesResponse.Milestones {
+		if title = strings.TrimSpace(title); title != "" {
+			milestones = append(milestones, title)
+		}
+	}
+	if len(milestones) == 0 {
+		return nil, fmt.Errorf("%s returned no milestones", c.provider.Name())
+	}
+
+	log.Printf(LogMilestonePlanningResult, len(milestones))
+	return milestones, nil
+}
@@ -1,17 +1,25 @@
 package llm
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+
+	"goal-helper/internal/i18n"
+	"goal-helper/internal/persona"
 )
 
 // PromptLoader представляет загрузчик промптов из файлов
 type PromptLoader struct {
 	promptsDir string
+
+	// Обработчики апдейтов работают параллельно, поэтому кэш защищен мьютексом
+	cacheMutex sync.RWMutex
 	cache      map[string]string // Кэш для загруженных промптов
 }
 
@@ -33,16 +41,46 @@ func NewPromptLoader() *PromptLoader {
 }
 
 // LoadPrompt загружает промпт из файла и подставляет значения
+// ctx - контекст запроса: из него берутся язык и персона пользователя
+// (i18n.WithLanguage, persona.WithPersona). Варианты промптов на других языках лежат
+// в подпапках по языкам (например, "en/step_generation.md"), промпты на языке по умолчанию - в корне папки.
+// Если варианта на языке нет, берется промпт по умолчанию. Блок персоны добавляется в начало промпта
 // filename - имя файла без расширения (например, "step_generation")
 // placeholders - карта плейсхолдеров для подстановки
-func (pl *PromptLoader) LoadPrompt(filename string, placeholders map[string]string) (string, error) {
+func (pl *PromptLoader) LoadPrompt(ctx context.Context, filename string, placeholders map[string]string) (string, error) {
+	language := i18n.FromContext(ctx)
+	content, err := pl.loadTemplate(filename, language)
+	if err != nil {
+		return "", err
+	}
+
+	prompt := pl.replacePlaceholders(content, placeholders)
+	if block := persona.FromContext(ctx).Prompt(language); block != "" {
+		prompt = block + "\n\n" + prompt
+	}
+	return prompt, nil
+}
+
+// loadTemplate читает шаблон промпта на языке language (без подстановки плейсхолдеров)
+func (pl *PromptLoader) loadTemplate(filename, language string) (string, error) {
+	cacheKey := language + "/" + filename
+
 	// Проверяем кэш
-	if cached, exists := pl.cache[filename]; exists {
-		return pl.replacePlaceholders(cached, placeholders), nil
+	pl.cacheMutex.RLock()
+	cached, exists := pl.cache[cacheKey]
+	pl.cacheMutex.RUnlock()
+	if exists {
+		return cached, nil
 	}
 
 	// Формируем полный путь к файлу
 	filePath := filepath.Join(pl.promptsDir, filename+".md")
+	if language != i18n.DefaultLanguage {
+		localized := filepath.Join(pl.promptsDir, language, filename+".md")
+		if _, err := os.Stat(localized); err == nil {
+			filePath = localized
+		}
+	}
 
 	// Читаем файл
 	content, err := os.ReadFile(filePath)
@@ -60,10 +98,11 @@ func (pl *PromptLoader) LoadPrompt(filename string, placeholders map[string]stri
 	promptContent := strings.TrimSpace(strings.Join(lines, "\n"))
 
 	// Кэшируем промпт
-	pl.cache[filename] = promptContent
+	pl.cacheMutex.Lock()
+	pl.cache[cacheKey] = promptContent
+	pl.cacheMutex.Unlock()
 
-	// Подставляем плейсхолдеры и возвращаем результат
-	return pl.replacePlaceholders(promptContent, placeholders), nil
+	return promptContent, nil
 }
 
 // replacePlaceholders заменяет плейсхолдеры в формате {{{key}}} на соответствующие значения
@@ -99,6 +138,11 @@ func (pl *PromptLoader) ListAvailablePrompts() ([]string, error) {
 			return err
 		}
 
+		// Варианты промптов на других языках лежат в подпапках и отдельными промптами не считаются
+		if d.IsDir() && path != pl.promptsDir {
+			return fs.SkipDir
+		}
+
 		if !d.IsDir() && strings.HasSuffix(d.Name(), ".md") {
 			// Убираем расширение .md
 			promptName := strings.TrimSuffix(d.Name(), ".md")
@@ -117,5 +161,7 @@ func (pl *PromptLoader) ListAvailablePrompts() ([]string, error) {
 
 // ClearCache очищает кэш промптов
 func (pl *PromptLoader) ClearCache() {
+	pl.cacheMutex.Lock()
+	defer pl.cacheMutex.Unlock()
 	pl.cache = make(map[string]string)
 }
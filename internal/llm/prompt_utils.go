@@ -1,100 +1,263 @@
 package llm
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	"goal-helper/internal/calibration"
+	"goal-helper/internal/i18n"
 	"goal-helper/internal/models"
+	"goal-helper/internal/persona"
 )
 
 // PromptUtils предоставляет утилиты для подготовки плейсхолдеров промптов
 type PromptUtils struct{}
 
+// categoryStrategies ключи стратегий генерации шагов по категориям целей
+// Для цели без категории используется общая стратегия
+var categoryStrategies = map[string]string{
+	models.GoalCategoryCreative:    CategoryStrategyCreative,
+	models.GoalCategoryEducational: CategoryStrategyEducational,
+	models.GoalCategoryHousehold:   CategoryStrategyHousehold,
+	models.GoalCategoryHealth:      CategoryStrategyHealth,
+	models.GoalCategoryCareer:      CategoryStrategyCareer,
+}
+
+// categoryStepSizeRules ключи правил размера шага по категориям целей
+var categoryStepSizeRules = map[string]string{
+	models.GoalCategoryCreative:    StepSizeRuleCreative,
+	models.GoalCategoryEducational: StepSizeRuleEducational,
+	models.GoalCategoryHousehold:   StepSizeRuleHousehold,
+	models.GoalCategoryHealth:      StepSizeRuleHealth,
+	models.GoalCategoryCareer:      StepSizeRuleCareer,
+}
+
 // NewPromptUtils создает новый экземпляр утилит для промптов
 func NewPromptUtils() *PromptUtils {
 	return &PromptUtils{}
 }
 
 // BuildStepPromptPlaceholders подготавливает плейсхолдеры для промпта генерации шагов
-func (pu *PromptUtils) BuildStepPromptPlaceholders(goal *models.Goal, completedSteps []*models.Step) map[string]string {
+// Пропущенные шаги не считаются выполненными и передаются отдельно
+func (pu *PromptUtils) BuildStepPromptPlaceholders(loc *i18n.Localizer, goal *models.Goal, completedSteps, skippedSteps []*models.Step, level calibration.Level) map[string]string {
 	placeholders := make(map[string]string)
 
+	// Размер шага, подобранный по истории пользователя и категории цели
+	placeholders[PlaceholderStepSize] = stepSizeDescription(loc, level, goal.Category)
+	placeholders[PlaceholderCategoryStrategy] = categoryStrategy(loc, goal.Category)
+
 	// Основная информация о цели
 	placeholders[PlaceholderGoalTitle] = goal.Title
 	if goal.Description != "" {
-		placeholders[PlaceholderGoalDescription] = fmt.Sprintf(FormatDescription, goal.Description)
+		placeholders[PlaceholderGoalDescription] = loc.T(FormatDescription, goal.Description)
 	} else {
 		placeholders[PlaceholderGoalDescription] = ""
 	}
 
 	// Контекст пользователя
-	if len(goal.Context.Clarifications) > 0 {
-		var contextBuilder strings.Builder
-		for i, clarification := range goal.Context.Clarifications {
-			contextBuilder.WriteString(fmt.Sprintf(FormatClarification, i+1, clarification))
-		}
-		placeholders[PlaceholderUserContext] = contextBuilder.String()
-	} else {
-		placeholders[PlaceholderUserContext] = ""
-	}
+	placeholders[PlaceholderUserContext] = formatClarifications(loc, goal.Context.Clarifications)
 
 	// Выполненные шаги
 	if len(completedSteps) > 0 {
 		var stepsBuilder strings.Builder
 		for i, step := range completedSteps {
 			stepsBuilder.WriteString(fmt.Sprintf(FormatStep, i+1, step.Text))
+			if feedback := stepFeedback(loc, step); feedback != "" {
+				stepsBuilder.WriteString(loc.T(FormatStepFeedback, feedback))
+			}
 		}
 		placeholders[PlaceholderCompletedSteps] = stepsBuilder.String()
 	} else {
 		placeholders[PlaceholderCompletedSteps] = ""
 	}
 
+	// Пропущенные шаги с причинами
+	if len(skippedSteps) > 0 {
+		var skippedBuilder strings.Builder
+		for i, step := range skippedSteps {
+			if step.SkipReason != "" {
+				skippedBuilder.WriteString(loc.T(FormatSkippedStep, i+1, step.Text, step.SkipReason))
+			} else {
+				skippedBuilder.WriteString(fmt.Sprintf(FormatStep, i+1, step.Text))
+			}
+		}
+		placeholders[PlaceholderSkippedSteps] = skippedBuilder.String()
+	} else {
+		placeholders[PlaceholderSkippedSteps] = loc.T(FormatNoSkippedSteps)
+	}
+
+	// Формулировки, которые пользователь отклонил до выполнения или пропуска шагов
+	var rejected []string
+	for _, step := range completedSteps {
+		rejected = append(rejected, step.RejectedTexts()...)
+	}
+	for _, step := range skippedSteps {
+		rejected = append(rejected, step.RejectedTexts()...)
+	}
+	placeholders[PlaceholderRejectedSteps] = formatRejectedSteps(loc, rejected)
+
+	// План этапов: шаг должен относиться к текущему этапу
+	placeholders[PlaceholderMilestones] = formatMilestones(loc, goal, completedSteps)
+
+	return placeholders
+}
+
+// BuildMilestonePromptPlaceholders подготавливает плейсхолдеры для промпта составления этапов
+func (pu *PromptUtils) BuildMilestonePromptPlaceholders(loc *i18n.Localizer, goal *models.Goal, completedSteps []*models.Step) map[string]string {
+	placeholders := pu.BuildContextPromptPlaceholders(loc, goal)
+	placeholders[PlaceholderUserContext] = placeholders[PlaceholderExistingContext]
+	placeholders[PlaceholderCategoryStrategy] = categoryStrategy(loc, goal.Category)
+
+	var stepsBuilder strings.Builder
+	for i, step := range completedSteps {
+		stepsBuilder.WriteString(fmt.Sprintf(FormatStep, i+1, step.Text))
+	}
+	placeholders[PlaceholderCompletedSteps] = stepsBuilder.String()
+	placeholders[PlaceholderMilestones] = formatMilestones(loc, goal, completedSteps)
+
 	return placeholders
 }
 
 // BuildContextPromptPlaceholders подготавливает плейсхолдеры для промпта сбора контекста
-func (pu *PromptUtils) BuildContextPromptPlaceholders(goal *models.Goal) map[string]string {
+func (pu *PromptUtils) BuildContextPromptPlaceholders(loc *i18n.Localizer, goal *models.Goal) map[string]string {
 	placeholders := make(map[string]string)
 
 	// Основная информация о цели
 	placeholders[PlaceholderGoalTitle] = goal.Title
 	if goal.Description != "" {
-		placeholders[PlaceholderGoalDescription] = fmt.Sprintf(FormatDescription, goal.Description)
+		placeholders[PlaceholderGoalDescription] = loc.T(FormatDescription, goal.Description)
 	} else {
 		placeholders[PlaceholderGoalDescription] = ""
 	}
 
 	// Уже собранный контекст
-	if len(goal.Context.Clarifications) > 0 {
-		var contextBuilder strings.Builder
-		for i, clarification := range goal.Context.Clarifications {
-			contextBuilder.WriteString(fmt.Sprintf(FormatClarification, i+1, clarification))
-		}
-		placeholders[PlaceholderExistingContext] = contextBuilder.String()
-	} else {
-		placeholders[PlaceholderExistingContext] = ""
-	}
+	placeholders[PlaceholderExistingContext] = formatClarifications(loc, goal.Context.Clarifications)
 
 	return placeholders
 }
 
 // BuildRephrasePromptPlaceholders подготавливает плейсхолдеры для промпта переформулировки
-func (pu *PromptUtils) BuildRephrasePromptPlaceholders(goal *models.Goal, currentStep *models.Step, userComment string) map[string]string {
+func (pu *PromptUtils) BuildRephrasePromptPlaceholders(loc *i18n.Localizer, goal *models.Goal, currentStep *models.Step, userComment string) map[string]string {
 	return map[string]string{
-		PlaceholderGoalTitle:   goal.Title,
-		PlaceholderCurrentStep: currentStep.Text,
-		PlaceholderUserComment: userComment,
+		PlaceholderGoalTitle:        goal.Title,
+		PlaceholderCurrentStep:      currentStep.Text,
+		PlaceholderUserComment:      userComment,
+		PlaceholderRejectedSteps:    formatRejectedSteps(loc, currentStep.RejectedTexts()),
+		PlaceholderCategoryStrategy: categoryStrategy(loc, goal.Category),
+	}
+}
+
+// stepSizeDescription описывает для LLM размер шага на уровне level
+// с поправкой на категорию цели (например, для бытовых целей - одно действие)
+func stepSizeDescription(loc *i18n.Localizer, level calibration.Level, category string) string {
+	var size string
+	switch level {
+	case calibration.LevelTiny:
+		size = loc.T(StepSizeTiny)
+	case calibration.LevelSmall:
+		size = loc.T(StepSizeSmall)
+	case calibration.LevelLarge:
+		size = loc.T(StepSizeLarge)
+	case calibration.LevelHuge:
+		size = loc.T(StepSizeHuge)
+	default:
+		size = loc.T(StepSizeNormal)
+	}
+
+	if rule, ok := categoryStepSizeRules[category]; ok {
+		size += "; " + loc.T(rule)
 	}
+	return size
+}
+
+// categoryStrategy описывает для LLM, как строить шаги для цели этой категории
+func categoryStrategy(loc *i18n.Localizer, category string) string {
+	if strategy, ok := categoryStrategies[category]; ok {
+		return loc.T(strategy)
+	}
+	return loc.T(CategoryStrategyOther)
+}
+
+// stepFeedback описывает оценку сложности и заметку пользователя о выполненном шаге
+func stepFeedback(loc *i18n.Localizer, step *models.Step) string {
+	var parts []string
+	switch step.Difficulty {
+	case models.StepDifficultyTooEasy:
+		parts = append(parts, loc.T(DifficultyTooEasy))
+	case models.StepDifficultyRight:
+		parts = append(parts, loc.T(DifficultyRight))
+	case models.StepDifficultyTooHard:
+		parts = append(parts, loc.T(DifficultyTooHard))
+	}
+	if step.CompletionNote != "" {
+		parts = append(parts, loc.T(FormatStepNote, step.CompletionNote))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// formatClarifications нумерует уточнения в виде пар вопрос-ответ
+func formatClarifications(loc *i18n.Localizer, clarifications []models.Clarification) string {
+	var builder strings.Builder
+	for i, clarification := range clarifications {
+		if clarification.Question != "" {
+			builder.WriteString(loc.T(FormatClarification, i+1, clarification.Question, clarification.Answer))
+		} else {
+			builder.WriteString(fmt.Sprintf(FormatClarificationAnswer, i+1, clarification.Answer))
+		}
+	}
+	return builder.String()
+}
+
+// formatMilestones нумерует этапы цели с отметкой пройденных и текущего
+// У текущего этапа указывается, сколько шагов по нему уже выполнено
+func formatMilestones(loc *i18n.Localizer, goal *models.Goal, completedSteps []*models.Step) string {
+	if !goal.HasMilestones() {
+		return loc.T(FormatNoMilestones)
+	}
+
+	_, current := goal.CurrentMilestone()
+	var builder strings.Builder
+	for i, milestone := range goal.Milestones {
+		switch {
+		case milestone.IsCompleted():
+			builder.WriteString(loc.T(FormatMilestoneDone, i+1, milestone.Title))
+		case i == current:
+			done := 0
+			for _, step := range completedSteps {
+				if step.MilestoneID == milestone.ID {
+					done++
+				}
+			}
+			builder.WriteString(loc.T(FormatMilestoneCurrent, i+1, milestone.Title, done))
+		default:
+			builder.WriteString(loc.T(FormatMilestoneUpcoming, i+1, milestone.Title))
+		}
+	}
+	return builder.String()
+}
+
+// formatRejectedSteps нумерует отклоненные формулировки шагов
+func formatRejectedSteps(loc *i18n.Localizer, texts []string) string {
+	if len(texts) == 0 {
+		return loc.T(FormatNoRejectedSteps)
+	}
+
+	var builder strings.Builder
+	for i, text := range texts {
+		builder.WriteString(fmt.Sprintf(FormatStep, i+1, text))
+	}
+	return builder.String()
 }
 
 // BuildClarificationPromptPlaceholders подготавливает плейсхолдеры для промпта уточнения
-func (pu *PromptUtils) BuildClarificationPromptPlaceholders(goalTitle, goalDescription string) map[string]string {
+func (pu *PromptUtils) BuildClarificationPromptPlaceholders(loc *i18n.Localizer, goalTitle, goalDescription string) map[string]string {
 	placeholders := map[string]string{
 		PlaceholderGoalTitle: goalTitle,
 	}
 
 	if goalDescription != "" {
-		placeholders[PlaceholderGoalDescription] = fmt.Sprintf(FormatDescription, goalDescription)
+		placeholders[PlaceholderGoalDescription] = loc.T(FormatDescription, goalDescription)
 	} else {
 		placeholders[PlaceholderGoalDescription] = ""
 	}
@@ -102,9 +265,27 @@ func (pu *PromptUtils) BuildClarificationPromptPlaceholders(goalTitle, goalDescr
 	return placeholders
 }
 
+// BuildClassificationPromptPlaceholders подготавливает плейсхолдеры для промпта классификации цели
+func (pu *PromptUtils) BuildClassificationPromptPlaceholders(loc *i18n.Localizer, goalTitle, goalDescription string) map[string]string {
+	return pu.BuildClarificationPromptPlaceholders(loc, goalTitle, goalDescription)
+}
+
 // BuildTitlePromptPlaceholders подготавливает плейсхолдеры для промпта генерации названия
 func (pu *PromptUtils) BuildTitlePromptPlaceholders(description string) map[string]string {
 	return map[string]string{
 		PlaceholderDescription: description,
 	}
 }
+
+// systemMessage возвращает системное сообщение на языке запроса
+func systemMessage(ctx context.Context, key string) string {
+	return i18n.New(i18n.FromContext(ctx)).T(key)
+}
+
+// withGoalPersona возвращает контекст с персоной цели, если она задана (иначе остается персона пользователя)
+func withGoalPersona(ctx context.Context, goal *models.Goal) context.Context {
+	if persona.Exists(goal.Persona) {
+		return persona.WithPersona(ctx, goal.Persona)
+	}
+	return ctx
+}
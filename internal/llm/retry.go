@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy описывает повторные попытки HTTP запросов к провайдеру
+type RetryPolicy struct {
+	MaxAttempts int           // Максимальное количество попыток (включая первую)
+	BaseDelay   time.Duration // Задержка перед первым повтором
+	MaxDelay    time.Duration // Максимальная задержка между попытками
+}
+
+// DefaultRetryPolicy возвращает политику повторов по умолчанию
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: DefaultRetryAttempts,
+		BaseDelay:   DefaultRetryBaseDelay,
+		MaxDelay:    DefaultRetryMaxDelay,
+	}
+}
+
+// IsRetryable определяет, имеет ли смысл повторить запрос после ошибки.
+// Повторяются ограничения частоты (429), перегрузка и ошибки сервера (5xx),
+// таймауты и сетевые ошибки. Остальные ошибки API (400, 401, 404, ...) фатальны.
+// Истек ли контекст всего запроса, проверяет вызывающий по ctx.Err()
+func IsRetryable(err error) bool {
+	// Отмененный запрос повторять бессмысленно
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// Таймаут одной попытки (http.Client.Timeout) тоже выглядит как DeadlineExceeded
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusTooManyRequests,
+			apiErr.StatusCode == http.StatusRequestTimeout,
+			apiErr.StatusCode >= 500:
+			return true
+		default:
+			return false
+		}
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// delay вычисляет задержку перед попыткой attempt (начиная с 1) после ошибки err.
+// Возвращает false, если сервер просит подождать дольше MaxDelay
+func (p RetryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	// Сервер сам сказал, сколько ждать
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		if apiErr.RetryAfter > p.MaxDelay {
+			return 0, false
+		}
+		return apiErr.RetryAfter, true
+	}
+
+	// Экспоненциальная задержка с джиттером: случайное значение в [d/2, d]
+	d := p.BaseDelay << (attempt - 1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1)), true
+}
+
+// parseRetryAfter разбирает заголовок Retry-After (секунды или HTTP дата)
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,180 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{"empty", "", 0, 0},
+		{"seconds", "5", 5 * time.Second, 5 * time.Second},
+		{"zero seconds", "0", 0, 0},
+		{"negative seconds", "-3", 0, 0},
+		{"http date in future", time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat), 28 * time.Second, 30 * time.Second},
+		{"http date in past", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0, 0},
+		{"garbage", "soon", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseRetryAfter(tt.value)
+			if got < tt.min || got > tt.max {
+				t.Errorf("parseRetryAfter(%q) = %v, want in [%v, %v]", tt.value, got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+// timeoutError сетевая ошибка таймаута
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	var netErr net.Error = timeoutError{}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limited", &APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{"request timeout", &APIError{StatusCode: http.StatusRequestTimeout}, true},
+		{"server error", &APIError{StatusCode: http.StatusInternalServerError}, true},
+		{"overloaded", &APIError{StatusCode: 529}, true},
+		{"bad request", &APIError{StatusCode: http.StatusBadRequest}, false},
+		{"unauthorized", &APIError{StatusCode: http.StatusUnauthorized}, false},
+		{"not found", &APIError{StatusCode: http.StatusNotFound}, false},
+		{"wrapped api error", fmt.Errorf("call failed: %w", &APIError{StatusCode: http.StatusBadGateway}), true},
+		{"network error", fmt.Errorf("failed to send request: %w", netErr), true},
+		{"canceled", context.Canceled, false},
+		{"attempt deadline exceeded", fmt.Errorf("failed: %w", context.DeadlineExceeded), true},
+		{"other error", errors.New("failed to parse response"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	serverErr := &APIError{StatusCode: http.StatusServiceUnavailable}
+
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},  // Ограничено MaxDelay
+		{64, time.Second}, // Сдвиг переполняется
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt %d", tt.attempt), func(t *testing.T) {
+			// Джиттер случайный, поэтому проверяем диапазон на нескольких значениях
+			for i := 0; i < 100; i++ {
+				got, ok := policy.delay(tt.attempt, serverErr)
+				if !ok {
+					t.Fatal("delay returned false")
+				}
+				if got < tt.max/2 || got > tt.max {
+					t.Fatalf("delay = %v, want in [%v, %v]", got, tt.max/2, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelayRetryAfter(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 10 * time.Second}
+
+	got, ok := policy.delay(1, &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 3 * time.Second})
+	if !ok || got != 3*time.Second {
+		t.Errorf("delay = %v, %v, want Retry-After 3s", got, ok)
+	}
+
+	// Сервер просит ждать дольше, чем мы готовы
+	if _, ok := policy.delay(1, &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}); ok {
+		t.Error("delay with Retry-After above MaxDelay should give up")
+	}
+}
+
+func TestPostJSONRetriesAttemptTimeout(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Пока тело не прочитано, сервер не замечает, что клиент отключился
+		io.Copy(io.Discard, r.Body)
+
+		// Первая попытка не укладывается в таймаут клиента
+		if attempts.Add(1) == 1 {
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+			}
+			return
+		}
+		io.WriteString(w, `{"status": "ok"}`)
+	}))
+	defer server.Close()
+
+	httpClient := &http.Client{Timeout: 50 * time.Millisecond}
+	body, err := postJSON(context.Background(), httpClient, testRetryPolicy(3), "test", server.URL, nil, map[string]string{})
+	if err != nil {
+		t.Fatalf("postJSON failed: %v", err)
+	}
+	if string(body) != `{"status": "ok"}` {
+		t.Errorf("body = %s", body)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("attempts = %d, want 2", got)
+	}
+}
+
+func TestPostJSONStopsWhenContextExpires(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		attempts.Add(1)
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	// Истек контекст всего запроса, а не таймаут попытки - повторять нельзя
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := postJSON(ctx, newHTTPClient(), testRetryPolicy(3), "test", server.URL, nil, map[string]string{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want deadline exceeded", err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
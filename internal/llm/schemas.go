@@ -1,5 +1,7 @@
 package llm
 
+import "goal-helper/internal/models"
+
 // JSON схемы для структурированного вывода в новом OpenAI API
 
 // StepResponseSchema схема для ответа генерации шага
@@ -23,8 +25,12 @@ var StepResponseSchema = map[string]any{
 			"type":        "string",
 			"description": "Причина завершения цели (если цель достигнута)",
 		},
+		"milestone_completed": map[string]any{
+			"type":        "boolean",
+			"description": "Текущий этап цели пройден (false, если у цели нет этапов)",
+		},
 	},
-	"required":             []string{"status", "step", "question", "completion_reason"},
+	"required":             []string{"status", "step", "question", "completion_reason", "milestone_completed"},
 	"additionalProperties": false,
 }
 
@@ -98,3 +104,31 @@ var ContextResponseSchema = map[string]any{
 	"required":             []string{"status", "question", "context"},
 	"additionalProperties": false,
 }
+
+// ClassificationResponseSchema схема для ответа классификации цели
+var ClassificationResponseSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"category": map[string]any{
+			"type":        "string",
+			"enum":        models.GoalCategories,
+			"description": "Категория цели",
+		},
+	},
+	"required":             []string{"category"},
+	"additionalProperties": false,
+}
+
+// MilestonesResponseSchema схема для ответа составления этапов цели
+var MilestonesResponseSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"milestones": map[string]any{
+			"type":        "array",
+			"items":       map[string]any{"type": "string"},
+			"description": "Названия оставшихся этапов по порядку",
+		},
+	},
+	"required":             []string{"milestones"},
+	"additionalProperties": false,
+}
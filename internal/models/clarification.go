@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Источники уточнений
+const (
+	ClarificationSourceContext = "context" // Ответ на вопрос при сборе контекста
+	ClarificationSourceLegacy  = "legacy"  // Перенесено из старого строкового формата
+)
+
+// Префиксы старого формата "Вопрос: ... | Ответ: ..."
+const (
+	legacyQuestionPrefix = "Вопрос: "
+	legacyAnswerSep      = " | Ответ: "
+)
+
+// Clarification уточняющий вопрос о цели и ответ пользователя
+type Clarification struct {
+	Question string    `json:"question"` // Вопрос, который задал бот
+	Answer   string    `json:"answer"`   // Ответ пользователя
+	AskedAt  time.Time `json:"asked_at"` // Когда был получен ответ
+	Source   string    `json:"source"`   // Откуда взялось уточнение
+}
+
+// UnmarshalJSON читает уточнение, в том числе записанное старой строкой "Вопрос: ... | Ответ: ..."
+func (c *Clarification) UnmarshalJSON(data []byte) error {
+	var legacy string
+	if err := json.Unmarshal(data, &legacy); err == nil {
+		*c = parseLegacyClarification(legacy)
+		return nil
+	}
+
+	// Отдельный тип без методов, чтобы не уйти в рекурсию
+	type plain Clarification
+	var value plain
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*c = Clarification(value)
+	return nil
+}
+
+// parseLegacyClarification разбирает уточнение старого формата
+// Если строка не похожа на пару вопрос-ответ, она целиком считается ответом
+func parseLegacyClarification(text string) Clarification {
+	clarification := Clarification{Answer: text, Source: ClarificationSourceLegacy}
+	if question, answer, found := strings.Cut(strings.TrimPrefix(text, legacyQuestionPrefix), legacyAnswerSep); found {
+		clarification.Question = question
+		clarification.Answer = answer
+	}
+	return clarification
+}
+
+// AddClarification добавляет уточнение в контекст цели
+func (g *Goal) AddClarification(question, answer, source string) {
+	g.Context.Clarifications = append(g.Context.Clarifications, Clarification{
+		Question: question,
+		Answer:   answer,
+		AskedAt:  time.Now(),
+		Source:   source,
+	})
+	g.UpdatedAt = time.Now()
+}
+
+// UpdateClarification заменяет ответ на уточнение с номером index (с нуля)
+func (g *Goal) UpdateClarification(index int, answer string) error {
+	if index < 0 || index >= len(g.Context.Clarifications) {
+		return fmt.Errorf("goal %s has no clarification %d", g.ID, index)
+	}
+	g.Context.Clarifications[index].Answer = answer
+	g.UpdatedAt = time.Now()
+	return nil
+}
+
+// RemoveClarification удаляет уточнение с номером index (с нуля)
+func (g *Goal) RemoveClarification(index int) error {
+	if index < 0 || index >= len(g.Context.Clarifications) {
+		return fmt.Errorf("goal %s has no clarification %d", g.ID, index)
+	}
+	g.Context.Clarifications = append(g.Context.Clarifications[:index], g.Context.Clarifications[index+1:]...)
+	g.UpdatedAt = time.Now()
+	return nil
+}
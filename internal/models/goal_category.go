@@ -0,0 +1,29 @@
+package models
+
+import "slices"
+
+// Категории целей
+// Категорию определяет LLM при создании цели; от нее зависит стратегия генерации шагов
+const (
+	GoalCategoryCreative    = "creative"    // Творческие: музыка, рисование, тексты
+	GoalCategoryEducational = "educational" // Образовательные: языки, программирование, экзамены
+	GoalCategoryHousehold   = "household"   // Бытовые: ремонт, уборка, переезд, документы
+	GoalCategoryHealth      = "health"      // Здоровье и спорт
+	GoalCategoryCareer      = "career"      // Работа, карьера, бизнес, финансы
+	GoalCategoryOther       = "other"       // Не подходит ни под одну категорию
+)
+
+// GoalCategories все категории целей
+var GoalCategories = []string{
+	GoalCategoryCreative,
+	GoalCategoryEducational,
+	GoalCategoryHousehold,
+	GoalCategoryHealth,
+	GoalCategoryCareer,
+	GoalCategoryOther,
+}
+
+// IsGoalCategory проверяет, что category - известная категория цели
+func IsGoalCategory(category string) bool {
+	return slices.Contains(GoalCategories, category)
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Статусы цели
+const (
+	GoalStatusActive    = "active"    // Пользователь работает над целью
+	GoalStatusPaused    = "paused"    // Цель отложена, к ней можно вернуться
+	GoalStatusAbandoned = "abandoned" // Пользователь отказался от цели (её тоже можно вернуть)
+	GoalStatusCompleted = "completed" // Цель достигнута, статус окончательный
+)
+
+// GoalStatuses все статусы целей в порядке отображения
+var GoalStatuses = []string{GoalStatusActive, GoalStatusPaused, GoalStatusAbandoned, GoalStatusCompleted}
+
+// goalTransitions допустимые переходы между статусами цели
+var goalTransitions = map[string][]string{
+	GoalStatusActive:    {GoalStatusPaused, GoalStatusAbandoned, GoalStatusCompleted},
+	GoalStatusPaused:    {GoalStatusActive, GoalStatusAbandoned, GoalStatusCompleted},
+	GoalStatusAbandoned: {GoalStatusActive},
+}
+
+// CanTransition проверяет, можно ли перевести цель в статус status
+func (g *Goal) CanTransition(status string) bool {
+	return slices.Contains(goalTransitions[g.Status], status)
+}
+
+// SetStatus переводит цель в новый статус с необязательной причиной
+// (например, почему цель отложена). При возврате в работу причина сбрасывается
+func (g *Goal) SetStatus(status, reason string) error {
+	if !g.CanTransition(status) {
+		return fmt.Errorf("goal %s cannot change status from %q to %q", g.ID, g.Status, status)
+	}
+
+	now := time.Now()
+	g.Status = status
+	g.StatusReason = reason
+	g.UpdatedAt = now
+
+	if status == GoalStatusCompleted {
+		g.CompletedAt = &now
+	}
+
+	return nil
+}
+
+// IsActive проверяет, находится ли цель в работе
+func (g *Goal) IsActive() bool {
+	return g.Status == GoalStatusActive
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Milestone промежуточный этап большой цели
+// Пользователь видит только текущий этап, план целиком - по команде /milestones
+type Milestone struct {
+	ID          string     `json:"id"`                     // Уникальный ID этапа
+	Title       string     `json:"title"`                  // Название этапа
+	CompletedAt *time.Time `json:"completed_at,omitempty"` // Когда этап пройден
+}
+
+// IsCompleted проверяет, пройден ли этап
+func (m *Milestone) IsCompleted() bool {
+	return m.CompletedAt != nil
+}
+
+// HasMilestones проверяет, разбита ли цель на этапы
+func (g *Goal) HasMilestones() bool {
+	return len(g.Milestones) > 0
+}
+
+// CurrentMilestone возвращает первый непройденный этап и его номер (с нуля)
+// Если этапов нет или все пройдены, возвращает nil и -1
+func (g *Goal) CurrentMilestone() (*Milestone, int) {
+	for i := range g.Milestones {
+		if !g.Milestones[i].IsCompleted() {
+			return &g.Milestones[i], i
+		}
+	}
+	return nil, -1
+}
+
+// CompletedMilestones возвращает количество пройденных этапов
+func (g *Goal) CompletedMilestones() int {
+	count := 0
+	for _, milestone := range g.Milestones {
+		if milestone.IsCompleted() {
+			count++
+		}
+	}
+	return count
+}
+
+// PlanMilestones заменяет непройденные этапы новым планом; пройденные этапы остаются в начале
+func (g *Goal) PlanMilestones(titles []string) {
+	milestones := make([]Milestone, 0, len(g.Milestones)+len(titles))
+	for _, milestone := range g.Milestones {
+		if milestone.IsCompleted() {
+			milestones = append(milestones, milestone)
+		}
+	}
+	for _, title := range titles {
+		milestones = append(milestones, Milestone{ID: uuid.New().String(), Title: title})
+	}
+	g.Milestones = milestones
+	g.UpdatedAt = time.Now()
+}
+
+// AddMilestone добавляет этап в конец плана
+func (g *Goal) AddMilestone(title string) {
+	g.Milestones = append(g.Milestones, Milestone{ID: uuid.New().String(), Title: title})
+	g.UpdatedAt = time.Now()
+}
+
+// RenameMilestone меняет название этапа с номером index (с нуля)
+func (g *Goal) RenameMilestone(index int, title string) error {
+	if index < 0 || index >= len(g.Milestones) {
+		return fmt.Errorf("goal %s has no milestone %d", g.ID, index)
+	}
+	g.Milestones[index].Title = title
+	g.UpdatedAt = time.Now()
+	return nil
+}
+
+// RemoveMilestone удаляет этап с номером index (с нуля)
+// Шаги удаленного этапа остаются у цели
+func (g *Goal) RemoveMilestone(index int) error {
+	if index < 0 || index >= len(g.Milestones) {
+		return fmt.Errorf("goal %s has no milestone %d", g.ID, index)
+	}
+	g.Milestones = append(g.Milestones[:index], g.Milestones[index+1:]...)
+	g.UpdatedAt = time.Now()
+	return nil
+}
+
+// ClearMilestones убирает этапы: цель снова ведется шаг за шагом без плана
+func (g *Goal) ClearMilestones() {
+	g.Milestones = nil
+	g.UpdatedAt = time.Now()
+}
+
+// CompleteCurrentMilestone отмечает текущий этап пройденным и возвращает его
+func (g *Goal) CompleteCurrentMilestone() (*Milestone, error) {
+	milestone, _ := g.CurrentMilestone()
+	if milestone == nil {
+		return nil, fmt.Errorf("goal %s has no milestone in progress", g.ID)
+	}
+
+	now := time.Now()
+	milestone.CompletedAt = &now
+	g.UpdatedAt = now
+	return milestone, nil
+}
+
+// NewStep создает шаг цели, привязанный к текущему этапу (если цель разбита на этапы)
+func (g *Goal) NewStep(text string) *Step {
+	step := NewStep(g.ID, text)
+	if milestone, _ := g.CurrentMilestone(); milestone != nil {
+		step.MilestoneID = milestone.ID
+	}
+	return step
+}
@@ -2,7 +2,7 @@ package models
 
 import (
 	"fmt"
-	"strings"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,11 +10,38 @@ import (
 
 // User представляет пользователя Telegram
 type User struct {
-	ID           string    `json:"id"`                       // Telegram User ID
-	Username     string    `json:"username"`                 // Telegram username
-	FirstName    string    `json:"first_name"`               // Имя пользователя
-	CreatedAt    time.Time `json:"created_at"`               // Дата создания
-	ActiveGoalID string    `json:"active_goal_id,omitempty"` // ID активной цели
+	ID           string       `json:"id"`                       // Telegram User ID
+	Username     string       `json:"username"`                 // Telegram username
+	FirstName    string       `json:"first_name"`               // Имя пользователя
+	CreatedAt    time.Time    `json:"created_at"`               // Дата создания
+	ActiveGoalID string       `json:"active_goal_id,omitempty"` // ID цели в фокусе (остальные цели в статусе active тоже в работе)
+	LanguageCode string       `json:"language_code,omitempty"`  // Язык клиента Telegram, например "en-US"
+	Settings     UserSettings `json:"settings"`                 // Настройки пользователя
+}
+
+// UserSettings содержит настройки пользователя
+// Нулевые значения означают настройки по умолчанию
+type UserSettings struct {
+	Timezone   string           `json:"timezone,omitempty"` // Часовой пояс IANA, например "Europe/Moscow" (пусто - часовой пояс сервера)
+	QuietHours QuietHours       `json:"quiet_hours"`        // Время, когда бот не пишет первым
+	Reminders  ReminderSettings `json:"reminders"`          // Напоминания о зависших шагах
+	Language   string           `json:"language,omitempty"` // Язык, выбранный в /language (пусто - как в Telegram)
+	Persona    string           `json:"persona,omitempty"`  // Тон ассистента, выбранный в /persona (пусто - по умолчанию)
+}
+
+// QuietHours интервал "тихих часов" в местном времени пользователя
+// Интервал может переходить через полночь, например 22:00-08:00
+type QuietHours struct {
+	Start string `json:"start,omitempty"` // Начало "ЧЧ:ММ" (пусто - тихие часы выключены)
+	End   string `json:"end,omitempty"`   // Конец "ЧЧ:ММ"
+}
+
+// ReminderSettings описывает, когда напоминать о невыполненном шаге
+type ReminderSettings struct {
+	Disabled  bool           `json:"disabled,omitempty"`   // Напоминания выключены
+	IdleHours int            `json:"idle_hours,omitempty"` // Через сколько часов бездействия напоминать (0 - по умолчанию)
+	Time      string         `json:"time,omitempty"`       // Удобное время напоминания "ЧЧ:ММ" (пусто - любое)
+	Days      []time.Weekday `json:"days,omitempty"`       // Дни недели для напоминаний (пусто - каждый день)
 }
 
 // Goal представляет цель пользователя
@@ -26,8 +53,14 @@ type Goal struct {
 	CreatedAt   time.Time  `json:"created_at"`             // Дата создания
 	UpdatedAt   time.Time  `json:"updated_at"`             // Дата обновления
 	Context     Context    `json:"context"`                // Контекст для LLM
-	Status      string     `json:"status"`                 // "active", "completed", "abandoned"
+	Status      string     `json:"status"`                 // "active", "paused", "abandoned", "completed"
 	CompletedAt *time.Time `json:"completed_at,omitempty"` // Дата завершения
+
+	StatusReason string `json:"status_reason,omitempty"` // Причина смены статуса: почему цель отложена, брошена или достигнута
+	Category     string `json:"category,omitempty"`      // Категория цели: "creative", "educational", ... (пусто - не определена)
+	Persona      string `json:"persona,omitempty"`       // Персона для этой цели (пусто - как в настройках пользователя)
+
+	Milestones []Milestone `json:"milestones,omitempty"` // Этапы большой цели по порядку (пусто - цель без этапов)
 }
 
 // Step представляет шаг к достижению цели
@@ -39,12 +72,64 @@ type Step struct {
 	CompletedAt *time.Time `json:"completed_at,omitempty"` // Дата выполнения
 	Rephrased   bool       `json:"rephrased"`              // Был ли переформулирован
 	UserComment string     `json:"user_comment,omitempty"` // Комментарий пользователя
+
+	Rephrases       int `json:"rephrases,omitempty"`       // Сколько раз переформулирован по просьбе пользователя
+	Simplifications int `json:"simplifications,omitempty"` // Сколько раз упрощен
+
+	History []StepRevision `json:"history,omitempty"` // Прежние формулировки шага, от старых к новым
+
+	SkippedAt  *time.Time `json:"skipped_at,omitempty"`  // Дата пропуска (шаг не выполнен, но больше не нужен)
+	SkipReason string     `json:"skip_reason,omitempty"` // Почему пользователь пропустил шаг
+
+	Difficulty     string `json:"difficulty,omitempty"`      // Оценка сложности после выполнения: "too_easy", "right", "too_hard"
+	CompletionNote string `json:"completion_note,omitempty"` // Как прошло и что пользователь узнал
+
+	MilestoneID string `json:"milestone_id,omitempty"` // Этап цели, к которому относится шаг
+}
+
+// Оценки сложности выполненного шага
+const (
+	StepDifficultyTooEasy = "too_easy"
+	StepDifficultyRight   = "right"
+	StepDifficultyTooHard = "too_hard"
+)
+
+// Виды изменения формулировки шага
+const (
+	StepChangeRephrase = "rephrase"
+	StepChangeSimplify = "simplify"
+)
+
+// StepRevision прежняя формулировка шага, которую пользователь попросил изменить
+type StepRevision struct {
+	Text      string    `json:"text"`              // Отклоненный текст шага
+	Comment   string    `json:"comment,omitempty"` // Комментарий пользователя, почему шаг не подошел
+	Kind      string    `json:"kind"`              // "rephrase" или "simplify"
+	ChangedAt time.Time `json:"changed_at"`        // Когда формулировка была заменена
+}
+
+// UserState представляет состояние пользователя в FSM бота
+type UserState struct {
+	UserID    int64             `json:"user_id"`    // Telegram User ID
+	State     string            `json:"state"`      // "idle", "waiting_goal_description", "rephrasing", "gathering_context"
+	TempData  map[string]string `json:"temp_data"`  // Временные данные диалога
+	UpdatedAt time.Time         `json:"updated_at"` // Дата последнего изменения
+}
+
+// Reminder представляет запланированное напоминание о шаге
+// У пользователя не больше одного напоминания - о текущем шаге активной цели
+type Reminder struct {
+	UserID string    `json:"user_id"` // Telegram User ID
+	GoalID string    `json:"goal_id"` // ID цели
+	StepID string    `json:"step_id"` // ID шага, о котором напоминаем
+	DueAt  time.Time `json:"due_at"`  // Когда отправить напоминание
+	Nudges int       `json:"nudges"`  // Сколько напоминаний об этом шаге уже отправлено
 }
 
 // Context содержит дополнительную информацию для LLM
 type Context struct {
-	Clarifications []string `json:"clarifications"`  // Уточняющие вопросы и ответы
-	Notes          string   `json:"notes,omitempty"` // Дополнительные заметки
+	Clarifications []Clarification `json:"clarifications"`  // Уточняющие вопросы и ответы
+	Notes          string          `json:"notes,omitempty"` // Дополнительные заметки
 }
 
 // NewUser создает нового пользователя
@@ -66,11 +151,22 @@ func NewGoal(userID, title, description string) *Goal {
 		Description: description,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
-		Context:     Context{Clarifications: []string{}},
-		Status:      "active",
+		Context:     Context{Clarifications: []Clarification{}},
+		Status:      GoalStatusActive,
 	}
 }
 
+// SortGoals сортирует цели по порядку создания, при совпадении времени - по ID,
+// чтобы порядок не зависел от хранилища
+func SortGoals(goals []*Goal) {
+	sort.Slice(goals, func(i, j int) bool {
+		if !goals[i].CreatedAt.Equal(goals[j].CreatedAt) {
+			return goals[i].CreatedAt.Before(goals[j].CreatedAt)
+		}
+		return goals[i].ID < goals[j].ID
+	})
+}
+
 // NewStep создает новый шаг
 func NewStep(goalID, text string) *Step {
 	return &Step{
@@ -82,40 +178,90 @@ func NewStep(goalID, text string) *Step {
 	}
 }
 
+// NewUserState создает состояние пользователя в FSM
+func NewUserState(userID int64, state string) *UserState {
+	return &UserState{
+		UserID:    userID,
+		State:     state,
+		TempData:  make(map[string]string),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// IsExpired проверяет, не изменялось ли состояние дольше ttl
+func (s *UserState) IsExpired(ttl time.Duration) bool {
+	return time.Since(s.UpdatedAt) > ttl
+}
+
 // IsCompleted проверяет, выполнен ли шаг
 func (s *Step) IsCompleted() bool {
 	return s.CompletedAt != nil
 }
 
+// IsSkipped проверяет, пропущен ли шаг
+func (s *Step) IsSkipped() bool {
+	return s.SkippedAt != nil
+}
+
+// IsFinished проверяет, закрыт ли шаг: выполнен или пропущен
+func (s *Step) IsFinished() bool {
+	return s.IsCompleted() || s.IsSkipped()
+}
+
+// Skip отмечает шаг как пропущенный с необязательной причиной
+func (s *Step) Skip(reason string) {
+	now := time.Now()
+	s.SkippedAt = &now
+	s.SkipReason = reason
+}
+
+// Rate сохраняет оценку сложности выполненного шага
+func (s *Step) Rate(difficulty string) error {
+	switch difficulty {
+	case StepDifficultyTooEasy, StepDifficultyRight, StepDifficultyTooHard:
+		s.Difficulty = difficulty
+		return nil
+	default:
+		return fmt.Errorf("unknown step difficulty: %s", difficulty)
+	}
+}
+
 // Complete отмечает шаг как выполненный
 func (s *Step) Complete() {
 	now := time.Now()
 	s.CompletedAt = &now
 }
 
-// Rephrase отмечает шаг как переформулированный
-func (s *Step) Rephrase(comment string) {
-	s.Rephrased = true
-	s.UserComment = comment
+// Rephrase заменяет текст шага новой формулировкой, сохраняя прежнюю в истории
+func (s *Step) Rephrase(text, comment string) {
+	s.revise(StepChangeRephrase, text, comment)
+	s.Rephrases++
 }
 
-// AddClarification добавляет уточнение в контекст цели
-func (g *Goal) AddClarification(question, answer string) {
-	clarification := fmt.Sprintf("Вопрос: %s | Ответ: %s", question, answer)
-	g.Context.Clarifications = append(g.Context.Clarifications, clarification)
-	g.UpdatedAt = time.Now()
+// Simplify заменяет текст шага упрощенным, сохраняя прежний в истории
+func (s *Step) Simplify(text, comment string) {
+	s.revise(StepChangeSimplify, text, comment)
+	s.Simplifications++
 }
 
-// GetContextSummary возвращает краткое описание собранного контекста
-func (g *Goal) GetContextSummary() string {
-	if len(g.Context.Clarifications) == 0 {
-		return "Контекст не собран"
-	}
+// revise переносит текущий текст шага в историю и заменяет его новым
+func (s *Step) revise(kind, text, comment string) {
+	s.History = append(s.History, StepRevision{
+		Text:      s.Text,
+		Comment:   comment,
+		Kind:      kind,
+		ChangedAt: time.Now(),
+	})
+	s.Text = text
+	s.Rephrased = true
+	s.UserComment = comment
+}
 
-	var summary strings.Builder
-	summary.WriteString(fmt.Sprintf("Собрано %d уточнений:\n", len(g.Context.Clarifications)))
-	for i, clarification := range g.Context.Clarifications {
-		summary.WriteString(fmt.Sprintf("%d. %s\n", i+1, clarification))
+// RejectedTexts возвращает формулировки шага, которые пользователь отклонил
+func (s *Step) RejectedTexts() []string {
+	texts := make([]string, 0, len(s.History))
+	for _, revision := range s.History {
+		texts = append(texts, revision.Text)
 	}
-	return summary.String()
+	return texts
 }
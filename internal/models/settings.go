@@ -0,0 +1,93 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ClockLayout формат времени суток в настройках пользователя
+const ClockLayout = "15:04"
+
+// Location возвращает часовой пояс пользователя
+// Если пояс не задан или неизвестен, используется часовой пояс сервера
+func (s UserSettings) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.Local
+	}
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return location
+}
+
+// Now возвращает текущее время в часовом поясе пользователя
+func (s UserSettings) Now() time.Time {
+	return time.Now().In(s.Location())
+}
+
+// Enabled проверяет, заданы ли тихие часы
+func (q QuietHours) Enabled() bool {
+	return q.Start != "" && q.End != "" && q.Start != q.End
+}
+
+// Contains проверяет, попадает ли момент t (в местном времени) в тихие часы
+func (q QuietHours) Contains(t time.Time) bool {
+	if !q.Enabled() {
+		return false
+	}
+
+	start, errStart := parseClockMinutes(q.Start)
+	end, errEnd := parseClockMinutes(q.End)
+	if errStart != nil || errEnd != nil {
+		return false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	// Интервал через полночь
+	return minute >= start || minute < end
+}
+
+// NextEnd возвращает ближайший после t момент окончания тихих часов
+func (q QuietHours) NextEnd(t time.Time) time.Time {
+	end, err := AtClock(t, q.End)
+	if err != nil {
+		return t
+	}
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// String возвращает тихие часы в виде "ЧЧ:ММ-ЧЧ:ММ"
+func (q QuietHours) String() string {
+	return q.Start + "-" + q.End
+}
+
+// AllowsDay проверяет, можно ли напоминать в этот день недели
+func (r ReminderSettings) AllowsDay(day time.Weekday) bool {
+	return len(r.Days) == 0 || slices.Contains(r.Days, day)
+}
+
+// AtClock возвращает момент в тот же день, что и t, в указанное время суток "ЧЧ:ММ"
+func AtClock(t time.Time, clock string) (time.Time, error) {
+	minutes, err := parseClockMinutes(clock)
+	if err != nil {
+		return t, err
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), minutes/60, minutes%60, 0, 0, t.Location()), nil
+}
+
+// parseClockMinutes разбирает время суток "ЧЧ:ММ" в минуты от полуночи
+func parseClockMinutes(clock string) (int, error) {
+	parsed, err := time.Parse(ClockLayout, clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %w", clock, err)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
@@ -0,0 +1,222 @@
+package persona
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"goal-helper/internal/i18n"
+)
+
+// DefaultPersona персона по умолчанию: на ее тон рассчитаны исходные тексты бота
+const DefaultPersona = "friendly"
+
+// Встроенные персоны лежат в personas/<id>.json и используются, если в папке персон
+// нет файла с тем же именем (см. Load)
+//
+//go:embed personas/*.json
+var personaFiles embed.FS
+
+// Persona тон, в котором ассистент общается с пользователем
+// Тексты задаются по языкам
+type Persona struct {
+	ID       string                       `json:"-"`        // Идентификатор - имя файла без расширения
+	Icon     string                       `json:"icon"`     // Иконка для кнопок выбора
+	Order    int                          `json:"order"`    // Порядок в списке выбора
+	Names    map[string]string            `json:"name"`     // Название персоны по языкам
+	Prompts  map[string]string            `json:"prompt"`   // Блок для промптов LLM по языкам
+	Messages map[string]map[string]string `json:"messages"` // Свои шаблоны сообщений бота: язык -> ключ -> текст
+}
+
+var (
+	personasMutex sync.RWMutex
+	personas      []*Persona // Загруженные персоны в порядке показа пользователю (nil - еще не загружены)
+)
+
+// Load загружает персоны: встроенные в бинарник и файлы <dir>/<id>.json.
+// Файл в dir заменяет встроенную персону с тем же идентификатором, поэтому персону можно
+// добавить или поправить без пересборки. Если dir пуст или папки нет, используются встроенные персоны.
+// При ошибке (например, неизвестный ключ в messages) остаются ранее загруженные персоны
+func Load(dir string) error {
+	loaded := make(map[string]*Persona)
+	if err := readPersonas(personaFiles, "personas", loaded); err != nil {
+		return fmt.Errorf("built-in personas: %w", err)
+	}
+	if dir != "" {
+		err := readPersonas(os.DirFS(dir), ".", loaded)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("personas in %s: %w", dir, err)
+		}
+	}
+
+	if _, ok := loaded[DefaultPersona]; !ok {
+		return fmt.Errorf("default persona %q is missing", DefaultPersona)
+	}
+
+	result := make([]*Persona, 0, len(loaded))
+	for _, persona := range loaded {
+		result = append(result, persona)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Order != result[j].Order {
+			return result[i].Order < result[j].Order
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	personasMutex.Lock()
+	personas = result
+	personasMutex.Unlock()
+	return nil
+}
+
+// readPersonas читает файлы *.json из папки dir в loaded (ключ - имя файла без расширения)
+func readPersonas(fsys fs.FS, dir string, loaded map[string]*Persona) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+
+		var persona Persona
+		if err := json.Unmarshal(data, &persona); err != nil {
+			return fmt.Errorf("invalid %s: %w", entry.Name(), err)
+		}
+		persona.ID = strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+
+		// Опечатка в ключе молча оставила бы стандартный текст
+		for language, messages := range persona.Messages {
+			for key := range messages {
+				if !i18n.Has(key) {
+					return fmt.Errorf("%s overrides unknown message %q (%s)", entry.Name(), key, language)
+				}
+			}
+		}
+
+		loaded[persona.ID] = &persona
+	}
+
+	return nil
+}
+
+// loadedPersonas возвращает загруженные персоны.
+// Если Load еще не вызывался (например, в тестах), загружаются встроенные персоны
+func loadedPersonas() []*Persona {
+	personasMutex.RLock()
+	result := personas
+	personasMutex.RUnlock()
+	if result != nil {
+		return result
+	}
+
+	if err := Load(""); err != nil {
+		// Встроенные персоны проверяются тестами, но бот не должен падать из-за них
+		log.Printf("❌ Ошибка при загрузке персон: %v", err)
+		personasMutex.Lock()
+		if personas == nil {
+			personas = []*Persona{{ID: DefaultPersona}}
+		}
+		personasMutex.Unlock()
+	}
+
+	personasMutex.RLock()
+	defer personasMutex.RUnlock()
+	return personas
+}
+
+// find ищет персону по идентификатору
+func find(list []*Persona, id string) (*Persona, bool) {
+	for _, persona := range list {
+		if persona.ID == id {
+			return persona, true
+		}
+	}
+	return nil, false
+}
+
+// All возвращает все персоны в порядке показа
+func All() []*Persona {
+	return append([]*Persona(nil), loadedPersonas()...)
+}
+
+// Exists проверяет, что персона с таким идентификатором есть
+func Exists(id string) bool {
+	_, ok := find(loadedPersonas(), id)
+	return ok
+}
+
+// Get возвращает персону; неизвестная или пустая заменяется персоной по умолчанию
+func Get(id string) *Persona {
+	list := loadedPersonas()
+	if persona, ok := find(list, id); ok {
+		return persona
+	}
+	persona, _ := find(list, DefaultPersona)
+	return persona
+}
+
+// Resolve выбирает персону: заданную для цели, иначе выбранную пользователем, иначе по умолчанию
+func Resolve(goalPersona, userPersona string) string {
+	if Exists(goalPersona) {
+		return goalPersona
+	}
+	if Exists(userPersona) {
+		return userPersona
+	}
+	return DefaultPersona
+}
+
+// Name возвращает название персоны на языке language
+func (p *Persona) Name(language string) string {
+	if name, ok := p.Names[language]; ok {
+		return name
+	}
+	if name, ok := p.Names[i18n.DefaultLanguage]; ok {
+		return name
+	}
+	return p.ID
+}
+
+// Prompt возвращает блок для промптов LLM на языке language
+// Без перевода блок не добавляется, чтобы не сбить LLM на другой язык
+func (p *Persona) Prompt(language string) string {
+	return p.Prompts[language]
+}
+
+// MessageOverrides возвращает шаблоны сообщений на языке language, которыми персона заменяет стандартные
+// Шаблоны не переводятся с другого языка: без них остаются стандартные тексты
+func (p *Persona) MessageOverrides(language string) map[string]string {
+	return p.Messages[language]
+}
+
+// personaKey ключ персоны в context.Context
+type personaKey struct{}
+
+// WithPersona возвращает контекст, в котором LLM отвечает от лица персоны id
+func WithPersona(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, personaKey{}, id)
+}
+
+// FromContext возвращает персону запроса (персону по умолчанию, если она не задана)
+func FromContext(ctx context.Context) *Persona {
+	id, _ := ctx.Value(personaKey{}).(string)
+	return Get(id)
+}
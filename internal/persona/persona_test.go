@@ -0,0 +1,85 @@
+package persona
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writePersona кладет файл персоны в папку dir
+func writePersona(t *testing.T, dir, name, data string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// resetPersonas возвращает встроенные персоны после теста
+func resetPersonas(t *testing.T) {
+	t.Cleanup(func() {
+		if err := Load(""); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestLoadBuiltIn(t *testing.T) {
+	resetPersonas(t)
+
+	if err := Load(filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Fatalf("Load with missing directory: %v", err)
+	}
+	for _, id := range []string{"friendly", "serious", "motivational"} {
+		if !Exists(id) {
+			t.Errorf("built-in persona %q is missing", id)
+		}
+	}
+	if got := Get("unknown").ID; got != DefaultPersona {
+		t.Errorf("Get(unknown) = %q, want %q", got, DefaultPersona)
+	}
+}
+
+func TestLoadDirectory(t *testing.T) {
+	resetPersonas(t)
+
+	dir := t.TempDir()
+	writePersona(t, dir, "calm.json", `{"icon": "🌿", "order": 10, "name": {"en": "Calm"}, "prompt": {"en": "Tone: calm"}}`)
+	writePersona(t, dir, "serious.json", `{"icon": "🎩", "order": 2, "name": {"en": "Very serious"}}`)
+	writePersona(t, dir, "notes.txt", `not a persona`)
+
+	if err := Load(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if !Exists("calm") || Get("calm").Prompt("en") != "Tone: calm" {
+		t.Error("persona from the directory was not loaded")
+	}
+	if got := Get("serious").Name("en"); got != "Very serious" {
+		t.Errorf("serious persona name = %q, want it replaced by the file", got)
+	}
+	if all := All(); all[len(all)-1].ID != "calm" {
+		t.Errorf("last persona = %q, want calm (highest order)", all[len(all)-1].ID)
+	}
+}
+
+func TestLoadInvalidKeepsPrevious(t *testing.T) {
+	resetPersonas(t)
+
+	tests := map[string]string{
+		"malformed JSON":  `{"icon": `,
+		"unknown message": `{"messages": {"en": {"no_such_message": "text"}}}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writePersona(t, dir, "broken.json", data)
+
+			if err := Load(dir); err == nil {
+				t.Fatal("Load succeeded with a broken persona, want error")
+			}
+			if Exists("broken") || !Exists(DefaultPersona) {
+				t.Error("broken load replaced the loaded personas")
+			}
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package repository
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -12,10 +17,32 @@ import (
 	"goal-helper/internal/models"
 )
 
+// Имена файлов с данными
+const (
+	usersFileName   = "users.json"
+	goalsFileName   = "goals.json"
+	stepsFileName   = "steps.json"
+	journalFileName = "journal.jsonl"
+)
+
+// Суффиксы файлов поколений снапшота
+const (
+	snapshotBackupSuffix = ".bak" // Предыдущее поколение снапшота
+	snapshotNextSuffix   = ".new" // Новое поколение, которое еще не заменило текущее
+)
+
+// journalCompactThreshold количество записей в журнале, после которого
+// снапшоты перезаписываются целиком, а журнал очищается
+const journalCompactThreshold = 500
+
 // FileRepository реализует Repository интерфейс через JSON файлы
+// Снапшоты пишутся атомарно, а все мутации предварительно попадают в журнал,
+// который проигрывается при старте, если снапшоты отстают от него
 type FileRepository struct {
-	dataDir string
-	mutex   sync.RWMutex
+	dataDir   string
+	mutex     sync.RWMutex
+	saveMutex sync.Mutex // Сериализует запись снапшотов
+	journal   *journal
 
 	// Кэш данных в памяти для быстрого доступа
 	users map[string]*models.User
@@ -42,6 +69,29 @@ func NewFileRepository(dataDir string) (*FileRepository, error) {
 		return nil, fmt.Errorf("failed to load data: %w", err)
 	}
 
+	// Открываем журнал и проигрываем мутации, которые не успели попасть в снапшоты
+	j, entries, err := openJournal(filepath.Join(dataDir, journalFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	repo.journal = j
+
+	if len(entries) > 0 {
+		log.Printf("🔍 Проигрываем %d записей журнала", len(entries))
+		for _, entry := range entries {
+			if err := repo.applyEntry(entry); err != nil {
+				j.close()
+				return nil, fmt.Errorf("failed to replay journal entry %d: %w", entry.Seq, err)
+			}
+		}
+
+		// Фиксируем восстановленное состояние в снапшотах
+		if err := repo.compact(); err != nil {
+			j.close()
+			return nil, fmt.Errorf("failed to compact journal: %w", err)
+		}
+	}
+
 	return repo, nil
 }
 
@@ -65,21 +115,100 @@ func (r *FileRepository) loadData() error {
 	return nil
 }
 
-// loadUsers загружает пользователей из файла
-func (r *FileRepository) loadUsers() error {
-	filename := filepath.Join(r.dataDir, "users.json")
+// loadSnapshot читает JSON снапшот в v.
+// Если файл поврежден или пропал, данные берутся из предыдущего поколения (*.bak),
+// а более новые изменения проигрываются из журнала: он очищается только тогда,
+// когда оба поколения совпадают (см. compact). Поврежденный файл переименовывается в *.corrupted.
+// Если восстановиться не из чего, возвращается ошибка: продолжать с пустыми данными нельзя
+func (r *FileRepository) loadSnapshot(name string, v any) error {
+	filename := filepath.Join(r.dataDir, name)
+
+	err := readSnapshot(filename, v)
+	if err == nil {
+		return nil
+	}
+	missing := errors.Is(err, os.ErrNotExist)
+
+	backupErr := readSnapshot(filename+snapshotBackupSuffix, v)
+	if missing && errors.Is(backupErr, os.ErrNotExist) {
+		// Данных еще нет
+		return nil
+	}
+	if backupErr != nil {
+		return fmt.Errorf("snapshot %s cannot be loaded (%v) and its backup cannot be recovered: %w", filename, err, backupErr)
+	}
+
+	if !missing {
+		corrupted := fmt.Sprintf("%s.corrupted-%d", filename, time.Now().Unix())
+		if err := os.Rename(filename, corrupted); err != nil {
+			return fmt.Errorf("failed to move corrupted file: %w", err)
+		}
+		log.Printf("⚠️ Файл %s поврежден (%v), переименован в %s", filename, err, corrupted)
+	}
+	log.Printf("⚠️ Снапшот %s восстановлен из предыдущего поколения, новые изменения будут восстановлены из журнала", filename)
 
+	return nil
+}
+
+// readSnapshot читает и разбирает файл снапшота
+func readSnapshot(filename string, v any) error {
 	data, err := os.ReadFile(filename)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Файл не существует, создаем пустой
-			return r.saveUsers()
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeSnapshot записывает новое поколение снапшота, сохраняя предыдущее в *.bak.
+// Новая версия сначала записывается рядом, сбрасывается на диск и проверяется,
+// и только после этого текущий файл становится резервной копией.
+// При сжатии журнала (compacting) резервная копия тоже получает новую версию:
+// записи журнала, которые отделяют старое поколение от нового, будут удалены
+func writeSnapshot(filename string, data []byte, compacting bool) error {
+	backup := filename + snapshotBackupSuffix
+	if compacting {
+		if err := writeVerified(backup, data); err != nil {
+			return err
 		}
+		return writeVerified(filename, data)
+	}
+
+	next := filename + snapshotNextSuffix
+	if err := writeVerified(next, data); err != nil {
 		return err
 	}
+	if err := os.Rename(filename, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to keep previous snapshot: %w", err)
+	}
+	if err := os.Rename(next, filename); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+	syncDir(filepath.Dir(filename))
 
+	return nil
+}
+
+// writeVerified атомарно записывает файл и проверяет, что на диске оказались именно эти данные
+func writeVerified(filename string, data []byte) error {
+	if err := writeFileAtomic(filename, data, 0644); err != nil {
+		return err
+	}
+
+	written, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to verify %s: %w", filename, err)
+	}
+	if !bytes.Equal(written, data) {
+		return fmt.Errorf("failed to verify %s: content mismatch", filename)
+	}
+
+	return nil
+}
+
+// loadUsers загружает пользователей из файла
+func (r *FileRepository) loadUsers() error {
 	var users []*models.User
-	if err := json.Unmarshal(data, &users); err != nil {
+	if err := r.loadSnapshot(usersFileName, &users); err != nil {
 		return err
 	}
 
@@ -93,123 +222,239 @@ func (r *FileRepository) loadUsers() error {
 	return nil
 }
 
-// saveUsers сохраняет пользователей в файл
-func (r *FileRepository) saveUsers() error {
+// loadGoals загружает цели из файла
+func (r *FileRepository) loadGoals() error {
+	var goals []*models.Goal
+	if err := r.loadSnapshot(goalsFileName, &goals); err != nil {
+		return err
+	}
 
-	r.mutex.RLock()
-	users := make([]*models.User, 0, len(r.users))
-	for _, user := range r.users {
-		users = append(users, user)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, goal := range goals {
+		migrateGoal(goal)
+		r.goals[goal.ID] = goal
 	}
-	r.mutex.RUnlock()
 
-	data, err := json.MarshalIndent(users, "", "  ")
-	if err != nil {
+	return nil
+}
+
+// loadSteps загружает шаги из файла
+func (r *FileRepository) loadSteps() error {
+	var steps []*models.Step
+	if err := r.loadSnapshot(stepsFileName, &steps); err != nil {
 		return err
 	}
 
-	filename := filepath.Join(r.dataDir, "users.json")
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 
-	err = os.WriteFile(filename, data, 0644)
-	if err != nil {
-		return err
+	for _, step := range steps {
+		migrateStep(step)
+		r.steps[step.ID] = step
 	}
 
 	return nil
 }
 
-// loadGoals загружает цели из файла
-func (r *FileRepository) loadGoals() error {
-	filename := filepath.Join(r.dataDir, "goals.json")
+// migrateGoal приводит цель из старого снапшота к текущему формату, как это делают миграции SQLite.
+// Неизвестный статус (например, "inactive") возвращает цель в работу, а уточнениям
+// старого формата, у которых не сохранялось время ответа, проставляется время создания цели
+func migrateGoal(goal *models.Goal) {
+	if !slices.Contains(models.GoalStatuses, goal.Status) {
+		goal.Status = models.GoalStatusActive
+	}
 
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return r.saveGoals()
+	for i := range goal.Context.Clarifications {
+		if goal.Context.Clarifications[i].AskedAt.IsZero() {
+			goal.Context.Clarifications[i].AskedAt = goal.CreatedAt
 		}
-		return err
 	}
+}
 
-	var goals []*models.Goal
-	if err := json.Unmarshal(data, &goals); err != nil {
-		return err
+// migrateStep приводит шаг из старого снапшота к текущему формату:
+// раньше хранился только флаг rephrased - считаем такие шаги переформулированными один раз
+func migrateStep(step *models.Step) {
+	if step.Rephrased && step.Rephrases == 0 {
+		step.Rephrases = 1
 	}
+}
 
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	for _, goal := range goals {
-		r.goals[goal.ID] = goal
+// marshalUsers сериализует пользователей (вызывается под блокировкой)
+func (r *FileRepository) marshalUsers() ([]byte, error) {
+	users := make([]*models.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
 	}
-
-	return nil
+	return json.MarshalIndent(users, "", "  ")
 }
 
-// saveGoals сохраняет цели в файл
-func (r *FileRepository) saveGoals() error {
-	r.mutex.RLock()
+// marshalGoals сериализует цели (вызывается под блокировкой)
+func (r *FileRepository) marshalGoals() ([]byte, error) {
 	goals := make([]*models.Goal, 0, len(r.goals))
 	for _, goal := range r.goals {
 		goals = append(goals, goal)
 	}
-	r.mutex.RUnlock()
+	return json.MarshalIndent(goals, "", "  ")
+}
+
+// marshalSteps сериализует шаги (вызывается под блокировкой)
+func (r *FileRepository) marshalSteps() ([]byte, error) {
+	steps := make([]*models.Step, 0, len(r.steps))
+	for _, step := range r.steps {
+		steps = append(steps, step)
+	}
+	return json.MarshalIndent(steps, "", "  ")
+}
+
+// saveSnapshot атомарно сохраняет один снапшот
+func (r *FileRepository) saveSnapshot(name string, marshal func() ([]byte, error)) error {
+	r.saveMutex.Lock()
+	defer r.saveMutex.Unlock()
 
-	data, err := json.MarshalIndent(goals, "", "  ")
+	r.mutex.RLock()
+	data, err := marshal()
+	r.mutex.RUnlock()
 	if err != nil {
 		return err
 	}
 
-	filename := filepath.Join(r.dataDir, "goals.json")
-	return os.WriteFile(filename, data, 0644)
+	return writeSnapshot(filepath.Join(r.dataDir, name), data, false)
 }
 
-// loadSteps загружает шаги из файла
-func (r *FileRepository) loadSteps() error {
-	filename := filepath.Join(r.dataDir, "steps.json")
+// saveUsers сохраняет пользователей в файл
+func (r *FileRepository) saveUsers() error {
+	return r.saveSnapshot(usersFileName, r.marshalUsers)
+}
 
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return r.saveSteps()
+// saveGoals сохраняет цели в файл
+func (r *FileRepository) saveGoals() error {
+	return r.saveSnapshot(goalsFileName, r.marshalGoals)
+}
+
+// saveSteps сохраняет шаги в файл
+func (r *FileRepository) saveSteps() error {
+	return r.saveSnapshot(stepsFileName, r.marshalSteps)
+}
+
+// compact сохраняет все снапшоты в оба поколения и очищает журнал
+func (r *FileRepository) compact() error {
+	r.saveMutex.Lock()
+	defer r.saveMutex.Unlock()
+
+	// Блокируем запись, чтобы между снапшотами и очисткой журнала не появилось новых мутаций
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	snapshots := []struct {
+		name    string
+		marshal func() ([]byte, error)
+	}{
+		{usersFileName, r.marshalUsers},
+		{goalsFileName, r.marshalGoals},
+		{stepsFileName, r.marshalSteps},
+	}
+
+	for _, snapshot := range snapshots {
+		data, err := snapshot.marshal()
+		if err != nil {
+			return err
+		}
+		if err := writeSnapshot(filepath.Join(r.dataDir, snapshot.name), data, true); err != nil {
+			return err
 		}
-		return err
 	}
 
-	var steps []*models.Step
-	if err := json.Unmarshal(data, &steps); err != nil {
-		return err
+	return r.journal.reset()
+}
+
+// persist сохраняет снапшот после мутации, периодически сжимая журнал
+func (r *FileRepository) persist(save func() error) error {
+	if r.journal.len() >= journalCompactThreshold {
+		return r.compact()
 	}
+	return save()
+}
 
+// applyEntry применяет запись журнала к данным в памяти
+func (r *FileRepository) applyEntry(entry journalEntry) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	for _, step := range steps {
-		r.steps[step.ID] = step
+	switch entry.Op {
+	case opPutUser:
+		var user models.User
+		if err := json.Unmarshal(entry.Data, &user); err != nil {
+			return err
+		}
+		r.users[user.ID] = &user
+	case opPutGoal:
+		var goal models.Goal
+		if err := json.Unmarshal(entry.Data, &goal); err != nil {
+			return err
+		}
+		migrateGoal(&goal)
+		r.goals[goal.ID] = &goal
+	case opDeleteGoal:
+		r.deleteGoalLocked(entry.ID)
+	case opPutStep:
+		var step models.Step
+		if err := json.Unmarshal(entry.Data, &step); err != nil {
+			return err
+		}
+		migrateStep(&step)
+		r.steps[step.ID] = &step
+	case opDeleteStep:
+		delete(r.steps, entry.ID)
+	default:
+		return fmt.Errorf("unknown journal operation: %s", entry.Op)
 	}
 
 	return nil
 }
 
-// saveSteps сохраняет шаги в файл
-func (r *FileRepository) saveSteps() error {
-	r.mutex.RLock()
-	steps := make([]*models.Step, 0, len(r.steps))
-	for _, step := range r.steps {
-		steps = append(steps, step)
+// deleteGoalLocked удаляет цель вместе с шагами (вызывается под блокировкой)
+func (r *FileRepository) deleteGoalLocked(goalID string) {
+	delete(r.goals, goalID)
+
+	// Удаляем все шаги этой цели
+	for stepID, step := range r.steps {
+		if step.GoalID == goalID {
+			delete(r.steps, stepID)
+		}
 	}
-	r.mutex.RUnlock()
+}
 
-	data, err := json.MarshalIndent(steps, "", "  ")
+// clone возвращает глубокую копию сущности. Репозиторий отдает и хранит
+// только копии, чтобы изменения объектов в обработчиках не гонялись
+// с сериализацией снапшотов и с другими обработчиками
+func clone[T any](v *T) *T {
+	data, err := json.Marshal(v)
 	if err != nil {
-		return err
+		panic(fmt.Sprintf("failed to clone %T: %v", v, err))
+	}
+
+	var copied T
+	if err := json.Unmarshal(data, &copied); err != nil {
+		panic(fmt.Sprintf("failed to clone %T: %v", v, err))
 	}
 
-	filename := filepath.Join(r.dataDir, "steps.json")
-	return os.WriteFile(filename, data, 0644)
+	return &copied
+}
+
+// cloneAll возвращает копии всех сущностей из списка
+func cloneAll[T any](items []*T) []*T {
+	copied := make([]*T, len(items))
+	for i, item := range items {
+		copied[i] = clone(item)
+	}
+	return copied
 }
 
 // Реализация методов интерфейса Repository
-func (r *FileRepository) GetUser(userID string) (*models.User, error) {
+// Все операции выполняются над данными в памяти, поэтому контекст не используется
+func (r *FileRepository) GetUser(ctx context.Context, userID string) (*models.User, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
@@ -218,10 +463,10 @@ func (r *FileRepository) GetUser(userID string) (*models.User, error) {
 		return nil, fmt.Errorf("user not found: %s", userID)
 	}
 
-	return user, nil
+	return clone(user), nil
 }
 
-func (r *FileRepository) CreateUser(user *models.User) error {
+func (r *FileRepository) CreateUser(ctx context.Context, user *models.User) error {
 	r.mutex.Lock()
 
 	if _, exists := r.users[user.ID]; exists {
@@ -229,13 +474,18 @@ func (r *FileRepository) CreateUser(user *models.User) error {
 		return fmt.Errorf("user already exists: %s", user.ID)
 	}
 
-	r.users[user.ID] = user
+	if err := r.journal.append(opPutUser, user.ID, user); err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+
+	r.users[user.ID] = clone(user)
 	r.mutex.Unlock()
 
-	return r.saveUsers()
+	return r.persist(r.saveUsers)
 }
 
-func (r *FileRepository) UpdateUser(user *models.User) error {
+func (r *FileRepository) UpdateUser(ctx context.Context, user *models.User) error {
 	r.mutex.Lock()
 
 	if _, exists := r.users[user.ID]; !exists {
@@ -243,13 +493,18 @@ func (r *FileRepository) UpdateUser(user *models.User) error {
 		return fmt.Errorf("user not found: %s", user.ID)
 	}
 
-	r.users[user.ID] = user
+	if err := r.journal.append(opPutUser, user.ID, user); err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+
+	r.users[user.ID] = clone(user)
 	r.mutex.Unlock()
 
-	return r.saveUsers()
+	return r.persist(r.saveUsers)
 }
 
-func (r *FileRepository) GetGoal(goalID string) (*models.Goal, error) {
+func (r *FileRepository) GetGoal(ctx context.Context, goalID string) (*models.Goal, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
@@ -258,10 +513,10 @@ func (r *FileRepository) GetGoal(goalID string) (*models.Goal, error) {
 		return nil, fmt.Errorf("goal not found: %s", goalID)
 	}
 
-	return goal, nil
+	return clone(goal), nil
 }
 
-func (r *FileRepository) GetUserGoals(userID string) ([]*models.Goal, error) {
+func (r *FileRepository) GetUserGoals(ctx context.Context, userID string) ([]*models.Goal, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
@@ -272,10 +527,13 @@ func (r *FileRepository) GetUserGoals(userID string) ([]*models.Goal, error) {
 		}
 	}
 
-	return userGoals, nil
+	// Цели хранятся в map - сортируем по дате создания, как в SQLite
+	models.SortGoals(userGoals)
+
+	return cloneAll(userGoals), nil
 }
 
-func (r *FileRepository) CreateGoal(goal *models.Goal) error {
+func (r *FileRepository) CreateGoal(ctx context.Context, goal *models.Goal) error {
 	r.mutex.Lock()
 
 	if _, exists := r.goals[goal.ID]; exists {
@@ -283,13 +541,18 @@ func (r *FileRepository) CreateGoal(goal *models.Goal) error {
 		return fmt.Errorf("goal already exists: %s", goal.ID)
 	}
 
-	r.goals[goal.ID] = goal
+	if err := r.journal.append(opPutGoal, goal.ID, goal); err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+
+	r.goals[goal.ID] = clone(goal)
 	r.mutex.Unlock()
 
-	return r.saveGoals()
+	return r.persist(r.saveGoals)
 }
 
-func (r *FileRepository) UpdateGoal(goal *models.Goal) error {
+func (r *FileRepository) UpdateGoal(ctx context.Context, goal *models.Goal) error {
 	r.mutex.Lock()
 
 	if _, exists := r.goals[goal.ID]; !exists {
@@ -298,13 +561,18 @@ func (r *FileRepository) UpdateGoal(goal *models.Goal) error {
 	}
 
 	goal.UpdatedAt = time.Now()
-	r.goals[goal.ID] = goal
+	if err := r.journal.append(opPutGoal, goal.ID, goal); err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+
+	r.goals[goal.ID] = clone(goal)
 	r.mutex.Unlock()
 
-	return r.saveGoals()
+	return r.persist(r.saveGoals)
 }
 
-func (r *FileRepository) DeleteGoal(goalID string) error {
+func (r *FileRepository) DeleteGoal(ctx context.Context, goalID string) error {
 	r.mutex.Lock()
 
 	if _, exists := r.goals[goalID]; !exists {
@@ -312,25 +580,23 @@ func (r *FileRepository) DeleteGoal(goalID string) error {
 		return fmt.Errorf("goal not found: %s", goalID)
 	}
 
-	delete(r.goals, goalID)
-
-	// Удаляем все шаги этой цели
-	for stepID, step := range r.steps {
-		if step.GoalID == goalID {
-			delete(r.steps, stepID)
-		}
+	if err := r.journal.append(opDeleteGoal, goalID, nil); err != nil {
+		r.mutex.Unlock()
+		return err
 	}
 
+	r.deleteGoalLocked(goalID)
 	r.mutex.Unlock()
 
-	if err := r.saveGoals(); err != nil {
-		return err
-	}
-
-	return r.saveSteps()
+	return r.persist(func() error {
+		if err := r.saveGoals(); err != nil {
+			return err
+		}
+		return r.saveSteps()
+	})
 }
 
-func (r *FileRepository) GetStep(stepID string) (*models.Step, error) {
+func (r *FileRepository) GetStep(ctx context.Context, stepID string) (*models.Step, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
@@ -339,10 +605,10 @@ func (r *FileRepository) GetStep(stepID string) (*models.Step, error) {
 		return nil, fmt.Errorf("step not found: %s", stepID)
 	}
 
-	return step, nil
+	return clone(step), nil
 }
 
-func (r *FileRepository) GetGoalSteps(goalID string) ([]*models.Step, error) {
+func (r *FileRepository) GetGoalSteps(ctx context.Context, goalID string) ([]*models.Step, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
@@ -358,17 +624,17 @@ func (r *FileRepository) GetGoalSteps(goalID string) ([]*models.Step, error) {
 		return goalSteps[i].CreatedAt.Before(goalSteps[j].CreatedAt)
 	})
 
-	return goalSteps, nil
+	return cloneAll(goalSteps), nil
 }
 
-func (r *FileRepository) GetCurrentStep(goalID string) (*models.Step, error) {
+func (r *FileRepository) GetCurrentStep(ctx context.Context, goalID string) (*models.Step, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	// Ищем последний невыполненный шаг для цели
+	// Ищем последний невыполненный и непропущенный шаг для цели
 	var currentStep *models.Step
 	for _, step := range r.steps {
-		if step.GoalID == goalID && !step.IsCompleted() {
+		if step.GoalID == goalID && !step.IsFinished() {
 			if currentStep == nil || step.CreatedAt.After(currentStep.CreatedAt) {
 				currentStep = step
 			}
@@ -379,10 +645,34 @@ func (r *FileRepository) GetCurrentStep(goalID string) (*models.Step, error) {
 		return nil, fmt.Errorf("no current step found for goal: %s", goalID)
 	}
 
-	return currentStep, nil
+	return clone(currentStep), nil
 }
 
-func (r *FileRepository) CreateStep(step *models.Step) error {
+func (r *FileRepository) GetRecentFinishedSteps(ctx context.Context, userID string, limit int) ([]*models.Step, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	// Берем последние limit закрытых шагов по всем целям пользователя
+	var finished []*models.Step
+	for _, step := range r.steps {
+		goal, exists := r.goals[step.GoalID]
+		if exists && goal.UserID == userID && step.IsFinished() {
+			finished = append(finished, step)
+		}
+	}
+
+	// Сортируем шаги по дате создания (от старых к новым)
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].CreatedAt.Before(finished[j].CreatedAt)
+	})
+	if len(finished) > limit {
+		finished = finished[len(finished)-limit:]
+	}
+
+	return cloneAll(finished), nil
+}
+
+func (r *FileRepository) CreateStep(ctx context.Context, step *models.Step) error {
 	r.mutex.Lock()
 
 	if _, exists := r.steps[step.ID]; exists {
@@ -390,13 +680,18 @@ func (r *FileRepository) CreateStep(step *models.Step) error {
 		return fmt.Errorf("step already exists: %s", step.ID)
 	}
 
-	r.steps[step.ID] = step
+	if err := r.journal.append(opPutStep, step.ID, step); err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+
+	r.steps[step.ID] = clone(step)
 	r.mutex.Unlock()
 
-	return r.saveSteps()
+	return r.persist(r.saveSteps)
 }
 
-func (r *FileRepository) UpdateStep(step *models.Step) error {
+func (r *FileRepository) UpdateStep(ctx context.Context, step *models.Step) error {
 	r.mutex.Lock()
 
 	if _, exists := r.steps[step.ID]; !exists {
@@ -404,13 +699,18 @@ func (r *FileRepository) UpdateStep(step *models.Step) error {
 		return fmt.Errorf("step not found: %s", step.ID)
 	}
 
-	r.steps[step.ID] = step
+	if err := r.journal.append(opPutStep, step.ID, step); err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+
+	r.steps[step.ID] = clone(step)
 	r.mutex.Unlock()
 
-	return r.saveSteps()
+	return r.persist(r.saveSteps)
 }
 
-func (r *FileRepository) DeleteStep(stepID string) error {
+func (r *FileRepository) DeleteStep(ctx context.Context, stepID string) error {
 	r.mutex.Lock()
 
 	if _, exists := r.steps[stepID]; !exists {
@@ -418,23 +718,22 @@ func (r *FileRepository) DeleteStep(stepID string) error {
 		return fmt.Errorf("step not found: %s", stepID)
 	}
 
+	if err := r.journal.append(opDeleteStep, stepID, nil); err != nil {
+		r.mutex.Unlock()
+		return err
+	}
+
 	delete(r.steps, stepID)
 	r.mutex.Unlock()
 
-	return r.saveSteps()
+	return r.persist(r.saveSteps)
 }
 
 func (r *FileRepository) Close() error {
-	// Сохраняем все данные перед закрытием
-	if err := r.saveUsers(); err != nil {
-		return err
-	}
-	if err := r.saveGoals(); err != nil {
-		return err
-	}
-	if err := r.saveSteps(); err != nil {
+	// Сохраняем все данные перед закрытием и очищаем журнал
+	if err := r.compact(); err != nil {
 		return err
 	}
 
-	return nil
+	return r.journal.close()
 }
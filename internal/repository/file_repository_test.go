@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"goal-helper/internal/models"
+)
+
+func TestFileRepositoryReplaysJournal(t *testing.T) {
+	dir := t.TempDir()
+	user := models.NewUser("1", "user", "User")
+	goal := models.NewGoal(user.ID, "Goal", "")
+	step := goal.NewStep("Step")
+
+	// Процесс упал после записи в журнал, но до записи снапшотов
+	j, _, err := openJournal(filepath.Join(dir, journalFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range []struct {
+		op, id string
+		v      any
+	}{
+		{opPutUser, user.ID, user},
+		{opPutGoal, goal.ID, goal},
+		{opPutStep, step.ID, step},
+		{opDeleteStep, step.ID, nil},
+	} {
+		if err := j.append(entry.op, entry.id, entry.v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	j.close()
+
+	repo := openFileRepository(t, dir)
+	ctx := context.Background()
+
+	if _, err := repo.GetUser(ctx, user.ID); err != nil {
+		t.Errorf("user not replayed: %v", err)
+	}
+	if _, err := repo.GetGoal(ctx, goal.ID); err != nil {
+		t.Errorf("goal not replayed: %v", err)
+	}
+	if _, err := repo.GetStep(ctx, step.ID); err == nil {
+		t.Errorf("deleted step was replayed")
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// После проигрывания данные зафиксированы в снапшотах, а журнал очищен
+	if size := fileSize(t, filepath.Join(dir, journalFileName)); size != 0 {
+		t.Errorf("journal size after replay = %d, want 0", size)
+	}
+	repo = openFileRepository(t, dir)
+	defer repo.Close()
+	if _, err := repo.GetGoal(ctx, goal.ID); err != nil {
+		t.Errorf("goal lost after restart: %v", err)
+	}
+}
+
+func TestFileRepositoryRecoversCorruptSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(t *testing.T, filename string)
+	}{
+		{"corrupted", func(t *testing.T, filename string) {
+			if err := os.WriteFile(filename, []byte(`[{"id": "tru`), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"missing", func(t *testing.T, filename string) {
+			if err := os.Remove(filename); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			ctx := context.Background()
+
+			repo := openFileRepository(t, dir)
+			user := models.NewUser("1", "user", "User")
+			goal := models.NewGoal(user.ID, "Goal", "")
+			if err := repo.CreateUser(ctx, user); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.CreateGoal(ctx, goal); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			// Изменение после чистого перезапуска: предыдущее поколение старше текущего снапшота
+			repo = openFileRepository(t, dir)
+			goal.Title = "Renamed"
+			if err := repo.UpdateGoal(ctx, goal); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			tt.corrupt(t, filepath.Join(dir, goalsFileName))
+
+			repo = openFileRepository(t, dir)
+			defer repo.Close()
+
+			loaded, err := repo.GetGoal(ctx, goal.ID)
+			if err != nil {
+				t.Fatalf("goal lost: %v", err)
+			}
+			if loaded.Title != "Renamed" {
+				t.Errorf("goal title = %q, want latest %q", loaded.Title, "Renamed")
+			}
+		})
+	}
+}
+
+func TestFileRepositoryRecoversBetweenCompactions(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	repo := openFileRepository(t, dir)
+	user := models.NewUser("1", "user", "User")
+	if err := repo.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	goal := models.NewGoal(user.ID, "Goal", "")
+	if err := repo.CreateGoal(ctx, goal); err != nil {
+		t.Fatal(err)
+	}
+	goal.Title = "Renamed"
+	if err := repo.UpdateGoal(ctx, goal); err != nil {
+		t.Fatal(err)
+	}
+	// Процесс упал без Close: журнал не очищен, а текущий снапшот испорчен
+	repo.journal.close()
+
+	if err := os.WriteFile(filepath.Join(dir, goalsFileName), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo = openFileRepository(t, dir)
+	defer repo.Close()
+
+	loaded, err := repo.GetGoal(ctx, goal.ID)
+	if err != nil {
+		t.Fatalf("goal lost: %v", err)
+	}
+	if loaded.Title != "Renamed" {
+		t.Errorf("goal title = %q, want %q", loaded.Title, "Renamed")
+	}
+}
+
+func TestFileRepositoryFailsWithoutRecoverableSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	repo := openFileRepository(t, dir)
+	if err := repo.CreateUser(ctx, models.NewUser("1", "user", "User")); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{usersFileName, usersFileName + snapshotBackupSuffix} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("garbage"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo, err := NewFileRepository(dir)
+	if err == nil {
+		repo.Close()
+		t.Fatal("NewFileRepository succeeded with unrecoverable users snapshot, want error")
+	}
+	if !strings.Contains(err.Error(), usersFileName) {
+		t.Errorf("error %q does not name the snapshot", err)
+	}
+}
+
+func TestFileRepositoryMigratesLegacySnapshots(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// Снапшоты, записанные до появления статусов, структурных уточнений и счетчика переформулировок
+	goals := `[
+		{"id": "paused", "user_id": "1", "title": "Paused", "status": "paused", "created_at": "2025-01-02T10:00:00Z"},
+		{"id": "legacy", "user_id": "1", "title": "Legacy", "status": "inactive", "created_at": "2025-01-01T10:00:00Z",
+			"context": {"clarifications": ["Вопрос: Зачем? | Ответ: Для себя", "Просто заметка"]}}
+	]`
+	steps := `[
+		{"id": "rephrased", "goal_id": "legacy", "text": "Step", "rephrased": true, "created_at": "2025-01-01T10:00:00Z"},
+		{"id": "counted", "goal_id": "legacy", "text": "Step", "rephrased": true, "rephrases": 3, "created_at": "2025-01-01T11:00:00Z"}
+	]`
+	for name, data := range map[string]string{goalsFileName: goals, stepsFileName: steps} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := openFileRepository(t, dir)
+	defer repo.Close()
+
+	legacy, err := repo.GetGoal(ctx, "legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if legacy.Status != models.GoalStatusActive {
+		t.Errorf("legacy goal status = %q, want %q", legacy.Status, models.GoalStatusActive)
+	}
+	clarifications := legacy.Context.Clarifications
+	if len(clarifications) != 2 {
+		t.Fatalf("got %d clarifications, want 2", len(clarifications))
+	}
+	if clarifications[0].Question != "Зачем?" || clarifications[0].Answer != "Для себя" {
+		t.Errorf("clarification = %+v, want question and answer split", clarifications[0])
+	}
+	for i, clarification := range clarifications {
+		if !clarification.AskedAt.Equal(legacy.CreatedAt) {
+			t.Errorf("clarification %d asked at %v, want goal creation time %v", i, clarification.AskedAt, legacy.CreatedAt)
+		}
+	}
+
+	paused, err := repo.GetGoal(ctx, "paused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paused.Status != models.GoalStatusPaused {
+		t.Errorf("paused goal status = %q, want it kept", paused.Status)
+	}
+
+	for id, want := range map[string]int{"rephrased": 1, "counted": 3} {
+		step, err := repo.GetStep(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if step.Rephrases != want {
+			t.Errorf("step %s rephrases = %d, want %d", id, step.Rephrases, want)
+		}
+	}
+}
+
+func openFileRepository(t *testing.T, dir string) *FileRepository {
+	t.Helper()
+	repo, err := NewFileRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Операции журнала
+const (
+	opPutUser    = "put_user"
+	opPutGoal    = "put_goal"
+	opDeleteGoal = "delete_goal"
+	opPutStep    = "put_step"
+	opDeleteStep = "delete_step"
+)
+
+// journalEntry описывает одну мутацию данных в журнале
+type journalEntry struct {
+	Seq  uint64          `json:"seq"`            // Порядковый номер записи
+	Time time.Time       `json:"time"`           // Время записи
+	Op   string          `json:"op"`             // Операция (put_user, delete_goal, ...)
+	ID   string          `json:"id"`             // ID сущности
+	Data json.RawMessage `json:"data,omitempty"` // Сущность целиком (для put операций)
+}
+
+// journal реализует append-only журнал мутаций (write-ahead log).
+// Каждая мутация сначала записывается в журнал с fsync, и только потом
+// применяется к данным в памяти и снапшотам. Все put операции содержат
+// сущность целиком, поэтому повторное применение записей идемпотентно.
+type journal struct {
+	path  string
+	mutex sync.Mutex
+	file  *os.File
+	seq   uint64 // Номер последней записи
+	count int    // Количество записей с момента последнего сжатия
+}
+
+// openJournal открывает журнал и возвращает записи, которые ещё не попали в снапшот
+func openJournal(path string) (*journal, []journalEntry, error) {
+	entries, validSize, err := readJournal(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	// Отбрасываем недописанный хвост (процесс упал во время записи)
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	if info.Size() > validSize {
+		log.Printf("⚠️ Журнал %s содержит поврежденный хвост, отбрасываем %d байт", path, info.Size()-validSize)
+		if err := file.Truncate(validSize); err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("failed to truncate journal: %w", err)
+		}
+	}
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	j := &journal{
+		path:  path,
+		file:  file,
+		count: len(entries),
+	}
+	if len(entries) > 0 {
+		j.seq = entries[len(entries)-1].Seq
+	}
+
+	return j, entries, nil
+}
+
+// readJournal читает все корректные записи журнала.
+// Возвращает записи и размер корректной части файла в байтах.
+func readJournal(path string) ([]journalEntry, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("failed to read journal: %w", err)
+	}
+	defer file.Close()
+
+	var entries []journalEntry
+	var validSize int64
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// Строка без перевода строки - незавершенная запись
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read journal: %w", err)
+		}
+
+		var entry journalEntry
+		if err := json.Unmarshal(bytes.TrimSpace(line), &entry); err != nil {
+			break
+		}
+
+		entries = append(entries, entry)
+		validSize += int64(len(line))
+	}
+
+	return entries, validSize, nil
+}
+
+// append записывает мутацию в журнал и дожидается сброса на диск
+func (j *journal) append(op, id string, v any) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	entry := journalEntry{
+		Seq:  j.seq + 1,
+		Time: time.Now(),
+		Op:   op,
+		ID:   id,
+	}
+
+	if v != nil {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to marshal journal entry: %w", err)
+		}
+		entry.Data = data
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	if _, err := j.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+
+	j.seq = entry.Seq
+	j.count++
+	return nil
+}
+
+// len возвращает количество записей с момента последнего сжатия
+func (j *journal) len() int {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.count
+}
+
+// reset очищает журнал после того, как все записи попали в снапшот
+func (j *journal) reset() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if err := j.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate journal: %w", err)
+	}
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+
+	j.count = 0
+	return nil
+}
+
+// close закрывает файл журнала
+func (j *journal) close() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.file.Close()
+}
+
+// writeFileAtomic атомарно записывает файл: данные пишутся во временный файл
+// в той же директории, сбрасываются на диск и переименовываются поверх старого.
+// При падении процесса на диске остается либо старая, либо новая версия файла.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(filename)
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+
+	// Удаляем временный файл, если что-то пошло не так
+	success := false
+	defer func() {
+		if !success {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	success = true
+
+	syncDir(dir)
+
+	return nil
+}
+
+// syncDir сбрасывает на диск записи о переименованиях в директории
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournalDropsTruncatedTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), journalFileName)
+
+	j, _, err := openJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"1", "2"} {
+		if err := j.append(opDeleteStep, id, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	validSize := fileSize(t, path)
+
+	// Процесс упал посреди записи: последняя строка без перевода строки
+	if _, err := j.file.WriteString(`{"seq":3,"op":"delete_st`); err != nil {
+		t.Fatal(err)
+	}
+	j.close()
+
+	j, entries, err := openJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.close()
+
+	if len(entries) != 2 || entries[1].ID != "2" {
+		t.Fatalf("entries = %+v, want 2 complete entries", entries)
+	}
+	if size := fileSize(t, path); size != validSize {
+		t.Fatalf("journal size = %d, want truncated to %d", size, validSize)
+	}
+
+	// Новые записи продолжают нумерацию и дописываются после корректной части
+	if err := j.append(opDeleteStep, "3", nil); err != nil {
+		t.Fatal(err)
+	}
+	entries, _, err = readJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[2].Seq != 3 {
+		t.Fatalf("entries after append = %+v, want 3 entries ending with seq 3", entries)
+	}
+}
+
+func TestJournalStopsAtCorruptLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), journalFileName)
+	data := `{"seq":1,"op":"delete_step","id":"1"}` + "\n" +
+		`not json` + "\n" +
+		`{"seq":3,"op":"delete_step","id":"3"}` + "\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, validSize, err := readJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || validSize != int64(len(`{"seq":1,"op":"delete_step","id":"1"}`)+1) {
+		t.Fatalf("entries = %+v, size = %d, want only the first entry", entries, validSize)
+	}
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
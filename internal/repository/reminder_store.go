@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"goal-helper/internal/models"
+)
+
+// ReminderStore определяет интерфейс для хранения запланированных напоминаний,
+// чтобы они переживали перезапуск бота. У пользователя не больше одного напоминания
+type ReminderStore interface {
+	ListDueReminders(ctx context.Context, now time.Time) ([]*models.Reminder, error)
+	GetReminder(ctx context.Context, userID string) (*models.Reminder, error)
+	SaveReminder(ctx context.Context, reminder *models.Reminder) error
+	DeleteReminder(ctx context.Context, userID string) error
+}
+
+// remindersFileName имя файла с напоминаниями
+const remindersFileName = "reminders.json"
+
+// NewReminderStore создает хранилище напоминаний рядом с репозиторием:
+// для SQLite напоминания хранятся в той же базе, иначе - в JSON файле
+func NewReminderStore(repo Repository, dataDir string) (ReminderStore, error) {
+	if sqliteRepo, ok := repo.(*SQLiteRepository); ok {
+		return &SQLiteReminderStore{db: sqliteRepo.db}, nil
+	}
+	return NewFileReminderStore(dataDir)
+}
+
+// FileReminderStore реализует ReminderStore через JSON файл
+type FileReminderStore struct {
+	filename  string
+	mutex     sync.Mutex
+	reminders map[string]*models.Reminder
+}
+
+// NewFileReminderStore создает файловое хранилище напоминаний
+func NewFileReminderStore(dataDir string) (*FileReminderStore, error) {
+	store := &FileReminderStore{
+		filename:  filepath.Join(dataDir, remindersFileName),
+		reminders: make(map[string]*models.Reminder),
+	}
+
+	data, err := os.ReadFile(store.filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read reminders: %w", err)
+	}
+
+	var reminders []*models.Reminder
+	if err := json.Unmarshal(data, &reminders); err != nil {
+		// Напоминания будут запланированы заново при следующей активности пользователя
+		log.Printf("⚠️ Файл %s поврежден, напоминания сброшены: %v", store.filename, err)
+		return store, nil
+	}
+
+	for _, reminder := range reminders {
+		store.reminders[reminder.UserID] = reminder
+	}
+
+	return store, nil
+}
+
+// save сохраняет все напоминания в файл (вызывается под блокировкой)
+func (s *FileReminderStore) save() error {
+	reminders := make([]*models.Reminder, 0, len(s.reminders))
+	for _, reminder := range s.reminders {
+		reminders = append(reminders, reminder)
+	}
+
+	data, err := json.MarshalIndent(reminders, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.filename, data, 0644)
+}
+
+func (s *FileReminderStore) ListDueReminders(ctx context.Context, now time.Time) ([]*models.Reminder, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var reminders []*models.Reminder
+	for _, reminder := range s.reminders {
+		if !reminder.DueAt.After(now) {
+			copied := *reminder
+			reminders = append(reminders, &copied)
+		}
+	}
+
+	return reminders, nil
+}
+
+func (s *FileReminderStore) GetReminder(ctx context.Context, userID string) (*models.Reminder, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	reminder, exists := s.reminders[userID]
+	if !exists {
+		return nil, nil
+	}
+	copied := *reminder
+	return &copied, nil
+}
+
+func (s *FileReminderStore) SaveReminder(ctx context.Context, reminder *models.Reminder) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	copied := *reminder
+	s.reminders[reminder.UserID] = &copied
+
+	return s.save()
+}
+
+func (s *FileReminderStore) DeleteReminder(ctx context.Context, userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.reminders[userID]; !exists {
+		return nil
+	}
+	delete(s.reminders, userID)
+
+	return s.save()
+}
+
+// SQLiteReminderStore реализует ReminderStore через таблицу reminders в базе SQLiteRepository
+type SQLiteReminderStore struct {
+	db *sql.DB
+}
+
+const reminderColumns = "user_id, goal_id, step_id, due_at, nudges"
+
+// scanReminder читает напоминание из строки результата
+func scanReminder(row rowScanner) (*models.Reminder, error) {
+	var reminder models.Reminder
+	if err := row.Scan(&reminder.UserID, &reminder.GoalID, &reminder.StepID, &reminder.DueAt, &reminder.Nudges); err != nil {
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+func (s *SQLiteReminderStore) ListDueReminders(ctx context.Context, now time.Time) ([]*models.Reminder, error) {
+	// Время хранится в UTC, чтобы сравнение строк в SQLite совпадало со сравнением времени
+	rows, err := s.db.QueryContext(ctx, "SELECT "+reminderColumns+" FROM reminders WHERE due_at <= ? ORDER BY due_at", now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reminders []*models.Reminder
+	for rows.Next() {
+		reminder, err := scanReminder(rows)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, rows.Err()
+}
+
+func (s *SQLiteReminderStore) GetReminder(ctx context.Context, userID string) (*models.Reminder, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+reminderColumns+" FROM reminders WHERE user_id = ?", userID)
+	reminder, err := scanReminder(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return reminder, err
+}
+
+func (s *SQLiteReminderStore) SaveReminder(ctx context.Context, reminder *models.Reminder) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO reminders (`+reminderColumns+`) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET goal_id = excluded.goal_id, step_id = excluded.step_id,
+			due_at = excluded.due_at, nudges = excluded.nudges`,
+		reminder.UserID, reminder.GoalID, reminder.StepID, reminder.DueAt.UTC(), reminder.Nudges)
+	if err != nil {
+		return fmt.Errorf("failed to save reminder for user %s: %w", reminder.UserID, err)
+	}
+	return nil
+}
+
+func (s *SQLiteReminderStore) DeleteReminder(ctx context.Context, userID string) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM reminders WHERE user_id = ?", userID)
+	return err
+}
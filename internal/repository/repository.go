@@ -1,32 +1,61 @@
 package repository
 
 import (
+	"context"
+	"fmt"
+	"path/filepath"
+
 	"goal-helper/internal/models"
 )
 
 // Repository определяет интерфейс для работы с данными
 // Это позволяет легко заменить файловое хранение на БД
+// Контекст позволяет отменять запросы к хранилищу и ограничивать их по времени
 type Repository interface {
 	// Пользователи
-	GetUser(userID string) (*models.User, error)
-	CreateUser(user *models.User) error
-	UpdateUser(user *models.User) error
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+	CreateUser(ctx context.Context, user *models.User) error
+	UpdateUser(ctx context.Context, user *models.User) error
 
 	// Цели
-	GetGoal(goalID string) (*models.Goal, error)
-	GetUserGoals(userID string) ([]*models.Goal, error)
-	CreateGoal(goal *models.Goal) error
-	UpdateGoal(goal *models.Goal) error
-	DeleteGoal(goalID string) error
+	GetGoal(ctx context.Context, goalID string) (*models.Goal, error)
+	GetUserGoals(ctx context.Context, userID string) ([]*models.Goal, error)
+	CreateGoal(ctx context.Context, goal *models.Goal) error
+	UpdateGoal(ctx context.Context, goal *models.Goal) error
+	DeleteGoal(ctx context.Context, goalID string) error
 
 	// Шаги
-	GetStep(stepID string) (*models.Step, error)
-	GetGoalSteps(goalID string) ([]*models.Step, error)
-	GetCurrentStep(goalID string) (*models.Step, error)
-	CreateStep(step *models.Step) error
-	UpdateStep(step *models.Step) error
-	DeleteStep(stepID string) error
+	GetStep(ctx context.Context, stepID string) (*models.Step, error)
+	GetGoalSteps(ctx context.Context, goalID string) ([]*models.Step, error)
+	GetCurrentStep(ctx context.Context, goalID string) (*models.Step, error)
+	GetRecentFinishedSteps(ctx context.Context, userID string, limit int) ([]*models.Step, error)
+	CreateStep(ctx context.Context, step *models.Step) error
+	UpdateStep(ctx context.Context, step *models.Step) error
+	DeleteStep(ctx context.Context, stepID string) error
 
 	// Утилиты
 	Close() error
 }
+
+// Типы хранилищ
+const (
+	BackendFile   = "file"
+	BackendSQLite = "sqlite"
+)
+
+// SQLiteFileName имя файла базы данных внутри директории с данными
+const SQLiteFileName = "goal_helper.db"
+
+// New создает репозиторий указанного типа
+// backend - тип хранилища ("file" или "sqlite"), по умолчанию "file"
+// dataDir - директория с данными
+func New(backend, dataDir string) (Repository, error) {
+	switch backend {
+	case "", BackendFile:
+		return NewFileRepository(dataDir)
+	case BackendSQLite:
+		return NewSQLiteRepository(filepath.Join(dataDir, SQLiteFileName))
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", backend)
+	}
+}
@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"goal-helper/internal/models"
+)
+
+// backends хранилища, на которых проверяется общее поведение Repository
+var backends = []string{BackendFile, BackendSQLite}
+
+func TestRepositoryRoundTrip(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend, func(t *testing.T) {
+			dir := t.TempDir()
+			repo, err := New(backend, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+			start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+			completedAt := start.Add(48 * time.Hour)
+
+			user := &models.User{ID: "1", Username: "user", FirstName: "User", CreatedAt: start, LanguageCode: "en"}
+			if err := repo.CreateUser(ctx, user); err != nil {
+				t.Fatal(err)
+			}
+			user.ActiveGoalID = "goal"
+			user.Settings = models.UserSettings{
+				Timezone:   "Europe/Moscow",
+				QuietHours: models.QuietHours{Start: "22:00", End: "08:00"},
+				Reminders:  models.ReminderSettings{IdleHours: 12, Days: []time.Weekday{time.Monday}},
+				Persona:    "serious",
+			}
+			if err := repo.UpdateUser(ctx, user); err != nil {
+				t.Fatal(err)
+			}
+
+			goal := &models.Goal{
+				ID: "goal", UserID: user.ID, Title: "Goal", Description: "Description",
+				CreatedAt: start, UpdatedAt: start, Status: models.GoalStatusActive,
+				Context: models.Context{Clarifications: []models.Clarification{
+					{Question: "Why?", Answer: "Because", AskedAt: start, Source: models.ClarificationSourceContext},
+				}},
+			}
+			if err := repo.CreateGoal(ctx, goal); err != nil {
+				t.Fatal(err)
+			}
+			goal.Status = models.GoalStatusCompleted
+			goal.StatusReason = "done"
+			goal.CompletedAt = &completedAt
+			goal.Category = "educational"
+			goal.Persona = "motivational"
+			goal.Milestones = []models.Milestone{{ID: "m1", Title: "First", CompletedAt: &completedAt}, {ID: "m2", Title: "Second"}}
+			if err := repo.UpdateGoal(ctx, goal); err != nil {
+				t.Fatal(err)
+			}
+
+			done := &models.Step{ID: "done", GoalID: goal.ID, Text: "Done", CreatedAt: start, MilestoneID: "m1"}
+			current := &models.Step{ID: "current", GoalID: goal.ID, Text: "Current", CreatedAt: start.Add(time.Hour)}
+			extra := &models.Step{ID: "extra", GoalID: goal.ID, Text: "Extra", CreatedAt: start.Add(2 * time.Hour)}
+			for _, step := range []*models.Step{done, current, extra} {
+				if err := repo.CreateStep(ctx, step); err != nil {
+					t.Fatal(err)
+				}
+			}
+			done.CompletedAt = &completedAt
+			done.Rephrased = true
+			done.Rephrases = 2
+			done.Simplifications = 1
+			done.UserComment = "comment"
+			done.History = []models.StepRevision{{Text: "Old", Kind: models.StepChangeRephrase, ChangedAt: start}}
+			done.Difficulty = models.StepDifficultyRight
+			done.CompletionNote = "note"
+			if err := repo.UpdateStep(ctx, done); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.DeleteStep(ctx, extra.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			check := func(repo Repository) {
+				t.Helper()
+				gotUser, err := repo.GetUser(ctx, user.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertSameJSON(t, gotUser, user)
+
+				gotGoal, err := repo.GetGoal(ctx, goal.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertSameJSON(t, gotGoal, goal)
+
+				steps, err := repo.GetGoalSteps(ctx, goal.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertSameJSON(t, steps, []*models.Step{done, current})
+
+				gotCurrent, err := repo.GetCurrentStep(ctx, goal.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if gotCurrent.ID != current.ID {
+					t.Errorf("current step = %s, want %s", gotCurrent.ID, current.ID)
+				}
+
+				if _, err := repo.GetStep(ctx, extra.ID); err == nil {
+					t.Error("deleted step is still found")
+				}
+				if _, err := repo.GetUser(ctx, "missing"); err == nil {
+					t.Error("GetUser found a missing user")
+				}
+			}
+			check(repo)
+
+			// Данные переживают перезапуск
+			if err := repo.Close(); err != nil {
+				t.Fatal(err)
+			}
+			repo, err = New(backend, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repo.Close()
+			check(repo)
+
+			// Удаление цели удаляет и её шаги
+			if err := repo.DeleteGoal(ctx, goal.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := repo.GetGoal(ctx, goal.ID); err == nil {
+				t.Error("deleted goal is still found")
+			}
+			if _, err := repo.GetStep(ctx, done.ID); err == nil {
+				t.Error("step of a deleted goal is still found")
+			}
+		})
+	}
+}
+
+// assertSameJSON сравнивает значения по их JSON представлению
+func assertSameJSON(t *testing.T, got, want any) {
+	t.Helper()
+	gotJSON, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("got  %s\nwant %s", gotJSON, wantJSON)
+	}
+}
+
+func TestGetRecentFinishedSteps(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend, func(t *testing.T) {
+			repo, err := New(backend, t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repo.Close()
+
+			ctx := context.Background()
+			start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+			// Шаги двух целей пользователя чередуются, у другого пользователя свои шаги
+			var goals []*models.Goal
+			for _, userID := range []string{"1", "1", "2"} {
+				goal := models.NewGoal(userID, "Goal", "")
+				if err := repo.CreateGoal(ctx, goal); err != nil {
+					t.Fatal(err)
+				}
+				goals = append(goals, goal)
+			}
+
+			var want []string
+			for i := 0; i < 6; i++ {
+				goal := goals[i%len(goals)]
+				step := goal.NewStep("Step")
+				step.CreatedAt = start.Add(time.Duration(i) * time.Hour)
+				switch {
+				case i == 4:
+					// Текущий шаг не закрыт
+				case i%2 == 0:
+					step.Complete()
+				default:
+					step.Skip("")
+				}
+				if err := repo.CreateStep(ctx, step); err != nil {
+					t.Fatal(err)
+				}
+				if goal.UserID == "1" && step.IsFinished() {
+					want = append(want, step.ID)
+				}
+			}
+
+			steps, err := repo.GetRecentFinishedSteps(ctx, "1", 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := stepIDs(steps); !slices.Equal(got, want) {
+				t.Errorf("steps = %v, want %v", got, want)
+			}
+
+			steps, err = repo.GetRecentFinishedSteps(ctx, "1", 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := stepIDs(steps); !slices.Equal(got, want[len(want)-2:]) {
+				t.Errorf("limited steps = %v, want the last two %v", got, want[len(want)-2:])
+			}
+		})
+	}
+}
+
+func TestGetUserGoalsOrder(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend, func(t *testing.T) {
+			repo, err := New(backend, t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repo.Close()
+
+			ctx := context.Background()
+			start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+			// Цели создаются не по порядку, две - в одно и то же время
+			goals := []*models.Goal{
+				{ID: "c", UserID: "1", Title: "C", Status: models.GoalStatusActive, CreatedAt: start.Add(time.Hour)},
+				{ID: "b", UserID: "1", Title: "B", Status: models.GoalStatusActive, CreatedAt: start},
+				{ID: "a", UserID: "1", Title: "A", Status: models.GoalStatusActive, CreatedAt: start},
+				{ID: "d", UserID: "1", Title: "D", Status: models.GoalStatusActive, CreatedAt: start.Add(-time.Hour)},
+			}
+			for _, goal := range goals {
+				goal.UpdatedAt = goal.CreatedAt
+				if err := repo.CreateGoal(ctx, goal); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Порядок должен быть одинаковым при каждом вызове
+			for i := 0; i < 5; i++ {
+				loaded, err := repo.GetUserGoals(ctx, "1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				var ids []string
+				for _, goal := range loaded {
+					ids = append(ids, goal.ID)
+				}
+				if want := []string{"d", "a", "b", "c"}; !slices.Equal(ids, want) {
+					t.Fatalf("goals = %v, want %v", ids, want)
+				}
+			}
+		})
+	}
+}
+
+func stepIDs(steps []*models.Step) []string {
+	ids := make([]string, 0, len(steps))
+	for _, step := range steps {
+		ids = append(ids, step.ID)
+	}
+	return ids
+}
@@ -0,0 +1,510 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"goal-helper/internal/models"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteMigrations содержит последовательные миграции схемы.
+// Номер применённой миграции хранится в PRAGMA user_version,
+// поэтому новые миграции нужно только дописывать в конец списка.
+var sqliteMigrations = []string{
+	`CREATE TABLE IF NOT EXISTS users (
+		id             TEXT PRIMARY KEY,
+		username       TEXT NOT NULL DEFAULT '',
+		first_name     TEXT NOT NULL DEFAULT '',
+		created_at     TIMESTAMP NOT NULL,
+		active_goal_id TEXT NOT NULL DEFAULT ''
+	);
+	CREATE TABLE IF NOT EXISTS goals (
+		id           TEXT PRIMARY KEY,
+		user_id      TEXT NOT NULL,
+		title        TEXT NOT NULL,
+		description  TEXT NOT NULL DEFAULT '',
+		created_at   TIMESTAMP NOT NULL,
+		updated_at   TIMESTAMP NOT NULL,
+		context      TEXT NOT NULL DEFAULT '{}',
+		status       TEXT NOT NULL,
+		completed_at TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_goals_user_id ON goals(user_id);
+	CREATE TABLE IF NOT EXISTS steps (
+		id           TEXT PRIMARY KEY,
+		goal_id      TEXT NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+		text         TEXT NOT NULL,
+		created_at   TIMESTAMP NOT NULL,
+		completed_at TIMESTAMP,
+		rephrased    INTEGER NOT NULL DEFAULT 0,
+		user_comment TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_steps_goal_id ON steps(goal_id, created_at);`,
+	`CREATE TABLE IF NOT EXISTS user_states (
+		user_id    INTEGER PRIMARY KEY,
+		state      TEXT NOT NULL,
+		temp_data  TEXT NOT NULL DEFAULT '{}',
+		updated_at TIMESTAMP NOT NULL
+	);`,
+	`ALTER TABLE users ADD COLUMN settings TEXT NOT NULL DEFAULT '{}';
+	CREATE TABLE IF NOT EXISTS reminders (
+		user_id TEXT PRIMARY KEY,
+		goal_id TEXT NOT NULL,
+		step_id TEXT NOT NULL,
+		due_at  TIMESTAMP NOT NULL,
+		nudges  INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_reminders_due_at ON reminders(due_at);`,
+	// Раньше хранился только флаг rephrased - считаем такие шаги переформулированными один раз
+	`ALTER TABLE steps ADD COLUMN rephrases INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE steps ADD COLUMN simplifications INTEGER NOT NULL DEFAULT 0;
+	UPDATE steps SET rephrases = 1 WHERE rephrased = 1;`,
+	// Статус "inactive" нигде не выставлялся, но на всякий случай возвращаем такие цели в работу
+	`ALTER TABLE goals ADD COLUMN status_reason TEXT NOT NULL DEFAULT '';
+	UPDATE goals SET status = 'active' WHERE status NOT IN ('active', 'paused', 'abandoned', 'completed');`,
+	`ALTER TABLE steps ADD COLUMN history TEXT NOT NULL DEFAULT '[]';`,
+	`ALTER TABLE steps ADD COLUMN skipped_at TIMESTAMP;
+	ALTER TABLE steps ADD COLUMN skip_reason TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE steps ADD COLUMN difficulty TEXT NOT NULL DEFAULT '';
+	ALTER TABLE steps ADD COLUMN completion_note TEXT NOT NULL DEFAULT '';`,
+	// Уточнения хранились строками "Вопрос: ... | Ответ: ..." - раскладываем их на поля.
+	// Время ответа не сохранялось, берем время создания цели
+	`UPDATE goals SET context = json_set(context, '$.clarifications', (
+		SELECT json_group_array(CASE
+			WHEN c.type != 'text' THEN json(c.value)
+			WHEN c.value LIKE 'Вопрос: % | Ответ: %' THEN json_object(
+				'question', substr(c.value, 9, instr(c.value, ' | Ответ: ') - 9),
+				'answer', substr(c.value, instr(c.value, ' | Ответ: ') + 10),
+				'asked_at', strftime('%Y-%m-%dT%H:%M:%SZ', goals.created_at),
+				'source', 'legacy')
+			ELSE json_object(
+				'question', '',
+				'answer', c.value,
+				'asked_at', strftime('%Y-%m-%dT%H:%M:%SZ', goals.created_at),
+				'source', 'legacy')
+			END)
+		FROM json_each(goals.context, '$.clarifications') AS c))
+	WHERE json_type(context, '$.clarifications') = 'array';`,
+	`ALTER TABLE users ADD COLUMN language_code TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE goals ADD COLUMN category TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE goals ADD COLUMN persona TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE goals ADD COLUMN milestones TEXT NOT NULL DEFAULT '[]';
+	ALTER TABLE steps ADD COLUMN milestone_id TEXT NOT NULL DEFAULT '';`,
+}
+
+// SQLiteRepository реализует Repository интерфейс через SQLite
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository создает новый SQLite репозиторий
+// path - путь к файлу базы данных
+func NewSQLiteRepository(path string) (*SQLiteRepository, error) {
+	// Создаем директорию, если её нет
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	dsn := fmt.Sprintf("file:%s?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000", path)
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// SQLite не поддерживает параллельную запись, поэтому одно соединение
+	db.SetMaxOpenConns(1)
+
+	repo := &SQLiteRepository{db: db}
+	if err := repo.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	return repo, nil
+}
+
+// migrate применяет недостающие миграции схемы
+func (r *SQLiteRepository) migrate() error {
+	var version int
+	if err := r.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := r.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		// PRAGMA не поддерживает плейсхолдеры
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rowScanner общий интерфейс для *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+const userColumns = "id, username, first_name, created_at, active_goal_id, settings, language_code"
+
+// scanUser читает пользователя из строки результата
+func scanUser(row rowScanner) (*models.User, error) {
+	var user models.User
+	var settingsJSON string
+	if err := row.Scan(&user.ID, &user.Username, &user.FirstName, &user.CreatedAt, &user.ActiveGoalID, &settingsJSON, &user.LanguageCode); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(settingsJSON), &user.Settings); err != nil {
+		return nil, fmt.Errorf("failed to decode settings for user %s: %w", user.ID, err)
+	}
+	return &user, nil
+}
+
+const goalColumns = "id, user_id, title, description, created_at, updated_at, context, status, completed_at, status_reason, category, persona, milestones"
+
+// scanGoal читает цель из строки результата
+func scanGoal(row rowScanner) (*models.Goal, error) {
+	var goal models.Goal
+	var contextJSON, milestonesJSON string
+	var completedAt sql.NullTime
+
+	if err := row.Scan(&goal.ID, &goal.UserID, &goal.Title, &goal.Description,
+		&goal.CreatedAt, &goal.UpdatedAt, &contextJSON, &goal.Status, &completedAt, &goal.StatusReason, &goal.Category, &goal.Persona, &milestonesJSON); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(contextJSON), &goal.Context); err != nil {
+		return nil, fmt.Errorf("failed to decode goal context: %w", err)
+	}
+	if err := json.Unmarshal([]byte(milestonesJSON), &goal.Milestones); err != nil {
+		return nil, fmt.Errorf("failed to decode goal milestones: %w", err)
+	}
+	if completedAt.Valid {
+		goal.CompletedAt = &completedAt.Time
+	}
+
+	return &goal, nil
+}
+
+const stepColumns = "id, goal_id, text, created_at, completed_at, rephrased, user_comment, rephrases, simplifications, history, skipped_at, skip_reason, difficulty, completion_note, milestone_id"
+
+// scanStep читает шаг из строки результата
+func scanStep(row rowScanner) (*models.Step, error) {
+	var step models.Step
+	var completedAt, skippedAt sql.NullTime
+	var historyJSON string
+
+	if err := row.Scan(&step.ID, &step.GoalID, &step.Text, &step.CreatedAt,
+		&completedAt, &step.Rephrased, &step.UserComment, &step.Rephrases, &step.Simplifications, &historyJSON,
+		&skippedAt, &step.SkipReason, &step.Difficulty, &step.CompletionNote, &step.MilestoneID); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(historyJSON), &step.History); err != nil {
+		return nil, fmt.Errorf("failed to decode step history: %w", err)
+	}
+
+	if completedAt.Valid {
+		step.CompletedAt = &completedAt.Time
+	}
+	if skippedAt.Valid {
+		step.SkippedAt = &skippedAt.Time
+	}
+
+	return &step, nil
+}
+
+// nullTime преобразует указатель на время в значение для SQL
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+// marshalHistory кодирует историю шага в JSON (пустая история - пустой массив, а не null)
+func marshalHistory(history []models.StepRevision) (string, error) {
+	if history == nil {
+		history = []models.StepRevision{}
+	}
+	data, err := json.Marshal(history)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// marshalMilestones кодирует этапы цели в JSON (цель без этапов - пустой массив, а не null)
+func marshalMilestones(milestones []models.Milestone) (string, error) {
+	if milestones == nil {
+		milestones = []models.Milestone{}
+	}
+	data, err := json.Marshal(milestones)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// checkAffected возвращает ошибку "not found", если запрос не затронул ни одной строки
+func checkAffected(result sql.Result, entity, id string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s not found: %s", entity, id)
+	}
+	return nil
+}
+
+// Реализация методов интерфейса Repository
+func (r *SQLiteRepository) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE id = ?", userID)
+	user, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("user not found: %s", userID)
+	}
+	return user, err
+}
+
+func (r *SQLiteRepository) CreateUser(ctx context.Context, user *models.User) error {
+	settingsJSON, err := json.Marshal(user.Settings)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, "INSERT INTO users ("+userColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)",
+		user.ID, user.Username, user.FirstName, user.CreatedAt, user.ActiveGoalID, string(settingsJSON), user.LanguageCode)
+	if err != nil {
+		return fmt.Errorf("failed to create user %s: %w", user.ID, err)
+	}
+	return nil
+}
+
+func (r *SQLiteRepository) UpdateUser(ctx context.Context, user *models.User) error {
+	settingsJSON, err := json.Marshal(user.Settings)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, "UPDATE users SET username = ?, first_name = ?, active_goal_id = ?, settings = ?, language_code = ? WHERE id = ?",
+		user.Username, user.FirstName, user.ActiveGoalID, string(settingsJSON), user.LanguageCode, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update user %s: %w", user.ID, err)
+	}
+	return checkAffected(result, "user", user.ID)
+}
+
+func (r *SQLiteRepository) GetGoal(ctx context.Context, goalID string) (*models.Goal, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+goalColumns+" FROM goals WHERE id = ?", goalID)
+	goal, err := scanGoal(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("goal not found: %s", goalID)
+	}
+	return goal, err
+}
+
+func (r *SQLiteRepository) GetUserGoals(ctx context.Context, userID string) ([]*models.Goal, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+goalColumns+" FROM goals WHERE user_id = ? ORDER BY created_at, id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var goals []*models.Goal
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, goal)
+	}
+
+	return goals, rows.Err()
+}
+
+func (r *SQLiteRepository) CreateGoal(ctx context.Context, goal *models.Goal) error {
+	contextJSON, err := json.Marshal(goal.Context)
+	if err != nil {
+		return err
+	}
+	milestonesJSON, err := marshalMilestones(goal.Milestones)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, "INSERT INTO goals ("+goalColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		goal.ID, goal.UserID, goal.Title, goal.Description, goal.CreatedAt, goal.UpdatedAt,
+		string(contextJSON), goal.Status, nullTime(goal.CompletedAt), goal.StatusReason, goal.Category, goal.Persona, milestonesJSON)
+	if err != nil {
+		return fmt.Errorf("failed to create goal %s: %w", goal.ID, err)
+	}
+	return nil
+}
+
+func (r *SQLiteRepository) UpdateGoal(ctx context.Context, goal *models.Goal) error {
+	contextJSON, err := json.Marshal(goal.Context)
+	if err != nil {
+		return err
+	}
+	milestonesJSON, err := marshalMilestones(goal.Milestones)
+	if err != nil {
+		return err
+	}
+
+	goal.UpdatedAt = time.Now()
+	result, err := r.db.ExecContext(ctx, `UPDATE goals SET user_id = ?, title = ?, description = ?, updated_at = ?,
+		context = ?, status = ?, completed_at = ?, status_reason = ?, category = ?, persona = ?, milestones = ? WHERE id = ?`,
+		goal.UserID, goal.Title, goal.Description, goal.UpdatedAt,
+		string(contextJSON), goal.Status, nullTime(goal.CompletedAt), goal.StatusReason, goal.Category, goal.Persona, milestonesJSON, goal.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update goal %s: %w", goal.ID, err)
+	}
+	return checkAffected(result, "goal", goal.ID)
+}
+
+func (r *SQLiteRepository) DeleteGoal(ctx context.Context, goalID string) error {
+	// Шаги удаляются каскадно через внешний ключ
+	result, err := r.db.ExecContext(ctx, "DELETE FROM goals WHERE id = ?", goalID)
+	if err != nil {
+		return fmt.Errorf("failed to delete goal %s: %w", goalID, err)
+	}
+	return checkAffected(result, "goal", goalID)
+}
+
+func (r *SQLiteRepository) GetStep(ctx context.Context, stepID string) (*models.Step, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+stepColumns+" FROM steps WHERE id = ?", stepID)
+	step, err := scanStep(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("step not found: %s", stepID)
+	}
+	return step, err
+}
+
+func (r *SQLiteRepository) GetGoalSteps(ctx context.Context, goalID string) ([]*models.Step, error) {
+	// Шаги отсортированы по дате создания (от старых к новым)
+	rows, err := r.db.QueryContext(ctx, "SELECT "+stepColumns+" FROM steps WHERE goal_id = ? ORDER BY created_at", goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var steps []*models.Step
+	for rows.Next() {
+		step, err := scanStep(rows)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, rows.Err()
+}
+
+func (r *SQLiteRepository) GetCurrentStep(ctx context.Context, goalID string) (*models.Step, error) {
+	// Ищем последний невыполненный и непропущенный шаг для цели
+	row := r.db.QueryRowContext(ctx, "SELECT "+stepColumns+` FROM steps
+		WHERE goal_id = ? AND completed_at IS NULL AND skipped_at IS NULL
+		ORDER BY created_at DESC LIMIT 1`, goalID)
+	step, err := scanStep(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("no current step found for goal: %s", goalID)
+	}
+	return step, err
+}
+
+func (r *SQLiteRepository) GetRecentFinishedSteps(ctx context.Context, userID string, limit int) ([]*models.Step, error) {
+	// Берем последние limit закрытых шагов по всем целям пользователя
+	rows, err := r.db.QueryContext(ctx, "SELECT "+stepColumns+` FROM steps
+		WHERE goal_id IN (SELECT id FROM goals WHERE user_id = ?)
+		AND (completed_at IS NOT NULL OR skipped_at IS NOT NULL)
+		ORDER BY created_at DESC LIMIT ?`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var steps []*models.Step
+	for rows.Next() {
+		step, err := scanStep(rows)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Возвращаем от старых к новым, как GetGoalSteps
+	slices.Reverse(steps)
+	return steps, nil
+}
+
+func (r *SQLiteRepository) CreateStep(ctx context.Context, step *models.Step) error {
+	historyJSON, err := marshalHistory(step.History)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, "INSERT INTO steps ("+stepColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		step.ID, step.GoalID, step.Text, step.CreatedAt, nullTime(step.CompletedAt),
+		step.Rephrased, step.UserComment, step.Rephrases, step.Simplifications, historyJSON,
+		nullTime(step.SkippedAt), step.SkipReason, step.Difficulty, step.CompletionNote, step.MilestoneID)
+	if err != nil {
+		return fmt.Errorf("failed to create step %s: %w", step.ID, err)
+	}
+	return nil
+}
+
+func (r *SQLiteRepository) UpdateStep(ctx context.Context, step *models.Step) error {
+	historyJSON, err := marshalHistory(step.History)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `UPDATE steps SET goal_id = ?, text = ?, completed_at = ?,
+		rephrased = ?, user_comment = ?, rephrases = ?, simplifications = ?, history = ?,
+		skipped_at = ?, skip_reason = ?, difficulty = ?, completion_note = ?, milestone_id = ? WHERE id = ?`,
+		step.GoalID, step.Text, nullTime(step.CompletedAt), step.Rephrased, step.UserComment,
+		step.Rephrases, step.Simplifications, historyJSON, nullTime(step.SkippedAt), step.SkipReason,
+		step.Difficulty, step.CompletionNote, step.MilestoneID, step.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update step %s: %w", step.ID, err)
+	}
+	return checkAffected(result, "step", step.ID)
+}
+
+func (r *SQLiteRepository) DeleteStep(ctx context.Context, stepID string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM steps WHERE id = ?", stepID)
+	if err != nil {
+		return fmt.Errorf("failed to delete step %s: %w", stepID, err)
+	}
+	return checkAffected(result, "step", stepID)
+}
+
+func (r *SQLiteRepository) Close() error {
+	return r.db.Close()
+}
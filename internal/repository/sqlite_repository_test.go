@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"goal-helper/internal/models"
+)
+
+func TestSQLiteMigratesFromBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SQLiteFileName)
+	createdAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// База в исходной схеме: таблицы без новых колонок, user_version = 0
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(sqliteMigrations[0]); err != nil {
+		t.Fatal(err)
+	}
+	for _, query := range []struct {
+		sql  string
+		args []any
+	}{
+		{`INSERT INTO users (id, username, first_name, created_at, active_goal_id) VALUES (?, ?, ?, ?, ?)`,
+			[]any{"1", "user", "User", createdAt, "legacy"}},
+		{`INSERT INTO goals (id, user_id, title, created_at, updated_at, context, status) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			[]any{"legacy", "1", "Legacy", createdAt, createdAt,
+				`{"clarifications": ["Вопрос: Зачем? | Ответ: Для себя", "Просто заметка"]}`, "inactive"}},
+		{`INSERT INTO goals (id, user_id, title, created_at, updated_at, status) VALUES (?, ?, ?, ?, ?, ?)`,
+			[]any{"done", "1", "Done", createdAt, createdAt, models.GoalStatusCompleted}},
+		{`INSERT INTO steps (id, goal_id, text, created_at, completed_at, rephrased) VALUES (?, ?, ?, ?, ?, ?)`,
+			[]any{"rephrased", "legacy", "Step", createdAt, createdAt.Add(time.Hour), 1}},
+		{`INSERT INTO steps (id, goal_id, text, created_at) VALUES (?, ?, ?, ?)`,
+			[]any{"current", "legacy", "Step", createdAt.Add(2 * time.Hour)}},
+	} {
+		if _, err := db.Exec(query.sql, query.args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	repo, err := NewSQLiteRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	var version int
+	if err := repo.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != len(sqliteMigrations) {
+		t.Errorf("user_version = %d, want %d", version, len(sqliteMigrations))
+	}
+
+	ctx := context.Background()
+	user, err := repo.GetUser(ctx, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ActiveGoalID != "legacy" || user.Settings.Reminders.Disabled {
+		t.Errorf("user = %+v, want active goal kept and default settings", user)
+	}
+
+	legacy, err := repo.GetGoal(ctx, "legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if legacy.Status != models.GoalStatusActive {
+		t.Errorf("legacy goal status = %q, want %q", legacy.Status, models.GoalStatusActive)
+	}
+	want := []models.Clarification{
+		{Question: "Зачем?", Answer: "Для себя", AskedAt: createdAt, Source: models.ClarificationSourceLegacy},
+		{Answer: "Просто заметка", AskedAt: createdAt, Source: models.ClarificationSourceLegacy},
+	}
+	assertSameJSON(t, legacy.Context.Clarifications, want)
+
+	done, err := repo.GetGoal(ctx, "done")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done.Status != models.GoalStatusCompleted || len(done.Milestones) != 0 {
+		t.Errorf("completed goal = %+v, want status kept and no milestones", done)
+	}
+
+	step, err := repo.GetStep(ctx, "rephrased")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if step.Rephrases != 1 || step.Simplifications != 0 || step.IsSkipped() {
+		t.Errorf("migrated step = %+v, want one rephrase", step)
+	}
+
+	current, err := repo.GetCurrentStep(ctx, "legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.ID != "current" {
+		t.Errorf("current step = %s, want current", current.ID)
+	}
+
+	// Повторное открытие не применяет миграции еще раз
+	repo.Close()
+	reopened, err := NewSQLiteRepository(path)
+	if err != nil {
+		t.Fatalf("reopen after migrations: %v", err)
+	}
+	reopened.Close()
+}
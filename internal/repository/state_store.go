@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"goal-helper/internal/models"
+)
+
+// StateStore определяет интерфейс для хранения состояний FSM бота,
+// чтобы незавершенные диалоги переживали перезапуск
+type StateStore interface {
+	ListStates() ([]*models.UserState, error)
+	SaveState(state *models.UserState) error
+	DeleteState(userID int64) error
+}
+
+// statesFileName имя файла с состояниями FSM
+const statesFileName = "states.json"
+
+// NewStateStore создает хранилище состояний рядом с репозиторием:
+// для SQLite состояния хранятся в той же базе, иначе - в JSON файле
+func NewStateStore(repo Repository, dataDir string) (StateStore, error) {
+	if sqliteRepo, ok := repo.(*SQLiteRepository); ok {
+		return &SQLiteStateStore{db: sqliteRepo.db}, nil
+	}
+	return NewFileStateStore(dataDir)
+}
+
+// FileStateStore реализует StateStore через JSON файл
+type FileStateStore struct {
+	filename string
+	mutex    sync.Mutex
+	states   map[int64]*models.UserState
+}
+
+// NewFileStateStore создает файловое хранилище состояний
+func NewFileStateStore(dataDir string) (*FileStateStore, error) {
+	store := &FileStateStore{
+		filename: filepath.Join(dataDir, statesFileName),
+		states:   make(map[int64]*models.UserState),
+	}
+
+	data, err := os.ReadFile(store.filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read states: %w", err)
+	}
+
+	var states []*models.UserState
+	if err := json.Unmarshal(data, &states); err != nil {
+		// Состояния не критичны: при повреждении просто начинаем с чистого листа
+		log.Printf("⚠️ Файл %s поврежден, состояния диалогов сброшены: %v", store.filename, err)
+		return store, nil
+	}
+
+	for _, state := range states {
+		store.states[state.UserID] = state
+	}
+
+	return store, nil
+}
+
+// save сохраняет все состояния в файл (вызывается под блокировкой)
+func (s *FileStateStore) save() error {
+	states := make([]*models.UserState, 0, len(s.states))
+	for _, state := range s.states {
+		states = append(states, state)
+	}
+
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.filename, data, 0644)
+}
+
+func (s *FileStateStore) ListStates() ([]*models.UserState, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	states := make([]*models.UserState, 0, len(s.states))
+	for _, state := range s.states {
+		states = append(states, copyState(state))
+	}
+
+	return states, nil
+}
+
+func (s *FileStateStore) SaveState(state *models.UserState) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Храним копию, чтобы бот мог менять своё состояние без гонок с сериализацией
+	s.states[state.UserID] = copyState(state)
+
+	return s.save()
+}
+
+func (s *FileStateStore) DeleteState(userID int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.states[userID]; !exists {
+		return nil
+	}
+	delete(s.states, userID)
+
+	return s.save()
+}
+
+// copyState создает глубокую копию состояния
+func copyState(state *models.UserState) *models.UserState {
+	copied := *state
+	copied.TempData = make(map[string]string, len(state.TempData))
+	for key, value := range state.TempData {
+		copied.TempData[key] = value
+	}
+	return &copied
+}
+
+// SQLiteStateStore реализует StateStore через таблицу user_states в базе SQLiteRepository
+type SQLiteStateStore struct {
+	db *sql.DB
+}
+
+func (s *SQLiteStateStore) ListStates() ([]*models.UserState, error) {
+	rows, err := s.db.Query("SELECT user_id, state, temp_data, updated_at FROM user_states")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []*models.UserState
+	for rows.Next() {
+		var state models.UserState
+		var tempData string
+		if err := rows.Scan(&state.UserID, &state.State, &tempData, &state.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(tempData), &state.TempData); err != nil {
+			return nil, fmt.Errorf("failed to decode temp data for user %d: %w", state.UserID, err)
+		}
+		states = append(states, &state)
+	}
+
+	return states, rows.Err()
+}
+
+func (s *SQLiteStateStore) SaveState(state *models.UserState) error {
+	tempData, err := json.Marshal(state.TempData)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`INSERT INTO user_states (user_id, state, temp_data, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET state = excluded.state, temp_data = excluded.temp_data, updated_at = excluded.updated_at`,
+		state.UserID, state.State, string(tempData), state.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save state for user %d: %w", state.UserID, err)
+	}
+	return nil
+}
+
+func (s *SQLiteStateStore) DeleteState(userID int64) error {
+	_, err := s.db.Exec("DELETE FROM user_states WHERE user_id = ?", userID)
+	return err
+}
@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"goal-helper/internal/models"
+	"goal-helper/internal/repository"
+)
+
+// DeliverFunc отправляет напоминание пользователю.
+// Возвращает следующее напоминание или nil, если напоминать больше не нужно
+type DeliverFunc func(ctx context.Context, reminder *models.Reminder) (*models.Reminder, error)
+
+// LockFunc захватывает блокировку пользователя и возвращает функцию для её освобождения.
+// Напоминание отправляется под той же блокировкой, что и обработка апдейтов пользователя
+type LockFunc func(userID string) (unlock func())
+
+// RetryTimeFunc возвращает время повторной отправки неудачного напоминания не раньше due:
+// например, переносит его за тихие часы пользователя
+type RetryTimeFunc func(ctx context.Context, reminder *models.Reminder, due time.Time) time.Time
+
+// Scheduler периодически проверяет хранилище и отправляет наступившие напоминания.
+// Напоминания хранятся в ReminderStore, поэтому переживают перезапуск бота
+type Scheduler struct {
+	store     repository.ReminderStore
+	lock      LockFunc
+	retryTime RetryTimeFunc
+	interval  time.Duration // Как часто проверять наступившие напоминания
+	retry     time.Duration // Через сколько повторить неудачную отправку
+}
+
+// New создает планировщик
+func New(store repository.ReminderStore, lock LockFunc, retryTime RetryTimeFunc, interval, retry time.Duration) *Scheduler {
+	return &Scheduler{
+		store:     store,
+		lock:      lock,
+		retryTime: retryTime,
+		interval:  interval,
+		retry:     retry,
+	}
+}
+
+// Schedule планирует (или переносит) напоминание пользователя
+func (s *Scheduler) Schedule(ctx context.Context, reminder *models.Reminder) error {
+	return s.store.SaveReminder(ctx, reminder)
+}
+
+// Cancel отменяет напоминание пользователя
+func (s *Scheduler) Cancel(ctx context.Context, userID string) error {
+	return s.store.DeleteReminder(ctx, userID)
+}
+
+// Get возвращает запланированное напоминание пользователя или nil
+func (s *Scheduler) Get(ctx context.Context, userID string) (*models.Reminder, error) {
+	return s.store.GetReminder(ctx, userID)
+}
+
+// Run проверяет напоминания каждые interval до отмены ctx
+func (s *Scheduler) Run(ctx context.Context, deliver DeliverFunc) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		// Сразу после запуска отправляем напоминания, наступившие пока бот был выключен
+		s.runDue(ctx, deliver)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDue отправляет все наступившие напоминания
+func (s *Scheduler) runDue(ctx context.Context, deliver DeliverFunc) {
+	reminders, err := s.store.ListDueReminders(ctx, time.Now())
+	if err != nil {
+		log.Printf("❌ Ошибка при загрузке напоминаний: %v", err)
+		return
+	}
+
+	for _, reminder := range reminders {
+		if ctx.Err() != nil {
+			return
+		}
+		s.deliver(ctx, reminder, deliver)
+	}
+}
+
+// deliver отправляет одно напоминание и сохраняет следующее
+func (s *Scheduler) deliver(ctx context.Context, reminder *models.Reminder, deliver DeliverFunc) {
+	unlock := s.lock(reminder.UserID)
+	defer unlock()
+
+	// Пока ждали блокировку, пользователь мог перенести напоминание (например, выполнив шаг)
+	current, err := s.store.GetReminder(ctx, reminder.UserID)
+	if err != nil {
+		log.Printf("❌ Ошибка при загрузке напоминания пользователя %s: %v", reminder.UserID, err)
+		return
+	}
+	if current == nil || current.StepID != reminder.StepID || !current.DueAt.Equal(reminder.DueAt) {
+		return
+	}
+
+	next, err := deliver(ctx, reminder)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("❌ Ошибка при отправке напоминания пользователю %s: %v", reminder.UserID, err)
+
+		// Повторим позже, не увеличивая счетчик напоминаний и не нарушая тихие часы
+		retry := *reminder
+		retry.DueAt = s.retryTime(ctx, reminder, time.Now().Add(s.retry))
+		next = &retry
+	}
+
+	if next == nil {
+		err = s.store.DeleteReminder(ctx, reminder.UserID)
+	} else {
+		err = s.store.SaveReminder(ctx, next)
+	}
+	if err != nil {
+		log.Printf("❌ Ошибка при сохранении напоминания пользователя %s: %v", reminder.UserID, err)
+	}
+}
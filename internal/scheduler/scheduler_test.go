@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"goal-helper/internal/models"
+)
+
+// memoryStore хранит напоминания в памяти
+type memoryStore struct {
+	mutex     sync.Mutex
+	reminders map[string]*models.Reminder
+}
+
+func (s *memoryStore) ListDueReminders(ctx context.Context, now time.Time) ([]*models.Reminder, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var due []*models.Reminder
+	for _, reminder := range s.reminders {
+		if !reminder.DueAt.After(now) {
+			copied := *reminder
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryStore) GetReminder(ctx context.Context, userID string) (*models.Reminder, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	reminder, ok := s.reminders[userID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *reminder
+	return &copied, nil
+}
+
+func (s *memoryStore) SaveReminder(ctx context.Context, reminder *models.Reminder) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	copied := *reminder
+	s.reminders[reminder.UserID] = &copied
+	return nil
+}
+
+func (s *memoryStore) DeleteReminder(ctx context.Context, userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.reminders, userID)
+	return nil
+}
+
+func noLock(string) func() { return func() {} }
+
+func TestFailedDeliveryRetryRespectsRetryTime(t *testing.T) {
+	ctx := context.Background()
+	reminder := &models.Reminder{UserID: "1", StepID: "step", DueAt: time.Now().Add(-time.Minute)}
+	store := &memoryStore{reminders: map[string]*models.Reminder{}}
+	store.SaveReminder(ctx, reminder)
+
+	// Например, сейчас тихие часы, и повторить можно только утром
+	morning := time.Now().Add(10 * time.Hour)
+	var requested time.Time
+	retryTime := func(ctx context.Context, r *models.Reminder, due time.Time) time.Time {
+		requested = due
+		return morning
+	}
+
+	s := New(store, noLock, retryTime, time.Minute, 15*time.Minute)
+	s.runDue(ctx, func(ctx context.Context, r *models.Reminder) (*models.Reminder, error) {
+		return nil, errors.New("telegram is down")
+	})
+
+	if wait := time.Until(requested); wait < 14*time.Minute || wait > 15*time.Minute {
+		t.Errorf("retry requested at %v from now, want the retry delay", wait)
+	}
+	saved, _ := store.GetReminder(ctx, "1")
+	if saved == nil || !saved.DueAt.Equal(morning) {
+		t.Fatalf("saved reminder = %+v, want due at %v", saved, morning)
+	}
+	if saved.Nudges != reminder.Nudges {
+		t.Errorf("nudges = %d, want unchanged %d", saved.Nudges, reminder.Nudges)
+	}
+}
+
+func TestDeliverySavesNextReminder(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{reminders: map[string]*models.Reminder{}}
+	store.SaveReminder(ctx, &models.Reminder{UserID: "1", StepID: "step", DueAt: time.Now().Add(-time.Minute)})
+
+	next := &models.Reminder{UserID: "1", StepID: "step", DueAt: time.Now().Add(time.Hour), Nudges: 1}
+	s := New(store, noLock, func(ctx context.Context, r *models.Reminder, due time.Time) time.Time {
+		t.Error("retry time requested after successful delivery")
+		return due
+	}, time.Minute, time.Minute)
+	s.runDue(ctx, func(ctx context.Context, r *models.Reminder) (*models.Reminder, error) {
+		return next, nil
+	})
+
+	saved, _ := store.GetReminder(ctx, "1")
+	if saved == nil || saved.Nudges != 1 || !saved.DueAt.Equal(next.DueAt) {
+		t.Errorf("saved reminder = %+v, want %+v", saved, next)
+	}
+}
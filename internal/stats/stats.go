@@ -0,0 +1,208 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"goal-helper/internal/models"
+	"goal-helper/internal/repository"
+)
+
+// WeeksHistory сколько последних недель учитывать в статистике по неделям
+const WeeksHistory = 4
+
+// Stats содержит мотивационные метрики пользователя.
+// Дни и недели считаются в местном времени пользователя
+type Stats struct {
+	CurrentStreak  int // Сколько дней подряд (до сегодня или вчера) выполнялись шаги
+	LongestStreak  int // Самая длинная серия дней подряд
+	StepsCompleted int // Всего выполнено шагов
+
+	Weeks          []WeekStats // Шаги по последним неделям, от старых к новым
+	AveragePerWeek float64     // Среднее число шагов в неделю за эти недели
+
+	AverageCompletion time.Duration // Среднее время от появления шага до выполнения
+
+	GoalsTotal     int         // Всего целей
+	GoalsCompleted int         // Завершенных целей
+	Goals          []GoalStats // Статистика по целям в порядке создания
+}
+
+// WeekStats число выполненных шагов за неделю
+type WeekStats struct {
+	Start time.Time // Понедельник, 00:00 местного времени
+	Steps int
+}
+
+// GoalStats статистика по одной цели
+type GoalStats struct {
+	Goal            *models.Goal
+	StepsTotal      int // Без пропущенных шагов
+	StepsCompleted  int
+	Rephrases       int // Сколько раз шаги переформулировались
+	Simplifications int // Сколько раз шаги упрощались
+}
+
+// ForUser загружает цели и шаги пользователя и считает статистику на момент now
+func ForUser(ctx context.Context, repo repository.Repository, user *models.User, now time.Time) (*Stats, error) {
+	goals, err := repo.GetUserGoals(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load goals: %w", err)
+	}
+
+	steps := make(map[string][]*models.Step, len(goals))
+	for _, goal := range goals {
+		goalSteps, err := repo.GetGoalSteps(ctx, goal.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load steps for goal %s: %w", goal.ID, err)
+		}
+		steps[goal.ID] = goalSteps
+	}
+
+	return Compute(goals, steps, now.In(user.Settings.Location())), nil
+}
+
+// Compute считает статистику по целям и их шагам (steps - шаги по ID цели).
+// Границы дней и недель берутся из часового пояса now
+func Compute(goals []*models.Goal, steps map[string][]*models.Step, now time.Time) *Stats {
+	stats := &Stats{GoalsTotal: len(goals)}
+
+	// Хранилище не обязано отдавать цели по порядку создания
+	goals = append([]*models.Goal(nil), goals...)
+	models.SortGoals(goals)
+
+	var completedAt []time.Time
+	var totalCompletion time.Duration
+
+	for _, goal := range goals {
+		goalStats := GoalStats{Goal: goal}
+		if goal.Status == models.GoalStatusCompleted {
+			stats.GoalsCompleted++
+		}
+
+		for _, step := range steps[goal.ID] {
+			goalStats.Rephrases += rephrases(step)
+			goalStats.Simplifications += step.Simplifications
+
+			// Пропущенные шаги не входят ни в выполненные, ни в общее число шагов
+			if step.IsSkipped() {
+				continue
+			}
+			goalStats.StepsTotal++
+
+			if !step.IsCompleted() {
+				continue
+			}
+			goalStats.StepsCompleted++
+			completedAt = append(completedAt, step.CompletedAt.In(now.Location()))
+			if duration := step.CompletedAt.Sub(step.CreatedAt); duration > 0 {
+				totalCompletion += duration
+			}
+		}
+
+		stats.Goals = append(stats.Goals, goalStats)
+	}
+
+	stats.StepsCompleted = len(completedAt)
+	if stats.StepsCompleted > 0 {
+		stats.AverageCompletion = totalCompletion / time.Duration(stats.StepsCompleted)
+	}
+
+	stats.CurrentStreak, stats.LongestStreak = streaks(completedAt, now)
+	stats.Weeks = weeks(completedAt, now)
+
+	total := 0
+	for _, week := range stats.Weeks {
+		total += week.Steps
+	}
+	stats.AveragePerWeek = float64(total) / float64(len(stats.Weeks))
+
+	return stats
+}
+
+// rephrases возвращает число переформулировок шага
+// У шагов, сохраненных до появления счетчика, есть только флаг Rephrased
+func rephrases(step *models.Step) int {
+	if step.Rephrases == 0 && step.Simplifications == 0 && step.Rephrased {
+		return 1
+	}
+	return step.Rephrases
+}
+
+// day возвращает начало дня t в его часовом поясе
+func day(t time.Time) time.Time {
+	year, month, d := t.Date()
+	return time.Date(year, month, d, 0, 0, 0, 0, t.Location())
+}
+
+// streaks считает текущую и самую длинную серию дней с выполненными шагами.
+// Серия не прерывается, пока не закончился следующий за ней день:
+// если сегодня шагов еще не было, текущей считается серия, закончившаяся вчера
+func streaks(completedAt []time.Time, now time.Time) (current, longest int) {
+	if len(completedAt) == 0 {
+		return 0, 0
+	}
+
+	days := make(map[time.Time]bool)
+	for _, t := range completedAt {
+		days[day(t)] = true
+	}
+
+	sorted := make([]time.Time, 0, len(days))
+	for d := range days {
+		sorted = append(sorted, d)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	run := 0
+	var previous time.Time
+	for _, d := range sorted {
+		// AddDate вместо 24 часов - из-за перевода часов в сутках бывает 23 или 25 часов
+		if run > 0 && previous.AddDate(0, 0, 1).Equal(d) {
+			run++
+		} else {
+			run = 1
+		}
+		previous = d
+		if run > longest {
+			longest = run
+		}
+	}
+
+	today := day(now)
+	last := sorted[len(sorted)-1]
+	if last.Equal(today) || last.AddDate(0, 0, 1).Equal(today) {
+		current = run
+	}
+	return current, longest
+}
+
+// weekStart возвращает понедельник недели, в которую попадает t
+func weekStart(t time.Time) time.Time {
+	d := day(t)
+	offset := (int(d.Weekday()) + 6) % 7 // Понедельник - 0
+	return d.AddDate(0, 0, -offset)
+}
+
+// weeks считает выполненные шаги за последние WeeksHistory недель, включая текущую
+func weeks(completedAt []time.Time, now time.Time) []WeekStats {
+	result := make([]WeekStats, WeeksHistory)
+	current := weekStart(now)
+	for i := range result {
+		result[i].Start = current.AddDate(0, 0, -7*(WeeksHistory-1-i))
+	}
+
+	for _, t := range completedAt {
+		start := weekStart(t)
+		for i := range result {
+			if result[i].Start.Equal(start) {
+				result[i].Steps++
+				break
+			}
+		}
+	}
+
+	return result
+}
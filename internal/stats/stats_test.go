@@ -0,0 +1,198 @@
+package stats
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"goal-helper/internal/models"
+)
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("timezone %s is not available: %v", name, err)
+	}
+	return location
+}
+
+// completedSteps создает выполненные шаги цели в указанные моменты
+func completedSteps(goalID string, times ...time.Time) []*models.Step {
+	steps := make([]*models.Step, len(times))
+	for i, t := range times {
+		completedAt := t
+		steps[i] = &models.Step{
+			ID:          fmt.Sprintf("%s-%d", goalID, i),
+			GoalID:      goalID,
+			CreatedAt:   t.Add(-time.Hour),
+			CompletedAt: &completedAt,
+		}
+	}
+	return steps
+}
+
+func TestStreaks(t *testing.T) {
+	berlin := loadLocation(t, "Europe/Berlin")
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2026, month, day, hour, 30, 0, 0, berlin)
+	}
+
+	tests := []struct {
+		name        string
+		completed   []time.Time
+		now         time.Time
+		wantCurrent int
+		wantLongest int
+	}{
+		{"no steps", nil, at(10, 20, 12), 0, 0},
+		{"today only", []time.Time{at(10, 20, 9)}, at(10, 20, 12), 1, 1},
+		{"several steps a day count once", []time.Time{at(10, 19, 9), at(10, 19, 18), at(10, 20, 9)}, at(10, 20, 12), 2, 2},
+		{"streak ending yesterday is current", []time.Time{at(10, 18, 9), at(10, 19, 9)}, at(10, 20, 12), 2, 2},
+		{"streak ending two days ago is over", []time.Time{at(10, 17, 9), at(10, 18, 9)}, at(10, 20, 12), 0, 2},
+		{"gap splits streaks", []time.Time{at(10, 10, 9), at(10, 11, 9), at(10, 12, 9), at(10, 19, 9), at(10, 20, 9)}, at(10, 20, 12), 2, 3},
+		// 25 октября 2026 в Берлине переводят часы: в сутках 25 часов
+		{"autumn DST change", []time.Time{at(10, 24, 23), at(10, 25, 23), at(10, 26, 23)}, at(10, 26, 23), 3, 3},
+		// 29 марта 2026 в Берлине в сутках 23 часа
+		{"spring DST change", []time.Time{at(3, 28, 0), at(3, 29, 0), at(3, 30, 0)}, at(3, 30, 12), 3, 3},
+		{"unordered input", []time.Time{at(10, 20, 9), at(10, 18, 9), at(10, 19, 9)}, at(10, 20, 12), 3, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, longest := streaks(tt.completed, tt.now)
+			if current != tt.wantCurrent || longest != tt.wantLongest {
+				t.Errorf("streaks = (%d, %d), want (%d, %d)", current, longest, tt.wantCurrent, tt.wantLongest)
+			}
+		})
+	}
+}
+
+func TestComputeUsesUserTimezone(t *testing.T) {
+	moscow := loadLocation(t, "Europe/Moscow")
+
+	// После 21:00 UTC в Москве (UTC+3) уже следующий день
+	goal := &models.Goal{ID: "goal", CreatedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)}
+	steps := map[string][]*models.Step{goal.ID: completedSteps(goal.ID,
+		time.Date(2026, 10, 13, 22, 30, 0, 0, time.UTC),
+		time.Date(2026, 10, 14, 22, 0, 0, 0, time.UTC),
+	)}
+
+	// В Москве шаги выполнены 14-го и 15-го, и серия еще продолжается 16-го
+	inMoscow := Compute([]*models.Goal{goal}, steps, time.Date(2026, 10, 16, 12, 0, 0, 0, moscow))
+	if inMoscow.CurrentStreak != 2 || inMoscow.LongestStreak != 2 {
+		t.Errorf("Moscow streaks = (%d, %d), want (2, 2)", inMoscow.CurrentStreak, inMoscow.LongestStreak)
+	}
+
+	// По UTC шаги выполнены 13-го и 14-го, и к 16-му серия прервалась
+	inUTC := Compute([]*models.Goal{goal}, steps, time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+	if inUTC.CurrentStreak != 0 || inUTC.LongestStreak != 2 {
+		t.Errorf("UTC streaks = (%d, %d), want (0, 2)", inUTC.CurrentStreak, inUTC.LongestStreak)
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	berlin := loadLocation(t, "Europe/Berlin")
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{"monday", time.Date(2026, 10, 12, 0, 0, 0, 0, berlin), time.Date(2026, 10, 12, 0, 0, 0, 0, berlin)},
+		{"sunday belongs to previous week", time.Date(2026, 10, 18, 23, 59, 0, 0, berlin), time.Date(2026, 10, 12, 0, 0, 0, 0, berlin)},
+		{"wednesday", time.Date(2026, 10, 14, 15, 0, 0, 0, berlin), time.Date(2026, 10, 12, 0, 0, 0, 0, berlin)},
+		{"sunday of DST change", time.Date(2026, 10, 25, 23, 0, 0, 0, berlin), time.Date(2026, 10, 19, 0, 0, 0, 0, berlin)},
+		{"monday after DST change", time.Date(2026, 10, 26, 0, 30, 0, 0, berlin), time.Date(2026, 10, 26, 0, 0, 0, 0, berlin)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weekStart(tt.t); !got.Equal(tt.want) {
+				t.Errorf("weekStart(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeeksAndAverage(t *testing.T) {
+	berlin := loadLocation(t, "Europe/Berlin")
+	now := time.Date(2026, 10, 28, 12, 0, 0, 0, berlin) // Среда после перевода часов
+
+	goal := &models.Goal{ID: "goal"}
+	steps := map[string][]*models.Step{goal.ID: completedSteps(goal.ID,
+		time.Date(2026, 9, 20, 12, 0, 0, 0, berlin),  // Раньше последних WeeksHistory недель
+		time.Date(2026, 10, 5, 0, 0, 0, 0, berlin),   // Понедельник первой недели
+		time.Date(2026, 10, 18, 23, 0, 0, 0, berlin), // Воскресенье второй недели
+		time.Date(2026, 10, 19, 9, 0, 0, 0, berlin),
+		time.Date(2026, 10, 25, 23, 30, 0, 0, berlin), // Воскресенье с переводом часов
+		time.Date(2026, 10, 26, 0, 30, 0, 0, berlin),
+		time.Date(2026, 10, 28, 9, 0, 0, 0, berlin),
+	)}
+
+	stats := Compute([]*models.Goal{goal}, steps, now)
+
+	wantStarts := []time.Time{
+		time.Date(2026, 10, 5, 0, 0, 0, 0, berlin),
+		time.Date(2026, 10, 12, 0, 0, 0, 0, berlin),
+		time.Date(2026, 10, 19, 0, 0, 0, 0, berlin),
+		time.Date(2026, 10, 26, 0, 0, 0, 0, berlin),
+	}
+	wantSteps := []int{1, 1, 2, 2}
+
+	if len(stats.Weeks) != WeeksHistory {
+		t.Fatalf("weeks = %d, want %d", len(stats.Weeks), WeeksHistory)
+	}
+	for i, week := range stats.Weeks {
+		if !week.Start.Equal(wantStarts[i]) || week.Steps != wantSteps[i] {
+			t.Errorf("week %d = %v: %d, want %v: %d", i, week.Start, week.Steps, wantStarts[i], wantSteps[i])
+		}
+	}
+
+	// Среднее считается по всем WeeksHistory неделям, включая пустые и текущую
+	if want := 6.0 / WeeksHistory; stats.AveragePerWeek != want {
+		t.Errorf("AveragePerWeek = %v, want %v", stats.AveragePerWeek, want)
+	}
+	if stats.StepsCompleted != 7 {
+		t.Errorf("StepsCompleted = %d, want 7", stats.StepsCompleted)
+	}
+}
+
+func TestComputeGoals(t *testing.T) {
+	created := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	first := &models.Goal{ID: "b", CreatedAt: created, Status: models.GoalStatusCompleted}
+	sameTime := &models.Goal{ID: "c", CreatedAt: created, Status: models.GoalStatusActive}
+	later := &models.Goal{ID: "a", CreatedAt: created.Add(time.Hour), Status: models.GoalStatusActive}
+
+	skippedAt := created
+	steps := map[string][]*models.Step{
+		first.ID: append(completedSteps(first.ID, created, created),
+			&models.Step{ID: "skipped", GoalID: first.ID, SkippedAt: &skippedAt, Rephrases: 1},
+			&models.Step{ID: "open", GoalID: first.ID, Simplifications: 2},
+			&models.Step{ID: "legacy", GoalID: first.ID, Rephrased: true}),
+	}
+
+	// Файловое хранилище отдает цели в произвольном порядке
+	stats := Compute([]*models.Goal{later, sameTime, first}, steps, created)
+
+	wantOrder := []string{"b", "c", "a"}
+	for i, goalStats := range stats.Goals {
+		if goalStats.Goal.ID != wantOrder[i] {
+			t.Fatalf("goal %d = %s, want order %v", i, goalStats.Goal.ID, wantOrder)
+		}
+	}
+
+	if stats.GoalsTotal != 3 || stats.GoalsCompleted != 1 {
+		t.Errorf("goals = %d/%d, want 1/3", stats.GoalsCompleted, stats.GoalsTotal)
+	}
+	goalStats := stats.Goals[0]
+	if goalStats.StepsCompleted != 2 || goalStats.StepsTotal != 4 {
+		t.Errorf("steps = %d/%d, want 2/4 (skipped step excluded)", goalStats.StepsCompleted, goalStats.StepsTotal)
+	}
+	if goalStats.Rephrases != 2 || goalStats.Simplifications != 2 {
+		t.Errorf("rephrases = %d, simplifications = %d, want 2 and 2", goalStats.Rephrases, goalStats.Simplifications)
+	}
+	if stats.AverageCompletion != time.Hour {
+		t.Errorf("AverageCompletion = %v, want 1h", stats.AverageCompletion)
+	}
+}